@@ -0,0 +1,115 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/cache/redis/internal/backend"
+)
+
+// init hands the internals the in-memory Cache in redistest shares with this
+// package to internal/backend.
+func init() {
+	backend.DefaultTTL = defaultTTL
+	backend.AbsentMarker = absentMarker
+	backend.StaleCopySuffix = staleCopySuffix
+	backend.TagKeyPrefix = tagKeyPrefix
+
+	backend.NewEncoder = func(codec backend.Codec) backend.Encoder {
+		return backendEncoder{Config{Codec: codec}.encoder()}
+	}
+	backend.AcquireLock = func(ctx context.Context, b backend.LockBackend, key string, ttl time.Duration, opts []func(*backend.LockOptions)) (any, error) {
+		return acquireLock(ctx, backendLocks{b}, key, ttl, lockOptionsOf(opts))
+	}
+	backend.AcquireSemaphore = func(ctx context.Context, s backend.SemaphoreStore, key string, limit int, ttl time.Duration, opts []func(*backend.LockOptions)) (any, error) {
+		return acquireSemaphore(ctx, backendPermits{s}, key, limit, ttl, lockOptionsOf(opts))
+	}
+
+	backend.ValidateKey = validateKey
+	backend.ValidateKV = validateKV
+	backend.ValidateDest = validateDest
+	backend.ValidatePattern = validatePattern
+	backend.ValidateTags = validateTags
+	backend.BatchTarget = batchTarget
+	backend.MatchGlob = matchGlob
+	backend.SidecarKeys = sidecarKeys
+	backend.WrittenTTL = writtenTTL
+	backend.StaleCopyTTL = staleCopyTTL
+	backend.NewToken = newToken
+	backend.Serialize = serialize
+	backend.FormatArg = formatArg
+}
+
+func lockOptionsOf(opts []func(*backend.LockOptions)) []LockOption {
+	out := make([]LockOption, len(opts))
+	for i, opt := range opts {
+		out[i] = opt
+	}
+	return out
+}
+
+// backendEncoder exposes encoder as a backend.Encoder.
+type backendEncoder struct{ e encoder }
+
+func (b backendEncoder) Encode(value any, codec backend.Codec) (string, error) {
+	return b.e.encode(value, codec)
+}
+
+func (b backendEncoder) Decode(val string, dest any) error {
+	return b.e.decode(val, dest)
+}
+
+func (b backendEncoder) SetBatchElem(target reflect.Value, keys []string, i int, val string) error {
+	return b.e.setBatchElem(target, keys, i, val)
+}
+
+func (b backendEncoder) HashPairs(values any) ([]any, error) {
+	return b.e.hashPairs(values)
+}
+
+func (b backendEncoder) DecodeField(val string, dest any) error {
+	return b.e.decodeField(val, dest)
+}
+
+func (b backendEncoder) ScanHash(vals map[string]string, dest any) error {
+	return b.e.scanHash(vals, dest)
+}
+
+// backendLocks adapts a backend.LockBackend to lockBackend.
+type backendLocks struct{ b backend.LockBackend }
+
+func (l backendLocks) tryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return l.b.TryLock(ctx, key, token, ttl)
+}
+
+func (l backendLocks) refreshLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return l.b.RefreshLock(ctx, key, token, ttl)
+}
+
+func (l backendLocks) unlock(ctx context.Context, key, token string) (bool, error) {
+	return l.b.ReleaseLock(ctx, key, token)
+}
+
+func (l backendLocks) logErr(ctx context.Context, step string, err error) error {
+	return l.b.LogErr(ctx, step, err)
+}
+
+// backendPermits adapts a backend.SemaphoreStore to semaphoreStore.
+type backendPermits struct{ s backend.SemaphoreStore }
+
+func (p backendPermits) tryAcquire(ctx context.Context, key, token string, limit int, ttl time.Duration) (bool, error) {
+	return p.s.TryAcquire(ctx, key, token, limit, ttl)
+}
+
+func (p backendPermits) refreshPermit(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return p.s.RefreshPermit(ctx, key, token, ttl)
+}
+
+func (p backendPermits) releasePermit(ctx context.Context, key, token string) (bool, error) {
+	return p.s.ReleasePermit(ctx, key, token)
+}
+
+func (p backendPermits) logErr(ctx context.Context, step string, err error) error {
+	return p.s.LogErr(ctx, step, err)
+}
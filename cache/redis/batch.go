@@ -0,0 +1,220 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Batch operations send one command per key in a single pipeline (one round trip).
+// Pipelines are not transactions: each command succeeds or fails independently.
+// Per-key GETs are used instead of MGET so batches also work when keys span cluster slots.
+
+// GetMany reads keys in one round trip and deserializes every found value into dest.
+// dest must be a pointer to a map with string keys or a pointer to a slice:
+//
+//   - *map[string]T — found keys are added; missing keys are left out
+//   - *[]T          — resized to len(keys); element i holds keys[i], zero value when missing
+//
+// The returned flags are aligned with keys: found[i] reports whether keys[i] exists.
+//
+//	users := map[string]User{}
+//	found, err := cache.GetMany(ctx, []string{"user:1", "user:2"}, &users)
+func (c *cache) GetMany(ctx context.Context, keys []string, dest any) ([]bool, error) {
+	if ctx == nil {
+		return nil, errors.New("redis: context is required")
+	}
+	target, err := batchTarget(dest, len(keys))
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return []bool{}, nil
+	}
+
+	pipe := c.instance.Pipeline()
+	cmds := make([]*goredis.StringCmd, len(keys))
+	for i, k := range keys {
+		if k == "" {
+			return nil, fmt.Errorf("redis: key at index %d is empty", i)
+		}
+		cmds[i] = pipe.Get(ctx, c.key(k))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		return nil, c.logErr(ctx, "cache.get_many", err)
+	}
+
+	found := make([]bool, len(keys))
+	for i, cmd := range cmds {
+		val, err := cmd.Result()
+		if err == goredis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, c.logErr(ctx, "cache.get_many", err)
+		}
+		if val == absentMarker {
+			continue
+		}
+		if err := c.encoder.setBatchElem(target, keys, i, val); err != nil {
+			return nil, err
+		}
+		found[i] = true
+	}
+	return found, nil
+}
+
+// SetMany writes items in one round trip. Each item uses its own TTL when set,
+// otherwise the call's TTL (WithTTL, WithPersist, WithKeepTTL; default 7 days).
+// WithTags tags every item. WithNX and WithXX are not supported.
+//
+//	err := cache.SetMany(ctx, []redis.SetItem{
+//	    {Key: "user:1", Value: u1},
+//	    {Key: "user:2", Value: u2, TTL: time.Minute},
+//	}, redis.WithTTL(10*time.Minute))
+func (c *cache) SetMany(ctx context.Context, items []SetItem, opts ...SetOption) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	o := &setOptions{TTL: defaultTTL}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.IfNotExist || o.IfExist {
+		return errors.New("redis: SetMany does not support WithNX or WithXX")
+	}
+
+	pipe := c.instance.Pipeline()
+	for i, it := range items {
+		if err := validateKV(ctx, it.Key, it.Value); err != nil {
+			return fmt.Errorf("item at index %d: %w", i, err)
+		}
+		payload, err := c.encoder.encode(it.Value, o.Codec)
+		if err != nil {
+			return fmt.Errorf("redis: key %q: %w", it.Key, err)
+		}
+		if len(o.Tags) > 0 {
+			itemOpts := *o
+			if it.TTL > 0 {
+				itemOpts.TTL, itemOpts.KeepTTL = it.TTL, false
+			}
+			keys, args, err := c.taggedSetArgs(it.Key, payload, &itemOpts)
+			if err != nil {
+				return err
+			}
+			pipe.Eval(ctx, luaSetTagged, keys, args...)
+			continue
+		}
+		args := goredis.SetArgs{TTL: o.TTL}
+		switch {
+		case it.TTL > 0:
+			args.TTL = it.TTL
+		case o.KeepTTL:
+			// KEEPTTL cannot be combined with EX/PX.
+			args = goredis.SetArgs{KeepTTL: true}
+		}
+		pipe.SetArgs(ctx, c.key(it.Key), payload, args)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return c.logErr(ctx, "cache.set_many", err)
+	}
+	return nil
+}
+
+// ExistsMany reports, for each key, whether it is present. Results are aligned with keys.
+func (c *cache) ExistsMany(ctx context.Context, keys []string) ([]bool, error) {
+	if ctx == nil {
+		return nil, errors.New("redis: context is required")
+	}
+	pipe := c.instance.Pipeline()
+	cmds := make([]*goredis.IntCmd, len(keys))
+	for i, k := range keys {
+		if k == "" {
+			return nil, fmt.Errorf("redis: key at index %d is empty", i)
+		}
+		cmds[i] = pipe.Exists(ctx, c.key(k))
+	}
+	if len(keys) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, c.logErr(ctx, "cache.exists_many", err)
+		}
+	}
+
+	out := make([]bool, len(keys))
+	for i, cmd := range cmds {
+		out[i] = cmd.Val() > 0
+	}
+	return out, nil
+}
+
+// TTLMany returns the remaining time-to-live of each key, aligned with keys.
+// Same conventions as TTL: -1 when a key has no expiry, -2 when it does not exist.
+func (c *cache) TTLMany(ctx context.Context, keys []string) ([]time.Duration, error) {
+	if ctx == nil {
+		return nil, errors.New("redis: context is required")
+	}
+	pipe := c.instance.Pipeline()
+	cmds := make([]*goredis.DurationCmd, len(keys))
+	for i, k := range keys {
+		if k == "" {
+			return nil, fmt.Errorf("redis: key at index %d is empty", i)
+		}
+		cmds[i] = pipe.TTL(ctx, c.key(k))
+	}
+	if len(keys) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, c.logErr(ctx, "cache.ttl_many", err)
+		}
+	}
+
+	out := make([]time.Duration, len(keys))
+	for i, cmd := range cmds {
+		out[i] = cmd.Val()
+	}
+	return out, nil
+}
+
+// setBatchElem decodes val into the GetMany target slot for keys[i].
+func (e encoder) setBatchElem(target reflect.Value, keys []string, i int, val string) error {
+	elem := reflect.New(target.Type().Elem())
+	if err := e.decode(val, elem.Interface()); err != nil {
+		return fmt.Errorf("redis: key %q: %w", keys[i], err)
+	}
+	if target.Kind() == reflect.Map {
+		target.SetMapIndex(reflect.ValueOf(keys[i]).Convert(target.Type().Key()), elem.Elem())
+	} else {
+		target.Index(i).Set(elem.Elem())
+	}
+	return nil
+}
+
+// batchTarget validates dest for GetMany and prepares it to receive values.
+func batchTarget(dest any, n int) (reflect.Value, error) {
+	rv := reflect.ValueOf(dest)
+	if dest == nil || rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return reflect.Value{}, errors.New("redis: dest must be a non-nil pointer to a map or slice")
+	}
+	target := rv.Elem()
+	switch target.Kind() {
+	case reflect.Map:
+		if target.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, errors.New("redis: dest map must have string keys")
+		}
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+	case reflect.Slice:
+		target.Set(reflect.MakeSlice(target.Type(), n, n))
+	default:
+		return reflect.Value{}, errors.New("redis: dest must be a pointer to a map or slice")
+	}
+	return target, nil
+}
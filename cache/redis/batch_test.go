@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+type batchUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestGetMany_Map(t *testing.T) {
+	c, _ := newTestCache(t)
+	_ = c.Set(ctx, "user:1", batchUser{ID: 1, Name: "alice"})
+	_ = c.Set(ctx, "user:3", batchUser{ID: 3, Name: "carol"})
+
+	users := map[string]batchUser{}
+	found, err := c.GetMany(ctx, []string{"user:1", "user:2", "user:3"}, &users)
+	if err != nil {
+		t.Fatalf("GetMany: %v", err)
+	}
+	if !found[0] || found[1] || !found[2] {
+		t.Errorf("found = %v, want [true false true]", found)
+	}
+	if len(users) != 2 || users["user:3"].Name != "carol" {
+		t.Errorf("users = %+v", users)
+	}
+}
+
+func TestGetMany_Slice(t *testing.T) {
+	c, _ := newTestCache(t)
+	_ = c.Set(ctx, "a", "1")
+	_ = c.Set(ctx, "c", "3")
+
+	var got []string
+	found, err := c.GetMany(ctx, []string{"a", "b", "c"}, &got)
+	if err != nil {
+		t.Fatalf("GetMany: %v", err)
+	}
+	if len(got) != 3 || got[0] != "1" || got[1] != "" || got[2] != "3" {
+		t.Errorf("got %q", got)
+	}
+	if found[1] {
+		t.Error("b should not be found")
+	}
+}
+
+func TestGetMany_InvalidDest(t *testing.T) {
+	c, _ := newTestCache(t)
+	var s string
+	if _, err := c.GetMany(ctx, []string{"a"}, &s); err == nil {
+		t.Error("expected error for non map/slice dest")
+	}
+}
+
+func TestSetMany_PerItemTTL(t *testing.T) {
+	c, mr := newTestCache(t)
+	err := c.SetMany(ctx, []SetItem{
+		{Key: "a", Value: batchUser{ID: 1}},
+		{Key: "b", Value: "plain", TTL: time.Minute},
+	}, WithTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("SetMany: %v", err)
+	}
+	if ttl := mr.TTL("test:a"); ttl != time.Hour {
+		t.Errorf("a TTL = %v, want 1h", ttl)
+	}
+	if ttl := mr.TTL("test:b"); ttl != time.Minute {
+		t.Errorf("b TTL = %v, want 1m", ttl)
+	}
+
+	var u batchUser
+	if err := c.Get(ctx, "a", &u); err != nil || u.ID != 1 {
+		t.Errorf("Get a: %+v, %v", u, err)
+	}
+}
+
+func TestSetMany_RejectsNX(t *testing.T) {
+	c, _ := newTestCache(t)
+	if err := c.SetMany(ctx, []SetItem{{Key: "a", Value: "1"}}, WithNX()); err == nil {
+		t.Error("expected error for WithNX")
+	}
+}
+
+func TestExistsMany_TTLMany(t *testing.T) {
+	c, _ := newTestCache(t)
+	_ = c.Set(ctx, "a", "1", WithTTL(time.Minute))
+	_ = c.Set(ctx, "b", "2", WithPersist())
+
+	exists, err := c.ExistsMany(ctx, []string{"a", "b", "missing"})
+	if err != nil {
+		t.Fatalf("ExistsMany: %v", err)
+	}
+	if !exists[0] || !exists[1] || exists[2] {
+		t.Errorf("exists = %v", exists)
+	}
+
+	ttls, err := c.TTLMany(ctx, []string{"a", "b", "missing"})
+	if err != nil {
+		t.Fatalf("TTLMany: %v", err)
+	}
+	if ttls[0] != time.Minute || ttls[1] >= 0 || ttls[2] >= 0 {
+		t.Errorf("ttls = %v", ttls)
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"time"
 
 	goredis "github.com/redis/go-redis/v9"
 )
 
-// Set stores value under key. Structs and slices are JSON-serialized automatically.
+// Set stores value under key. Structs and slices are encoded with the configured
+// Codec (JSON by default; override per call with WithCodec).
 // Primitives (string, int, int64, float64, bool, []byte) are stored as-is.
 // Default TTL is 7 days — override with WithTTL.
 func (c *cache) Set(ctx context.Context, key string, value any, opts ...SetOption) error {
@@ -23,14 +25,26 @@ func (c *cache) Set(ctx context.Context, key string, value any, opts ...SetOptio
 	for _, opt := range opts {
 		opt(o)
 	}
-	if o.IfNotExist && o.IfExist {
-		return errors.New("redis: WithNX and WithXX are mutually exclusive")
-	}
 
-	payload, err := serialize(value)
+	payload, err := c.encoder.encode(value, o.Codec)
 	if err != nil {
 		return err
 	}
+	if err := c.write(ctx, key, payload, o); err != nil {
+		return err
+	}
+	c.metrics.recordSize(ctx, opSet, directionWrite, key, len(payload))
+	return nil
+}
+
+// write stores an already encoded payload honoring NX/XX/KEEPTTL and tags.
+func (c *cache) write(ctx context.Context, key, payload string, o *setOptions) error {
+	if o.IfNotExist && o.IfExist {
+		return errors.New("redis: WithNX and WithXX are mutually exclusive")
+	}
+	if len(o.Tags) > 0 {
+		return c.writeTagged(ctx, key, payload, o)
+	}
 
 	args := goredis.SetArgs{TTL: o.TTL}
 	switch {
@@ -64,48 +78,103 @@ func (c *cache) Get(ctx context.Context, key string, dest any, opts ...GetOption
 		opt(o)
 	}
 
+	start := time.Now()
+	size, err := c.get(ctx, key, dest, o)
+	c.metrics.recordRequest(ctx, opGet, key, lookupResult(err), start)
+	if err == nil {
+		c.metrics.recordSize(ctx, opGet, directionRead, key, size)
+	}
+	if err == errCachedAbsent {
+		return ErrKeyNotFound
+	}
+	return err
+}
+
+// get reads key into dest without recording metrics and returns the stored size.
+// A key cached as absent (WithNegativeTTL) yields errCachedAbsent.
+func (c *cache) get(ctx context.Context, key string, dest any, o *getOptions) (int, error) {
 	var cmd *goredis.StringCmd
-	if o.DeleteAfterGet {
+	switch {
+	case o.DeleteAfterGet:
 		cmd = c.instance.GetDel(ctx, c.key(key))
-	} else {
+	case o.RemainingTTL != nil:
+		pipe := c.instance.Pipeline()
+		cmd = pipe.Get(ctx, c.key(key))
+		ttlCmd := pipe.PTTL(ctx, c.key(key))
+		if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+			return 0, c.logErr(ctx, "cache.get", err)
+		}
+		*o.RemainingTTL = remainingTTL(ttlCmd.Val())
+	default:
 		cmd = c.instance.Get(ctx, c.key(key))
 	}
 
 	val, err := cmd.Result()
 	if err != nil {
 		if err == goredis.Nil {
-			return ErrKeyNotFound
+			return 0, ErrKeyNotFound
 		}
-		return c.logErr(ctx, "cache.get", err)
+		return 0, c.logErr(ctx, "cache.get", err)
+	}
+	if val == absentMarker {
+		return len(val), errCachedAbsent
 	}
 
-	return deserialize(val, dest)
+	return len(val), c.encoder.decode(val, dest)
 }
 
 // GetOrSet returns the cached value if the key exists, otherwise calls fn,
 // stores the result, and returns it. Uses a single round-trip when the key exists.
+// See WithSingleflight, WithRecomputeLock and WithEarlyRefresh for stampede protection,
+// and WithNegativeTTL and WithStaleOnError for producers that find nothing or fail.
 func (c *cache) GetOrSet(ctx context.Context, key string, dest any, fn func() (any, error), opts ...SetOption) error {
-	err := c.Get(ctx, key, dest)
-	if err == nil {
-		return nil
-	}
-	if !errors.Is(err, ErrKeyNotFound) {
+	if err := validateDest(ctx, key, dest); err != nil {
 		return err
 	}
+	o := &setOptions{TTL: defaultTTL}
+	for _, opt := range opts {
+		opt(o)
+	}
 
-	value, err := fn()
+	start := time.Now()
+	found, refresh, err := c.lookup(ctx, key, dest, o)
+	if err == errCachedAbsent {
+		c.metrics.recordRequest(ctx, opGetOrSet, key, resultNegativeHit, start)
+		return ErrKeyNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("redis: GetOrSet producer failed: %w", err)
+		c.metrics.recordRequest(ctx, opGetOrSet, key, resultError, start)
+		return err
 	}
-
-	if setErr := c.Set(ctx, key, value, opts...); setErr != nil {
-		return setErr
+	if found && !refresh {
+		c.metrics.recordRequest(ctx, opGetOrSet, key, resultHit, start)
+		return nil
 	}
 
-	return deserialize(mustSerializeString(value), dest)
+	if o.RemainingTTL != nil {
+		// Only recompute knows the TTL of the value it writes.
+		*o.RemainingTTL = 0
+	}
+	raw, err := c.load(ctx, key, fn, o, found)
+	if found {
+		// An early refresh still served the cached value.
+		c.metrics.recordRequest(ctx, opGetOrSet, key, resultHit, start)
+	} else {
+		c.metrics.recordRequest(ctx, opGetOrSet, key, resultMiss, start)
+	}
+	if err != nil {
+		if found {
+			// Early refresh failed — dest already holds the current value.
+			_ = c.logErr(ctx, "cache.get_or_set", err)
+			return nil
+		}
+		return err
+	}
+	return c.encoder.decode(string(raw), dest)
 }
 
-// Delete removes one or more keys. Missing keys are silently ignored.
+// Delete removes one or more keys, along with their GetOrSet sidecar keys.
+// Missing keys are silently ignored.
 func (c *cache) Delete(ctx context.Context, keys ...string) error {
 	if ctx == nil {
 		return errors.New("redis: context is required")
@@ -117,7 +186,7 @@ func (c *cache) Delete(ctx context.Context, keys ...string) error {
 	for i, k := range keys {
 		prefixed[i] = c.key(k)
 	}
-	if err := c.instance.Del(ctx, prefixed...).Err(); err != nil {
+	if _, err := c.countKeys(ctx, goredis.Cmdable.Del, append(prefixed, sidecarKeys(prefixed)...)); err != nil {
 		return c.logErr(ctx, "cache.delete", err)
 	}
 	return nil
@@ -178,10 +247,14 @@ func (c *cache) TTL(ctx context.Context, key string) (time.Duration, error) {
 
 // AddToSet adds members to the Redis set stored at key.
 // If opts include WithTTL, the TTL is applied atomically via a Lua script.
+// Returns ErrEncryptionUnsupported when Config.Encryption is set.
 func (c *cache) AddToSet(ctx context.Context, key string, members []string, opts ...SetOption) error {
 	if err := validateKey(ctx, key); err != nil {
 		return err
 	}
+	if err := c.plaintextOnly(); err != nil {
+		return err
+	}
 	if len(members) == 0 {
 		return errors.New("redis: at least one member is required")
 	}
@@ -274,6 +347,7 @@ func (c *cache) ExistsInSet(ctx context.Context, key, member string) (bool, erro
 // --- Pub/Sub ---
 
 // Publish sends message to channel. message is JSON-serialized if not a primitive.
+// With Config.Encryption the payload is sealed; receivers open it with OpenMessage.
 func (c *cache) Publish(ctx context.Context, channel string, message any) error {
 	if ctx == nil {
 		return errors.New("redis: context is required")
@@ -285,6 +359,11 @@ func (c *cache) Publish(ctx context.Context, channel string, message any) error
 	if err != nil {
 		return err
 	}
+	if c.encoder.keyring != nil {
+		if payload, err = c.encoder.keyring.seal(formatArg(payload)); err != nil {
+			return err
+		}
+	}
 	if err := c.instance.Publish(ctx, channel, payload).Err(); err != nil {
 		return c.logErr(ctx, "cache.publish", err)
 	}
@@ -293,11 +372,13 @@ func (c *cache) Publish(ctx context.Context, channel string, message any) error
 
 // Subscribe returns a *goredis.PubSub subscription to the given channels.
 // The caller is responsible for closing the subscription via sub.Close().
+// With Config.Encryption, pass each payload through OpenMessage.
 //
 //	sub := cache.Subscribe(ctx, "notifications:user:123")
 //	defer sub.Close()
 //	for msg := range sub.Channel() {
-//	    // handle msg.Payload
+//	    payload, err := redis.OpenMessage(cache, msg.Payload)
+//	    // handle payload
 //	}
 func (c *cache) Subscribe(ctx context.Context, channels ...string) *goredis.PubSub {
 	return c.instance.Subscribe(ctx, channels...)
@@ -319,6 +400,26 @@ func (c *cache) key(k string) string {
 	return c.keyPrefix + ":" + k
 }
 
+// plaintextOnly rejects set and sorted-set writes when encryption is on: Redis
+// compares members, so they cannot be sealed with a random nonce.
+func (c *cache) plaintextOnly() error {
+	if c.encoder.keyring != nil {
+		return ErrEncryptionUnsupported
+	}
+	return nil
+}
+
+// openMessage decrypts a Pub/Sub payload sealed by Publish.
+func (c *cache) openMessage(payload string) (string, error) {
+	if !isEncrypted(payload) {
+		return payload, nil
+	}
+	if c.encoder.keyring == nil {
+		return "", errors.New("redis: message is encrypted and Config.Encryption is not set")
+	}
+	return c.encoder.keyring.open(payload)
+}
+
 func (c *cache) logErr(ctx context.Context, step string, err error) error {
 	if c.logger != nil {
 		c.logger.Error(ctx, step, err.Error())
@@ -339,7 +440,56 @@ func serialize(value any) (any, error) {
 	}
 }
 
+// formatArg renders a command argument the way go-redis writes it to Redis.
+func formatArg(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// deserialize decodes a stored value into dest. Values with a header are decoded
+// with the codec recorded in it; headerless values are raw text or JSON.
+// A *rawPayload dest receives the stored bytes untouched.
 func deserialize(val string, dest any) error {
+	if raw, ok := dest.(*rawPayload); ok {
+		*raw = rawPayload(val)
+		return nil
+	}
+	if val == absentMarker {
+		return ErrKeyNotFound
+	}
+	if isEncrypted(val) {
+		return errors.New("redis: failed to deserialize value: value is encrypted")
+	}
+	data, id, ok, err := decodeHeader(val)
+	if err != nil {
+		return fmt.Errorf("redis: failed to deserialize value: %w", err)
+	}
+	if ok {
+		return decodeWithCodec(data, id, dest)
+	}
+	return deserializeLegacy(val, dest)
+}
+
+// deserializeLegacy decodes headerless values: raw text for *string / *[]byte,
+// "1"/"0" for *bool (how go-redis writes booleans), JSON otherwise.
+func deserializeLegacy(val string, dest any) error {
 	switch d := dest.(type) {
 	case *string:
 		*d = val
@@ -347,6 +497,11 @@ func deserialize(val string, dest any) error {
 	case *[]byte:
 		*d = []byte(val)
 		return nil
+	case *bool:
+		if b, err := strconv.ParseBool(val); err == nil {
+			*d = b
+			return nil
+		}
 	}
 	if err := json.Unmarshal([]byte(val), dest); err != nil {
 		return fmt.Errorf("redis: failed to deserialize value: %w", err)
@@ -354,19 +509,6 @@ func deserialize(val string, dest any) error {
 	return nil
 }
 
-// mustSerializeString serializes value to its string representation for GetOrSet.
-func mustSerializeString(value any) string {
-	switch v := value.(type) {
-	case string:
-		return v
-	case []byte:
-		return string(v)
-	default:
-		b, _ := json.Marshal(v)
-		return string(b)
-	}
-}
-
 func toAny(ss []string) []any {
 	out := make([]any, len(ss))
 	for i, s := range ss {
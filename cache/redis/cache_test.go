@@ -3,6 +3,9 @@ package redis
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -254,3 +257,272 @@ func TestKeyPrefix(t *testing.T) {
 		t.Error("expected key to be stored with prefix svc:session")
 	}
 }
+
+// ---- GetOrSet stampede protection ----
+
+func TestGetOrSet_Singleflight(t *testing.T) {
+	c, _ := newTestCache(t)
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var got string
+			err := c.GetOrSet(ctx, "hot", &got, func() (any, error) {
+				calls.Add(1)
+				<-release
+				return "value", nil
+			}, WithSingleflight())
+			if err != nil || got != "value" {
+				t.Errorf("GetOrSet: got %q, %v", got, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn called %d times, want 1", n)
+	}
+}
+
+func TestGetOrSet_Singleflight_LeaderCancelled(t *testing.T) {
+	c, mr := newTestCache(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	leaderCtx, cancel := context.WithCancel(ctx)
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		var got string
+		leaderErr <- c.GetOrSet(leaderCtx, "hot", &got, func() (any, error) {
+			close(started)
+			<-release
+			return "value", nil
+		}, WithSingleflight())
+	}()
+	<-started
+
+	followerErr := make(chan error, 1)
+	var got string
+	go func() {
+		followerErr <- c.GetOrSet(ctx, "hot", &got, func() (any, error) {
+			t.Error("follower must share the leader's recompute")
+			return "other", nil
+		}, WithSingleflight())
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader err = %v, want context.Canceled", err)
+	}
+
+	close(release)
+	if err := <-followerErr; err != nil || got != "value" {
+		t.Fatalf("follower: got %q, %v", got, err)
+	}
+	if v, _ := mr.Get("test:hot"); v != "value" {
+		t.Errorf("stored %q, want value", v)
+	}
+}
+
+func TestGetOrSet_RecomputeLock_WaitsForHolder(t *testing.T) {
+	c, mr := newTestCache(t)
+	// Simulate another replica holding the recompute lock and writing the value shortly.
+	_ = mr.Set("test:report"+recomputeLockSuffix, "other-owner")
+	go func() {
+		time.Sleep(60 * time.Millisecond)
+		_ = mr.Set("test:report", "from-other-replica")
+	}()
+
+	calls := 0
+	var got string
+	err := c.GetOrSet(ctx, "report", &got, func() (any, error) {
+		calls++
+		return "local", nil
+	}, WithRecomputeLock(time.Second, time.Second))
+	if err != nil {
+		t.Fatalf("GetOrSet: %v", err)
+	}
+	if calls != 0 {
+		t.Error("fn should not be called while another replica holds the lock")
+	}
+	if got != "from-other-replica" {
+		t.Errorf("got %q, want value written by lock holder", got)
+	}
+}
+
+func TestGetOrSet_RecomputeLock_FallsBackAfterWait(t *testing.T) {
+	c, mr := newTestCache(t)
+	_ = mr.Set("test:report"+recomputeLockSuffix, "other-owner")
+
+	var got string
+	err := c.GetOrSet(ctx, "report", &got, func() (any, error) {
+		return "local", nil
+	}, WithRecomputeLock(time.Second, 20*time.Millisecond))
+	if err != nil || got != "local" {
+		t.Errorf("GetOrSet: got %q, %v", got, err)
+	}
+}
+
+func TestGetOrSet_EarlyRefresh(t *testing.T) {
+	c, mr := newTestCache(t)
+	var got string
+	_ = c.GetOrSet(ctx, "k", &got, func() (any, error) { return "v1", nil },
+		WithTTL(time.Minute), WithEarlyRefresh(1))
+	if !mr.Exists("test:k" + xfetchDeltaSuffix) {
+		t.Fatal("expected recompute duration to be recorded")
+	}
+
+	// A recompute that took far longer than the remaining TTL must trigger an early refresh.
+	_ = mr.Set("test:k"+xfetchDeltaSuffix, "100000000000")
+	err := c.GetOrSet(ctx, "k", &got, func() (any, error) { return "v2", nil },
+		WithTTL(time.Minute), WithEarlyRefresh(1))
+	if err != nil || got != "v2" {
+		t.Errorf("expected early refresh to v2, got %q, %v", got, err)
+	}
+}
+
+func TestGetOrSet_EarlyRefresh_ServesCurrentOnProducerError(t *testing.T) {
+	c, mr := newTestCache(t)
+	_ = c.Set(ctx, "k", "current", WithTTL(time.Minute))
+	_ = mr.Set("test:k"+xfetchDeltaSuffix, "100000000000")
+
+	var got string
+	err := c.GetOrSet(ctx, "k", &got, func() (any, error) { return nil, errors.New("db down") },
+		WithTTL(time.Minute), WithEarlyRefresh(1))
+	if err != nil || got != "current" {
+		t.Errorf("expected current value on refresh failure, got %q, %v", got, err)
+	}
+}
+
+func TestGetOrSet_NegativeTTL(t *testing.T) {
+	c, mr := newTestCache(t)
+	calls := 0
+	notFound := func() (any, error) {
+		calls++
+		return nil, fmt.Errorf("user 7: %w", ErrKeyNotFound)
+	}
+
+	var got string
+	for range 2 {
+		err := c.GetOrSet(ctx, "user:7", &got, notFound, WithNegativeTTL(30*time.Second))
+		if err != ErrKeyNotFound {
+			t.Fatalf("expected ErrKeyNotFound, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("producer called %d times, want 1", calls)
+	}
+	if ttl := mr.TTL("test:user:7"); ttl != 30*time.Second {
+		t.Errorf("marker TTL = %v, want 30s", ttl)
+	}
+	if err := c.Get(ctx, "user:7", &got); err != ErrKeyNotFound {
+		t.Errorf("Get on absent marker: expected ErrKeyNotFound, got %v", err)
+	}
+	found, err := c.GetMany(ctx, []string{"user:7"}, &map[string]string{})
+	if err != nil || found[0] {
+		t.Errorf("GetMany on absent marker = %v, %v", found, err)
+	}
+
+	mr.FastForward(31 * time.Second)
+	_ = c.GetOrSet(ctx, "user:7", &got, notFound, WithNegativeTTL(30*time.Second))
+	if calls != 2 {
+		t.Errorf("producer called %d times after marker expiry, want 2", calls)
+	}
+}
+
+func TestGetOrSet_NegativeTTL_OtherErrorsAreNotCached(t *testing.T) {
+	c, mr := newTestCache(t)
+	var got string
+	err := c.GetOrSet(ctx, "k", &got, func() (any, error) { return nil, errors.New("db down") },
+		WithNegativeTTL(time.Minute))
+	if err == nil || errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected producer error, got %v", err)
+	}
+	if mr.Exists("test:k") {
+		t.Error("a failing producer must not be cached as absent")
+	}
+}
+
+func TestGetOrSet_StaleOnError(t *testing.T) {
+	c, mr := newTestCache(t)
+	var got string
+	_ = c.GetOrSet(ctx, "k", &got, func() (any, error) { return "v1", nil },
+		WithTTL(time.Minute), WithStaleOnError(10*time.Minute))
+	if ttl := mr.TTL("test:k" + staleCopySuffix); ttl != 11*time.Minute {
+		t.Errorf("shadow TTL = %v, want 11m", ttl)
+	}
+
+	mr.FastForward(2 * time.Minute) // value expired, shadow still alive
+	failing := func() (any, error) { return nil, errors.New("db down") }
+	got = ""
+	err := c.GetOrSet(ctx, "k", &got, failing, WithTTL(time.Minute), WithStaleOnError(10*time.Minute))
+	if err != nil || got != "v1" {
+		t.Errorf("expected stale v1, got %q, %v", got, err)
+	}
+	if mr.Exists("test:k") {
+		t.Error("a stale fallback must not be written back as fresh")
+	}
+
+	mr.FastForward(10 * time.Minute) // past the staleness window
+	if err := c.GetOrSet(ctx, "k", &got, failing, WithStaleOnError(10*time.Minute)); err == nil {
+		t.Error("expected producer error once the shadow expired")
+	}
+}
+
+func TestDelete_RemovesSidecars(t *testing.T) {
+	failing := func() (any, error) { return nil, errors.New("db down") }
+	produce := func(c Cache, key string) {
+		var got string
+		_ = c.GetOrSet(ctx, key, &got, func() (any, error) { return "v1", nil },
+			WithTTL(time.Minute), WithStaleOnError(time.Hour), WithTags("t"), WithEarlyRefresh(1))
+	}
+	tests := []struct {
+		name   string
+		delete func(c Cache, key string) error
+	}{
+		{"Delete", func(c Cache, key string) error { return c.Delete(ctx, key) }},
+		{"InvalidateTags", func(c Cache, _ string) error { return c.InvalidateTags(ctx, "t") }},
+		{"DeleteByPattern", func(c Cache, key string) error {
+			n, err := c.DeleteByPattern(ctx, key)
+			if n != 1 {
+				t.Errorf("DeleteByPattern removed %d keys, want 1", n)
+			}
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mr := newTestCache(t)
+			produce(c, "k")
+			if err := tt.delete(c, "k"); err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			if err := c.GetOrSet(ctx, "k", &got, failing, WithStaleOnError(time.Hour)); err == nil {
+				t.Errorf("deleted value served as stale %q", got)
+			}
+			for _, k := range []string{"test:k", "test:k" + staleCopySuffix, "test:k" + xfetchDeltaSuffix} {
+				if mr.Exists(k) {
+					t.Errorf("redis key %s left behind", k)
+				}
+			}
+		})
+	}
+}
+
+func TestShouldRefreshEarly(t *testing.T) {
+	if shouldRefreshEarly(time.Millisecond, time.Hour, 1) {
+		t.Error("fast recompute far from expiry should not refresh")
+	}
+	if !shouldRefreshEarly(time.Hour, time.Millisecond, 1) {
+		t.Error("slow recompute near expiry should refresh")
+	}
+	if shouldRefreshEarly(time.Hour, -1, 1) {
+		t.Error("keys without expiry should never refresh early")
+	}
+}
@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"sync"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// keyCountCmd is a multi-key command that returns how many keys it affected (DEL, UNLINK).
+type keyCountCmd func(c goredis.Cmdable, ctx context.Context, keys ...string) *goredis.IntCmd
+
+// countKeys runs cmd over keys. In cluster mode keys may hash to different slots,
+// which a single multi-key command rejects with CROSSSLOT, so cmd is pipelined
+// once per key and go-redis routes each to its shard.
+func (c *cache) countKeys(ctx context.Context, cmd keyCountCmd, keys []string) (int64, error) {
+	if !c.cluster || len(keys) == 1 {
+		return cmd(c.instance, ctx, keys...).Result()
+	}
+	pipe := c.instance.Pipeline()
+	cmds := make([]*goredis.IntCmd, len(keys))
+	for i, k := range keys {
+		cmds[i] = cmd(pipe, ctx, k)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	var n int64
+	for _, r := range cmds {
+		n += r.Val()
+	}
+	return n, nil
+}
+
+// scanNodes returns the clients SCAN must visit: every master of a cluster,
+// otherwise the client itself. A cluster SCAN sent through the ClusterClient
+// only walks one shard.
+func (c *cache) scanNodes(ctx context.Context) ([]goredis.Cmdable, error) {
+	cc, ok := c.instance.(*goredis.ClusterClient)
+	if !ok {
+		return []goredis.Cmdable{c.instance}, nil
+	}
+	var (
+		mu    sync.Mutex
+		nodes []goredis.Cmdable
+	)
+	err := cc.ForEachMaster(ctx, func(_ context.Context, node *goredis.Client) error {
+		mu.Lock()
+		nodes = append(nodes, node)
+		mu.Unlock()
+		return nil
+	})
+	return nodes, err
+}
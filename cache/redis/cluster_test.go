@@ -0,0 +1,206 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// newTestClusterCache serves a single-shard cluster from miniredis, which answers
+// CLUSTER SLOTS with one node owning every slot.
+func newTestClusterCache(t *testing.T) (Cache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := goredis.NewClusterClient(&goredis.ClusterOptions{Addrs: []string{mr.Addr()}})
+	t.Cleanup(func() { _ = client.Close() })
+	return &cache{instance: client, cluster: true, keyPrefix: "test"}, mr
+}
+
+func TestCluster_KeyValueAndDelete(t *testing.T) {
+	c, mr := newTestClusterCache(t)
+	for _, k := range []string{"a", "b", "c"} {
+		if err := c.Set(ctx, k, k); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	var got string
+	if err := c.Get(ctx, "b", &got); err != nil || got != "b" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if err := c.Delete(ctx, "a", "b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if mr.Exists("test:a") || mr.Exists("test:b") || !mr.Exists("test:c") {
+		t.Error("Delete removed the wrong keys")
+	}
+}
+
+func TestCluster_ScanAndDeleteByPattern(t *testing.T) {
+	c, mr := newTestClusterCache(t)
+	_ = mr.Set("test:user:1", "x")
+	_ = mr.Set("test:user:2", "x")
+	_ = mr.Set("test:order:1", "x")
+
+	var keys []string
+	for k, err := range c.Scan(ctx, "user:*") {
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"user:1", "user:2"}) {
+		t.Errorf("Scan = %v", keys)
+	}
+
+	n, err := c.DeleteByPattern(ctx, "user:*")
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteByPattern = %d, %v", n, err)
+	}
+	if !mr.Exists("test:order:1") {
+		t.Error("non-matching key removed")
+	}
+}
+
+func TestCluster_PubSub(t *testing.T) {
+	c, _ := newTestClusterCache(t)
+	sub := c.Subscribe(ctx, "events")
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if err := c.Publish(ctx, "events", "hello"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	msg, err := sub.ReceiveMessage(ctx)
+	if err != nil || msg.Payload != "hello" {
+		t.Fatalf("ReceiveMessage = %v, %v", msg, err)
+	}
+}
+
+// multiKeyCheck fails t on scripts and on DEL/UNLINK over several keys, which a
+// real cluster may reject when the keys span slots.
+type multiKeyCheck struct{ t *testing.T }
+
+func (h multiKeyCheck) DialHook(next goredis.DialHook) goredis.DialHook { return next }
+
+func (h multiKeyCheck) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		h.check(cmd)
+		return next(ctx, cmd)
+	}
+}
+
+func (h multiKeyCheck) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		for _, cmd := range cmds {
+			h.check(cmd)
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func (h multiKeyCheck) check(cmd goredis.Cmder) {
+	switch cmd.Name() {
+	case "eval", "evalsha":
+		h.t.Errorf("unexpected script: %v", cmd.Args())
+	case "del", "unlink":
+		if len(cmd.Args()) > 2 {
+			h.t.Errorf("multi-key %s: %v", cmd.Name(), cmd.Args())
+		}
+	}
+}
+
+func TestCluster_InvalidateTags(t *testing.T) {
+	c, mr := newTestClusterCache(t)
+	// Members on different slots than their tag set, as a cluster may hold them.
+	for _, k := range []string{"test:view:1", "test:view:2", "test:view:2" + staleCopySuffix, "test:other"} {
+		_ = mr.Set(k, "x")
+	}
+	_, _ = mr.SetAdd("test:"+tagKeyPrefix+"product", "test:view:1", "test:view:2")
+	_, _ = mr.SetAdd("test:"+tagKeyPrefix+"tenant", "test:view:2")
+
+	c.(*cache).instance.AddHook(multiKeyCheck{t})
+	if err := c.InvalidateTags(ctx, "product", "tenant"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	if keys := mr.Keys(); !slices.Equal(keys, []string{"test:other"}) {
+		t.Errorf("keys left = %v, want [test:other]", keys)
+	}
+}
+
+func TestConfig_NewUniversalClient(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"standalone", Config{Host: "localhost"}, "*redis.Client"},
+		{"cluster", Config{Mode: ModeCluster, Addrs: []string{"a:6379", "b:6379"}}, "*redis.ClusterClient"},
+		{"sentinel", Config{Mode: ModeSentinel, Addrs: []string{"s:26379"}, MasterName: "mymaster"}, "*redis.Client"},
+		{"sentinel replicas", Config{Mode: ModeSentinel, Addrs: []string{"s:26379"}, MasterName: "mymaster", ReadFromReplica: true}, "*redis.ClusterClient"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := tt.cfg.newUniversalClient()
+			if err != nil {
+				t.Fatalf("newUniversalClient: %v", err)
+			}
+			defer client.Close()
+			if got := fmt.Sprintf("%T", client); got != tt.want {
+				t.Errorf("client = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateTopology(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"unknown mode", Config{Mode: "ring", Host: "localhost"}},
+		{"sentinel without master", Config{Mode: ModeSentinel, Addrs: []string{"s:26379"}}},
+		{"cluster with db", Config{Mode: ModeCluster, Addrs: []string{"a:6379"}, DB: 1}},
+		{"no address", Config{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.validateTopology(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestConfig_TLSServerNameFromAddrs(t *testing.T) {
+	cfg := Config{Mode: ModeCluster, Addrs: []string{"cfg.cache.amazonaws.com:6379"}, TLS: true}
+	if got := cfg.toUniversalOptions().TLSConfig.ServerName; got != "cfg.cache.amazonaws.com" {
+		t.Errorf("ServerName = %q", got)
+	}
+}
+
+func TestConfigFromEnv_Cluster(t *testing.T) {
+	t.Setenv("CLUSTER_REDIS_PASSWORD", "secret")
+	t.Setenv("CLUSTER_REDIS_TLS_SERVER_NAME", "localhost")
+	t.Setenv("CLUSTER_REDIS_KEY_PREFIX", "svc")
+	t.Setenv("CLUSTER_REDIS_MODE", "cluster")
+	t.Setenv("CLUSTER_REDIS_ADDRS", "a:6379, b:6379")
+	t.Setenv("CLUSTER_REDIS_READ_FROM_REPLICA", "true")
+	cfg, err := ConfigFromEnv("CLUSTER_REDIS")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if cfg.Mode != ModeCluster || !slices.Equal(cfg.Addrs, []string{"a:6379", "b:6379"}) || !cfg.ReadFromReplica {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	t.Setenv("CLUSTER_REDIS_MODE", "sentinel")
+	if _, err := ConfigFromEnv("CLUSTER_REDIS"); err == nil {
+		t.Error("expected error for sentinel without master name")
+	}
+}
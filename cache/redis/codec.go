@@ -0,0 +1,363 @@
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec turns structured values into bytes and back. Primitives (string, []byte,
+// int, int64, float64, bool) bypass the codec and are stored as-is.
+//
+// ID is written into the value header so a value stays readable after the
+// configured codec changes. IDs 0–15 are reserved for built-in codecs; custom
+// codecs use 16–63 and must be registered with RegisterCodec.
+type Codec interface {
+	ID() byte
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// Compression selects the algorithm applied to payloads above Config.CompressionThreshold.
+type Compression uint8
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Zstd
+)
+
+// Built-in codecs.
+var (
+	JSONCodec     Codec = jsonCodec{}
+	MsgpackCodec  Codec = msgpackCodec{}
+	GobCodec      Codec = gobCodec{}
+	ProtobufCodec Codec = protobufCodec{} // values must implement proto.Message
+)
+
+const (
+	// Header: marker byte followed by a format byte (codec ID in the low 6 bits,
+	// Compression in the high 2). 0xFF never occurs in UTF-8, so headerless JSON
+	// and text written before codecs existed are never mistaken for a header.
+	headerMarker    = 0xFF
+	headerLen       = 2
+	codecIDMask     = 0x3F
+	compressionBits = 6
+
+	codecRawID       byte = 0 // bytes are the value itself (string / []byte)
+	codecJSONID      byte = 1
+	codecMsgpackID   byte = 2
+	codecGobID       byte = 3
+	codecProtobufID  byte = 4
+	codecEncryptedID byte = 14 // key ID, nonce and AES-GCM ciphertext of an encoded value
+	codecAbsentID    byte = 15 // negative-cache marker written by WithNegativeTTL; no payload
+	minCustomCodec   byte = 16
+
+	defaultCompressionThreshold = 1024
+)
+
+// absentMarker is the whole value of a key cached as absent by WithNegativeTTL.
+const absentMarker = "\xff\x0f" // headerMarker, codecAbsentID
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[byte]Codec{
+		codecJSONID:     JSONCodec,
+		codecMsgpackID:  MsgpackCodec,
+		codecGobID:      GobCodec,
+		codecProtobufID: ProtobufCodec,
+	}
+
+	codecNames = map[string]Codec{
+		"json":     JSONCodec,
+		"msgpack":  MsgpackCodec,
+		"gob":      GobCodec,
+		"protobuf": ProtobufCodec,
+	}
+	compressionNames = map[string]Compression{
+		"":     NoCompression,
+		"none": NoCompression,
+		"gzip": Gzip,
+		"zstd": Zstd,
+	}
+)
+
+// RegisterCodec makes a custom codec available for decoding. Call it at init time
+// in every service that reads values written with the codec.
+func RegisterCodec(c Codec) error {
+	if c == nil {
+		return errors.New("redis: codec is required")
+	}
+	id := c.ID()
+	if id < minCustomCodec || id > codecIDMask {
+		return fmt.Errorf("redis: custom codec ID must be between %d and %d, got %d", minCustomCodec, codecIDMask, id)
+	}
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if existing, ok := codecs[id]; ok && existing != c {
+		return fmt.Errorf("redis: codec ID %d is already registered", id)
+	}
+	codecs[id] = c
+	return nil
+}
+
+func lookupCodec(id byte) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[id]
+	return c, ok
+}
+
+// --- encoding ---
+
+// encoder holds the value format used by a cache: codec plus optional compression
+// and encryption.
+type encoder struct {
+	codec       Codec
+	compression Compression
+	threshold   int
+	keyring     *keyring // nil = values are stored in plaintext
+}
+
+// encode renders value for storage. Numbers and booleans keep the format go-redis
+// writes, so INCRBY and other clients keep working; they are never encrypted.
+// Without encryption, JSON and text below the compression threshold are written
+// without a header, exactly as before codecs existed.
+func (e encoder) encode(value any, codec Codec) (string, error) {
+	if codec == nil {
+		codec = e.codec
+	}
+	if codec == nil {
+		codec = JSONCodec
+	}
+
+	var (
+		data []byte
+		id   = codecRawID
+	)
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	default:
+		b, err := codec.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("redis: failed to serialize value: %w", err)
+		}
+		data, id = b, codec.ID()
+	}
+
+	compression := NoCompression
+	if e.compression != NoCompression && len(data) >= e.threshold {
+		compressed, err := compress(e.compression, data)
+		if err != nil {
+			return "", err
+		}
+		if len(compressed) < len(data) {
+			data, compression = compressed, e.compression
+		}
+	}
+
+	legacy := id == codecRawID || id == codecJSONID
+	var encoded string
+	if compression == NoCompression && legacy && (len(data) == 0 || data[0] != headerMarker) {
+		encoded = string(data)
+	} else {
+		out := make([]byte, 0, headerLen+len(data))
+		out = append(out, headerMarker, id|byte(compression)<<compressionBits)
+		encoded = string(append(out, data...))
+	}
+	if e.keyring != nil {
+		return e.keyring.seal(encoded)
+	}
+	return encoded, nil
+}
+
+// decode deserializes a value read from storage into dest, decrypting it first
+// when it was written with encryption.
+func (e encoder) decode(val string, dest any) error {
+	if isEncrypted(val) {
+		if e.keyring == nil {
+			return errors.New("redis: failed to deserialize value: value is encrypted and Config.Encryption is not set")
+		}
+		plain, err := e.keyring.open(val)
+		if err != nil {
+			return err
+		}
+		val = plain
+	}
+	return deserialize(val, dest)
+}
+
+// decodeHeader strips the header from val, decompressing the payload if needed.
+// ok is false for headerless values.
+func decodeHeader(val string) (data []byte, id byte, ok bool, err error) {
+	if len(val) < headerLen || val[0] != headerMarker {
+		return nil, 0, false, nil
+	}
+	format := val[1]
+	id = format & codecIDMask
+	data = []byte(val[headerLen:])
+	if c := Compression(format >> compressionBits); c != NoCompression {
+		if data, err = decompress(c, data); err != nil {
+			return nil, 0, true, err
+		}
+	}
+	return data, id, true, nil
+}
+
+func decodeWithCodec(data []byte, id byte, dest any) error {
+	if id == codecRawID || id == codecJSONID {
+		return deserializeLegacy(string(data), dest)
+	}
+	codec, ok := lookupCodec(id)
+	if !ok {
+		return fmt.Errorf("redis: failed to deserialize value: unknown codec ID %d", id)
+	}
+	if err := codec.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("redis: failed to deserialize value: %w", err)
+	}
+	return nil
+}
+
+// --- compression ---
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+func zstdCodecs() (*zstd.Encoder, *zstd.Decoder, error) {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdEncoder, zstdDecoder, zstdErr
+}
+
+func compress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case Gzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("redis: gzip compress: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("redis: gzip compress: %w", err)
+		}
+		return buf.Bytes(), nil
+	case Zstd:
+		enc, _, err := zstdCodecs()
+		if err != nil {
+			return nil, fmt.Errorf("redis: zstd compress: %w", err)
+		}
+		return enc.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("redis: unknown compression %d", c)
+	}
+}
+
+func decompress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("redis: gzip decompress: %w", err)
+		}
+		defer r.Close()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("redis: gzip decompress: %w", err)
+		}
+		return out, nil
+	case Zstd:
+		_, dec, err := zstdCodecs()
+		if err != nil {
+			return nil, fmt.Errorf("redis: zstd decompress: %w", err)
+		}
+		out, err := dec.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("redis: zstd decompress: %w", err)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown compression %d", c)
+	}
+}
+
+// --- built-in codecs ---
+
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte                           { return codecJSONID }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ID() byte                           { return codecMsgpackID }
+func (msgpackCodec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) ID() byte { return codecGobID }
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) ID() byte { return codecProtobufID }
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
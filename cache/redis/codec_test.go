@@ -0,0 +1,249 @@
+package redis
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type codecUser struct {
+	ID   int      `json:"id" msgpack:"id"`
+	Name string   `json:"name" msgpack:"name"`
+	Tags []string `json:"tags" msgpack:"tags"`
+}
+
+// newCodecCache returns a cache on mr using cfg's codec settings, so several
+// caches with different settings can share one server.
+func newCodecCache(t *testing.T, mr *miniredis.Miniredis, cfg Config) Cache {
+	t.Helper()
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return &cache{instance: client, keyPrefix: "test", encoder: cfg.encoder()}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	in := codecUser{ID: 7, Name: "alice", Tags: []string{"a", "b"}}
+	tests := []struct {
+		name       string
+		codec      Codec
+		wantHeader bool
+	}{
+		{"json", JSONCodec, false},
+		{"msgpack", MsgpackCodec, true},
+		{"gob", GobCodec, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			c := newCodecCache(t, mr, Config{Codec: tt.codec})
+			if err := c.Set(ctx, "u", in); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			var out codecUser
+			if err := c.Get(ctx, "u", &out); err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if out.ID != in.ID || out.Name != in.Name || len(out.Tags) != 2 {
+				t.Errorf("got %+v, want %+v", out, in)
+			}
+			raw, _ := mr.Get("test:u")
+			if hasHeader := raw[0] == headerMarker; hasHeader != tt.wantHeader {
+				t.Errorf("header present = %v, want %v", hasHeader, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestCodec_Protobuf_PerCall(t *testing.T) {
+	c, _ := newTestCache(t)
+	if err := c.Set(ctx, "pb", wrapperspb.String("hello"), WithCodec(ProtobufCodec)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	var out wrapperspb.StringValue
+	if err := c.Get(ctx, "pb", &out); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if out.GetValue() != "hello" {
+		t.Errorf("got %q, want hello", out.GetValue())
+	}
+	if err := c.Set(ctx, "pb", codecUser{}, WithCodec(ProtobufCodec)); err == nil {
+		t.Error("expected error for non-proto value")
+	}
+}
+
+func TestCodec_ReadableAfterSwitch(t *testing.T) {
+	mr := miniredis.RunT(t)
+	jsonCache := newCodecCache(t, mr, Config{})
+	msgpackCache := newCodecCache(t, mr, Config{Codec: MsgpackCodec, Compression: Zstd, CompressionThreshold: 1})
+
+	_ = jsonCache.Set(ctx, "old", codecUser{ID: 1})
+	_ = msgpackCache.Set(ctx, "new", codecUser{ID: 2})
+
+	var u codecUser
+	if err := msgpackCache.Get(ctx, "old", &u); err != nil || u.ID != 1 {
+		t.Errorf("JSON value via msgpack cache = %+v, %v", u, err)
+	}
+	if err := jsonCache.Get(ctx, "new", &u); err != nil || u.ID != 2 {
+		t.Errorf("msgpack value via JSON cache = %+v, %v", u, err)
+	}
+}
+
+func TestCodec_LegacyValues(t *testing.T) {
+	c, mr := newTestCache(t)
+	// Written before codecs existed: plain JSON and raw text.
+	_ = mr.Set("test:json", `{"id":3,"name":"bob"}`)
+	_ = mr.Set("test:text", "plain")
+
+	var u codecUser
+	if err := c.Get(ctx, "json", &u); err != nil || u.Name != "bob" {
+		t.Errorf("legacy JSON = %+v, %v", u, err)
+	}
+	var s string
+	if err := c.Get(ctx, "text", &s); err != nil || s != "plain" {
+		t.Errorf("legacy text = %q, %v", s, err)
+	}
+}
+
+func TestCodec_Compression(t *testing.T) {
+	big := codecUser{Name: strings.Repeat("x", 4096)}
+	for _, comp := range []Compression{Gzip, Zstd} {
+		mr := miniredis.RunT(t)
+		c := newCodecCache(t, mr, Config{Compression: comp, CompressionThreshold: 100})
+
+		if err := c.Set(ctx, "big", big); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		raw, _ := mr.Get("test:big")
+		if raw[0] != headerMarker || Compression(raw[1]>>compressionBits) != comp {
+			t.Errorf("compression %d: value not compressed", comp)
+		}
+		if len(raw) >= len(big.Name) {
+			t.Errorf("compression %d: stored %d bytes, want fewer than %d", comp, len(raw), len(big.Name))
+		}
+		var out codecUser
+		if err := c.Get(ctx, "big", &out); err != nil || out.Name != big.Name {
+			t.Errorf("compression %d: round trip failed: %v", comp, err)
+		}
+
+		// Large strings are compressed too and still read back as text.
+		_ = c.Set(ctx, "text", big.Name)
+		var s string
+		if err := c.Get(ctx, "text", &s); err != nil || s != big.Name {
+			t.Errorf("compression %d: string round trip failed: %v", comp, err)
+		}
+
+		// Below the threshold values stay headerless.
+		_ = c.Set(ctx, "small", codecUser{ID: 1})
+		if raw, _ := mr.Get("test:small"); raw[0] == headerMarker {
+			t.Errorf("compression %d: small value should not have a header", comp)
+		}
+	}
+}
+
+func TestCodec_PrimitivesStayRaw(t *testing.T) {
+	c, mr := newTestCache(t)
+	c.(*cache).encoder = Config{Codec: MsgpackCodec, Compression: Gzip, CompressionThreshold: 1}.encoder()
+
+	_ = c.Set(ctx, "n", 41)
+	if n, err := c.Increment(ctx, "n", 1); err != nil || n != 42 {
+		t.Errorf("Increment after Set = %d, %v; want 42", n, err)
+	}
+	_ = c.Set(ctx, "flag", true)
+	if raw, _ := mr.Get("test:flag"); raw != "1" {
+		t.Errorf("bool stored as %q, want \"1\"", raw)
+	}
+	var b bool
+	if err := c.Get(ctx, "flag", &b); err != nil || !b {
+		t.Errorf("Get bool = %v, %v; want true", b, err)
+	}
+}
+
+func TestCodec_EscapesMarkerByte(t *testing.T) {
+	c, mr := newTestCache(t)
+	in := []byte{headerMarker, 0x01, 'x'}
+	if err := c.Set(ctx, "bin", in); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if raw, _ := mr.Get("test:bin"); len(raw) != len(in)+headerLen {
+		t.Errorf("expected header to be added, stored %d bytes", len(raw))
+	}
+	var out []byte
+	if err := c.Get(ctx, "bin", &out); err != nil || string(out) != string(in) {
+		t.Errorf("got %v, %v; want %v", out, err, in)
+	}
+}
+
+func TestCodec_GetOrSet_UsesCodec(t *testing.T) {
+	c, mr := newTestCache(t)
+	var out codecUser
+	err := c.GetOrSet(ctx, "u", &out, func() (any, error) {
+		return codecUser{ID: 9}, nil
+	}, WithCodec(MsgpackCodec))
+	if err != nil || out.ID != 9 {
+		t.Fatalf("GetOrSet = %+v, %v", out, err)
+	}
+	if raw, _ := mr.Get("test:u"); raw[0] != headerMarker || raw[1]&codecIDMask != codecMsgpackID {
+		t.Error("expected msgpack header on stored value")
+	}
+}
+
+type upperCodec struct{}
+
+func (upperCodec) ID() byte { return 20 }
+func (upperCodec) Marshal(v any) ([]byte, error) {
+	return []byte(strings.ToUpper(v.(codecUser).Name)), nil
+}
+func (upperCodec) Unmarshal(data []byte, v any) error {
+	v.(*codecUser).Name = string(data)
+	return nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	if err := RegisterCodec(nil); err == nil {
+		t.Error("expected error for nil codec")
+	}
+	if err := RegisterCodec(JSONCodec); err == nil {
+		t.Error("expected error for reserved ID")
+	}
+	if err := RegisterCodec(upperCodec{}); err != nil {
+		t.Fatalf("RegisterCodec: %v", err)
+	}
+
+	c, mr := newTestCache(t)
+	_ = c.Set(ctx, "u", codecUser{Name: "alice"}, WithCodec(upperCodec{}))
+	var out codecUser
+	if err := c.Get(ctx, "u", &out); err != nil || out.Name != "ALICE" {
+		t.Errorf("custom codec = %+v, %v", out, err)
+	}
+
+	// A value whose codec is not registered here cannot be decoded.
+	_ = mr.Set("test:unknown", string([]byte{headerMarker, 30, 'x'}))
+	if err := c.Get(ctx, "unknown", &out); err == nil || errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected decode error for unknown codec, got %v", err)
+	}
+}
+
+func TestConfigFromEnv_Codec(t *testing.T) {
+	t.Setenv("CODEC_REDIS_HOST", "localhost")
+	t.Setenv("CODEC_REDIS_PASSWORD", "secret")
+	t.Setenv("CODEC_REDIS_TLS_SERVER_NAME", "localhost")
+	t.Setenv("CODEC_REDIS_KEY_PREFIX", "svc")
+	t.Setenv("CODEC_REDIS_CODEC", "msgpack")
+	t.Setenv("CODEC_REDIS_COMPRESSION", "zstd")
+	cfg, err := ConfigFromEnv("CODEC_REDIS")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if cfg.Codec != MsgpackCodec || cfg.Compression != Zstd || cfg.CompressionThreshold != defaultCompressionThreshold {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	t.Setenv("CODEC_REDIS_CODEC", "yaml")
+	if _, err := ConfigFromEnv("CODEC_REDIS"); err == nil {
+		t.Error("expected error for unknown codec")
+	}
+}
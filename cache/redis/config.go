@@ -1,25 +1,59 @@
 package redis
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/juanMaAV92/go-utils/env"
 	goredis "github.com/redis/go-redis/v9"
 )
 
+// Mode selects the Redis deployment topology.
+type Mode string
+
+const (
+	ModeStandalone Mode = "standalone" // single node (default)
+	ModeCluster    Mode = "cluster"    // Redis Cluster / ElastiCache cluster mode
+	ModeSentinel   Mode = "sentinel"   // master discovered through Sentinel
+)
+
 // Config holds the configuration for a Redis client.
 type Config struct {
-	Host          string
+	Mode          Mode          // default ModeStandalone
+	Host          string        // standalone host; Addrs takes precedence when set
 	Port          string        // default "6379"
 	Password      string        // empty = no auth
-	DB            int           // logical database index; default 0
+	DB            int           // logical database index; default 0 (must be 0 in cluster mode)
 	TLS           bool          // enable TLS
 	TLSServerName string        // TLS SNI; defaults to Host when empty
 	ReadTimeout   time.Duration // default 3s
 	WriteTimeout  time.Duration // default 3s
 	KeyPrefix     string        // prepended to every key: "{prefix}:{key}"
+
+	Addrs            []string // "host:port" cluster seed nodes or sentinels; default Host:Port
+	MasterName       string   // sentinel master set name; required in sentinel mode
+	SentinelPassword string   // sentinel auth, if different from the data nodes
+	RouteByLatency   bool     // cluster/sentinel: send read-only commands to the closest node
+	ReadFromReplica  bool     // cluster/sentinel: allow read-only commands on replicas
+
+	Codec                Codec       // structured values; default JSONCodec
+	Compression          Compression // NoCompression (default), Gzip or Zstd
+	CompressionThreshold int         // compress payloads of at least this many bytes; default 1024
+
+	// Encryption, when set, encrypts stored values and hash field values with
+	// AES-256-GCM (see Keyring). Numbers and booleans stay plaintext.
+	// Pub/Sub messages are sealed too. Set and sorted-set writes return
+	// ErrEncryptionUnsupported.
+	Encryption *Keyring
+
+	// KeyNamespace maps a key to the "cache.namespace" metric attribute; default
+	// FirstKeySegment. Keep the result low-cardinality — never return the id part.
+	KeyNamespace func(key string) string
 }
 
 // ConfigFromEnv reads Redis configuration from environment variables.
@@ -28,16 +62,22 @@ type Config struct {
 //	ConfigFromEnv("REDIS")        → REDIS_HOST, REDIS_PORT, REDIS_PASSWORD …
 //	ConfigFromEnv("SESSION_REDIS") → SESSION_REDIS_HOST …
 //
-// Required: {prefix}_HOST
+// Required: {prefix}_HOST, or {prefix}_ADDRS (comma-separated host:port list)
 // Optional: {prefix}_PORT (6379), {prefix}_PASSWORD, {prefix}_DB (0),
 //
 //	{prefix}_TLS (false), {prefix}_TLS_SERVER_NAME,
 //	{prefix}_READ_TIMEOUT (3s), {prefix}_WRITE_TIMEOUT (3s),
-//	{prefix}_KEY_PREFIX
+//	{prefix}_KEY_PREFIX, {prefix}_CODEC (json|msgpack|gob|protobuf),
+//	{prefix}_COMPRESSION (none|gzip|zstd), {prefix}_COMPRESSION_THRESHOLD (1024),
+//	{prefix}_MODE (standalone|cluster|sentinel), {prefix}_MASTER_NAME,
+//	{prefix}_SENTINEL_PASSWORD, {prefix}_ROUTE_BY_LATENCY (false),
+//	{prefix}_READ_FROM_REPLICA (false),
+//	{prefix}_ENCRYPTION_KEYS ("id:base64key,…", first is primary; see KeyringFromString)
 func ConfigFromEnv(prefix string) (Config, error) {
 	p := prefix + "_"
 	cfg := Config{
-		Host:          env.GetEnv(p + "HOST"),
+		Mode:          Mode(strings.ToLower(env.GetEnvWithDefault(p+"MODE", string(ModeStandalone)))),
+		Host:          env.GetEnvWithDefault(p+"HOST", ""),
 		Port:          env.GetEnvWithDefault(p+"PORT", "6379"),
 		Password:      env.GetEnv(p + "PASSWORD"),
 		DB:            env.GetEnvAsIntWithDefault(p+"DB", 0),
@@ -46,27 +86,196 @@ func ConfigFromEnv(prefix string) (Config, error) {
 		ReadTimeout:   env.GetEnvAsDurationWithDefault(p+"READ_TIMEOUT", 3*time.Second),
 		WriteTimeout:  env.GetEnvAsDurationWithDefault(p+"WRITE_TIMEOUT", 3*time.Second),
 		KeyPrefix:     env.GetEnv(p + "KEY_PREFIX"),
+
+		Addrs:            env.GetEnvAsSliceWithDefault(p+"ADDRS", ",", nil),
+		MasterName:       env.GetEnvWithDefault(p+"MASTER_NAME", ""),
+		SentinelPassword: env.GetEnvWithDefault(p+"SENTINEL_PASSWORD", ""),
+		RouteByLatency:   env.GetEnvAsBoolWithDefault(p+"ROUTE_BY_LATENCY", false),
+		ReadFromReplica:  env.GetEnvAsBoolWithDefault(p+"READ_FROM_REPLICA", false),
+
+		CompressionThreshold: env.GetEnvAsIntWithDefault(p+"COMPRESSION_THRESHOLD", defaultCompressionThreshold),
+	}
+
+	if cfg.Host == "" && len(cfg.Addrs) == 0 {
+		return Config{}, fmt.Errorf("redis: missing required env var: %s or %s", p+"HOST", p+"ADDRS")
+	}
+	if err := cfg.validateTopology(); err != nil {
+		return Config{}, err
+	}
+
+	codecName := strings.ToLower(env.GetEnvWithDefault(p+"CODEC", "json"))
+	codec, ok := codecNames[codecName]
+	if !ok {
+		return Config{}, fmt.Errorf("redis: unknown codec %q in %s", codecName, p+"CODEC")
+	}
+	cfg.Codec = codec
+
+	compressionName := strings.ToLower(env.GetEnvWithDefault(p+"COMPRESSION", "none"))
+	compression, ok := compressionNames[compressionName]
+	if !ok {
+		return Config{}, fmt.Errorf("redis: unknown compression %q in %s", compressionName, p+"COMPRESSION")
 	}
+	cfg.Compression = compression
 
-	if cfg.Host == "" {
-		return Config{}, fmt.Errorf("redis: missing required env var: %s", p+"HOST")
+	if keys := env.GetEnvWithDefault(p+"ENCRYPTION_KEYS", ""); keys != "" {
+		keyring, err := KeyringFromString(keys)
+		if err != nil {
+			return Config{}, fmt.Errorf("%w in %s", err, p+"ENCRYPTION_KEYS")
+		}
+		cfg.Encryption = keyring
 	}
 	return cfg, nil
 }
 
-func (c Config) toRedisOptions() *goredis.Options {
-	opts := &goredis.Options{
-		Addr:         c.Host + ":" + c.Port,
-		Password:     c.Password,
-		DB:           c.DB,
-		ReadTimeout:  c.ReadTimeout,
-		WriteTimeout: c.WriteTimeout,
+// newEncoder is encoder plus the validated Encryption keyring.
+func (c Config) newEncoder() (encoder, error) {
+	e := c.encoder()
+	if c.Encryption != nil {
+		keyring, err := newKeyring(*c.Encryption)
+		if err != nil {
+			return encoder{}, err
+		}
+		e.keyring = keyring
+	}
+	return e, nil
+}
+
+func (c Config) encoder() encoder {
+	e := encoder{codec: c.Codec, compression: c.Compression, threshold: c.CompressionThreshold}
+	if e.codec == nil {
+		e.codec = JSONCodec
+	}
+	if e.threshold <= 0 {
+		e.threshold = defaultCompressionThreshold
+	}
+	return e
+}
+
+// NearCacheConfig configures the in-process layer created by NewNearCache.
+type NearCacheConfig struct {
+	MaxEntries int           // LRU capacity; default 10000
+	LocalTTL   time.Duration // max lifetime of a local entry, capped at the Redis key TTL; default 1m
+	Channel    string        // Pub/Sub channel for invalidations; default "cache:invalidate"
+
+	// KeyTTL optionally overrides LocalTTL per key (e.g. by namespace).
+	// Return 0 to bypass the local store for that key.
+	KeyTTL func(key string) time.Duration
+}
+
+func (c NearCacheConfig) withDefaults() NearCacheConfig {
+	if c.MaxEntries <= 0 {
+		c.MaxEntries = 10000
+	}
+	if c.LocalTTL <= 0 {
+		c.LocalTTL = time.Minute
+	}
+	if c.Channel == "" {
+		c.Channel = "cache:invalidate"
+	}
+	return c
+}
+
+// SubscribeConfig configures SubscribeHandler.
+type SubscribeConfig struct {
+	Workers int // concurrent handler calls; default 10
+	Buffer  int // received messages queued for the workers; default 100
+}
+
+func (c SubscribeConfig) withDefaults() SubscribeConfig {
+	if c.Workers <= 0 {
+		c.Workers = 10
+	}
+	if c.Buffer <= 0 {
+		c.Buffer = 100
+	}
+	return c
+}
+
+// ElectionConfig configures NewElection.
+type ElectionConfig struct {
+	Key           string        // lock key shared by all candidates; required
+	TTL           time.Duration // leadership lease, renewed every TTL/3; default 15s
+	RetryInterval time.Duration // how often followers try to take over; default TTL/3
+
+	// OnElected runs in its own goroutine when this replica becomes leader. ctx is
+	// cancelled when leadership ends; the election waits for OnElected to return
+	// before calling OnRevoked and campaigning again.
+	OnElected func(ctx context.Context)
+	// OnRevoked is called after leadership ends: the lease was lost, could not be
+	// renewed in time, or Run's ctx was cancelled.
+	OnRevoked func()
+}
+
+func (c ElectionConfig) withDefaults() ElectionConfig {
+	if c.TTL <= 0 {
+		c.TTL = 15 * time.Second
+	}
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = c.TTL / 3
+	}
+	return c
+}
+
+// validateTopology checks the Mode-specific fields.
+func (c Config) validateTopology() error {
+	switch c.Mode {
+	case "", ModeStandalone:
+	case ModeCluster:
+		if c.DB != 0 {
+			return errors.New("redis: DB must be 0 in cluster mode")
+		}
+	case ModeSentinel:
+		if c.MasterName == "" {
+			return errors.New("redis: MasterName is required in sentinel mode")
+		}
+	default:
+		return fmt.Errorf("redis: unknown mode %q", c.Mode)
+	}
+	if c.Host == "" && len(c.Addrs) == 0 {
+		return errors.New("redis: Host or Addrs is required")
+	}
+	return nil
+}
+
+// addrs returns Addrs, falling back to Host:Port.
+func (c Config) addrs() []string {
+	if len(c.Addrs) > 0 {
+		return c.Addrs
+	}
+	port := c.Port
+	if port == "" {
+		port = "6379"
+	}
+	return []string{net.JoinHostPort(c.Host, port)}
+}
+
+func (c Config) toUniversalOptions() *goredis.UniversalOptions {
+	opts := &goredis.UniversalOptions{
+		Addrs:            c.addrs(),
+		Password:         c.Password,
+		DB:               c.DB,
+		ReadTimeout:      c.ReadTimeout,
+		WriteTimeout:     c.WriteTimeout,
+		MasterName:       c.MasterName,
+		SentinelPassword: c.SentinelPassword,
+		RouteByLatency:   c.RouteByLatency,
+	}
+	switch c.Mode {
+	case ModeCluster:
+		opts.ReadOnly = c.ReadFromReplica
+	case ModeSentinel:
+		// For sentinel, ReadOnly would send writes to replicas too;
+		// random routing spreads reads over master and replicas instead.
+		opts.RouteRandomly = c.ReadFromReplica && !c.RouteByLatency
 	}
 	if c.TLS {
 		serverName := c.TLSServerName
 		if serverName == "" {
 			serverName = c.Host
 		}
+		if serverName == "" {
+			serverName, _, _ = net.SplitHostPort(opts.Addrs[0])
+		}
 		opts.TLSConfig = &tls.Config{
 			MinVersion: tls.VersionTLS12,
 			ServerName: serverName,
@@ -74,3 +283,23 @@ func (c Config) toRedisOptions() *goredis.Options {
 	}
 	return opts
 }
+
+// newUniversalClient builds the go-redis client for c.Mode. The mode is explicit
+// rather than inferred from the number of addresses as goredis.NewUniversalClient does.
+func (c Config) newUniversalClient() (goredis.UniversalClient, error) {
+	if err := c.validateTopology(); err != nil {
+		return nil, err
+	}
+	opts := c.toUniversalOptions()
+	switch c.Mode {
+	case ModeCluster:
+		return goredis.NewClusterClient(opts.Cluster()), nil
+	case ModeSentinel:
+		if opts.RouteByLatency || opts.RouteRandomly {
+			return goredis.NewFailoverClusterClient(opts.Failover()), nil
+		}
+		return goredis.NewFailoverClient(opts.Failover()), nil
+	default:
+		return goredis.NewClient(opts.Simple()), nil
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/juanMaAV92/go-utils/logger"
 	"github.com/redis/go-redis/extra/redisotel/v9"
 	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
 )
 
 const (
@@ -16,42 +17,73 @@ const (
 	defaultTTL        = 7 * 24 * time.Hour
 )
 
-// New creates a Cache backed by a Redis client with OTel tracing and metrics.
+// New creates a Cache backed by a Redis client with OTel tracing and metrics,
+// plus hit/miss metrics per key namespace (see Config.KeyNamespace).
 // Call once at service startup and inject the returned Cache where needed.
 func New(ctx context.Context, cfg Config, log logger.Logger) (Cache, error) {
-	client := goredis.NewClient(cfg.toRedisOptions())
+	enc, err := cfg.newEncoder()
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := newCacheMetrics(otel.GetMeterProvider(), cfg.KeyNamespace)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("redis: failed to create OTel metrics: %w", err)
+	}
+	return &cache{
+		keyPrefix: cfg.KeyPrefix,
+		instance:  client,
+		cluster:   cfg.Mode == ModeCluster,
+		logger:    log,
+		encoder:   enc,
+		metrics:   metrics,
+	}, nil
+}
+
+// NewClient creates a go-redis client for cfg.Mode (a *goredis.Client, *goredis.ClusterClient
+// or sentinel-backed client) with OTel tracing and metrics, retrying the initial ping.
+// Use it for packages that need Redis features outside Cache
+// (e.g. messaging/redisstream); KeyPrefix is not applied to this client.
+func NewClient(ctx context.Context, cfg Config) (goredis.UniversalClient, error) {
+	client, err := cfg.newUniversalClient()
+	if err != nil {
+		return nil, err
+	}
 
-	var err error
 	for i := 0; i <= connectionRetries; i++ {
 		err = client.Ping(ctx).Err()
 		if err == nil {
 			break
 		}
 		if ctx.Err() != nil {
+			_ = client.Close()
 			return nil, fmt.Errorf("redis: context cancelled during connection: %w", ctx.Err())
 		}
 		if i < connectionRetries {
 			select {
 			case <-ctx.Done():
+				_ = client.Close()
 				return nil, fmt.Errorf("redis: context cancelled during retry: %w", ctx.Err())
 			case <-time.After(retryDelay):
 			}
 		}
 	}
 	if err != nil {
+		_ = client.Close()
 		return nil, fmt.Errorf("redis: failed to connect after %d attempts: %w", connectionRetries+1, err)
 	}
 
 	if err := redisotel.InstrumentTracing(client); err != nil {
+		_ = client.Close()
 		return nil, fmt.Errorf("redis: failed to enable OTel tracing: %w", err)
 	}
 	if err := redisotel.InstrumentMetrics(client); err != nil {
+		_ = client.Close()
 		return nil, fmt.Errorf("redis: failed to enable OTel metrics: %w", err)
 	}
-
-	return &cache{
-		keyPrefix: cfg.KeyPrefix,
-		instance:  client,
-		logger:    log,
-	}, nil
+	return client, nil
 }
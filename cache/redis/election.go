@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/logger"
+)
+
+// Election elects one leader among the replicas sharing ElectionConfig.Key. The
+// leader holds a Cache.Lock as a lease and renews it; followers retry every
+// RetryInterval and take over once the lease is released or expires.
+//
+//	election, err := redis.NewElection(cache, redis.ElectionConfig{
+//	    Key: "leader:scheduler-reconciler",
+//	    OnElected: func(ctx context.Context) {
+//	        reconciler.Run(ctx) // returns when ctx is cancelled
+//	    },
+//	    OnRevoked: func() { log.Info(ctx, "election", "no longer leader") },
+//	}, log)
+//	if err != nil {
+//	    return err
+//	}
+//	go election.Run(ctx)
+type Election struct {
+	cache  Cache
+	cfg    ElectionConfig
+	logger logger.Logger
+	leader atomic.Bool
+}
+
+// NewElection validates cfg and returns an Election; call Run to campaign. log may be nil.
+func NewElection(c Cache, cfg ElectionConfig, log logger.Logger) (*Election, error) {
+	if c == nil {
+		return nil, errors.New("redis: cache is required")
+	}
+	if cfg.Key == "" {
+		return nil, errors.New("redis: election key is required")
+	}
+	return &Election{cache: c, cfg: cfg.withDefaults(), logger: log}, nil
+}
+
+// IsLeader reports whether this replica currently holds the leadership lease.
+func (e *Election) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns for leadership until ctx is cancelled and returns ctx.Err().
+// A leader releases the lease on cancellation, so another replica takes over
+// without waiting for it to expire.
+func (e *Election) Run(ctx context.Context) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	for {
+		l, err := e.cache.Lock(ctx, e.cfg.Key, e.cfg.TTL)
+		switch {
+		case err == nil:
+			e.lead(ctx, l)
+		case errors.Is(err, ErrLockNotAcquired), ctx.Err() != nil:
+		default:
+			e.warn(ctx, "cache.election.campaign", "failed to campaign for leadership", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jitter(e.cfg.RetryInterval)):
+		}
+	}
+}
+
+// lead runs OnElected and renews l until leadership ends, then calls OnRevoked.
+func (e *Election) lead(ctx context.Context, l Lock) {
+	e.leader.Store(true)
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if e.cfg.OnElected != nil {
+			e.cfg.OnElected(leaderCtx)
+		}
+	}()
+
+	e.renew(ctx, l)
+	e.leader.Store(false)
+	cancel()
+	<-done
+	if e.cfg.OnRevoked != nil {
+		e.cfg.OnRevoked()
+	}
+}
+
+// renew refreshes the lease every TTL/3 until ctx is cancelled or the lease is lost.
+// When Redis is unreachable it steps down while the lease may still be valid, so a
+// new leader is never elected while this one still believes it leads.
+func (e *Election) renew(ctx context.Context, l Lock) {
+	interval := max(e.cfg.TTL/3, time.Millisecond)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = l.Unlock(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			start := time.Now()
+			err := l.Refresh(ctx, e.cfg.TTL)
+			switch {
+			case err == nil:
+				renewed = start
+			case errors.Is(err, ErrLockNotHeld):
+				e.warn(ctx, "cache.election.lost", "leadership lease lost", err)
+				return
+			case time.Since(renewed) >= e.cfg.TTL-interval:
+				e.warn(ctx, "cache.election.renew", "stepping down: leadership lease could not be renewed", err)
+				_ = l.Unlock(context.WithoutCancel(ctx))
+				return
+			}
+		}
+	}
+}
+
+func (e *Election) warn(ctx context.Context, step, msg string, err error) {
+	if e.logger != nil {
+		e.logger.Warning(ctx, step, msg, "key", e.cfg.Key, "error", err.Error())
+	}
+}
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testElection runs an election on c and returns counters of its callbacks.
+func testElection(t *testing.T, c Cache, runCtx context.Context) (e *Election, elected, revoked *atomic.Int32) {
+	t.Helper()
+	elected, revoked = new(atomic.Int32), new(atomic.Int32)
+	e, err := NewElection(c, ElectionConfig{
+		Key:           "leader:reconciler",
+		TTL:           300 * time.Millisecond,
+		RetryInterval: 20 * time.Millisecond,
+		OnElected: func(ctx context.Context) {
+			elected.Add(1)
+			<-ctx.Done()
+		},
+		OnRevoked: func() { revoked.Add(1) },
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewElection: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = e.Run(runCtx)
+	}()
+	t.Cleanup(func() { <-done })
+	return e, elected, revoked
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestElection_SingleLeaderAndHandover(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctxA, cancelA := context.WithCancel(ctx)
+	ctxB, cancelB := context.WithCancel(ctx)
+	defer cancelB()
+
+	a, electedA, revokedA := testElection(t, c, ctxA)
+	waitFor(t, "a to lead", a.IsLeader)
+	b, electedB, _ := testElection(t, c, ctxB)
+
+	time.Sleep(100 * time.Millisecond) // b campaigns several times and loses
+	if b.IsLeader() || electedB.Load() != 0 {
+		t.Fatal("two leaders at once")
+	}
+
+	cancelA()
+	waitFor(t, "b to take over", b.IsLeader)
+	if electedA.Load() != 1 || revokedA.Load() != 1 || a.IsLeader() {
+		t.Errorf("a: elected=%d revoked=%d leader=%v", electedA.Load(), revokedA.Load(), a.IsLeader())
+	}
+	if electedB.Load() != 1 {
+		t.Errorf("b elected %d times, want 1", electedB.Load())
+	}
+}
+
+func TestElection_LeaseLost(t *testing.T) {
+	c, mr := newTestCache(t)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	e, elected, revoked := testElection(t, c, runCtx)
+	waitFor(t, "leader", e.IsLeader)
+
+	mr.Set("test:leader:reconciler", "someone-else")
+	waitFor(t, "revocation", func() bool { return revoked.Load() == 1 })
+	if e.IsLeader() {
+		t.Error("still leader after the lease was taken")
+	}
+
+	mr.Del("test:leader:reconciler")
+	waitFor(t, "re-election", func() bool { return elected.Load() == 2 })
+}
+
+func TestNewElection_Validation(t *testing.T) {
+	c, _ := newTestCache(t)
+	if _, err := NewElection(nil, ElectionConfig{Key: "k"}, nil); err == nil {
+		t.Error("expected error for nil cache")
+	}
+	if _, err := NewElection(c, ElectionConfig{}, nil); err == nil {
+		t.Error("expected error for missing key")
+	}
+}
@@ -0,0 +1,139 @@
+package redis
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Keyring holds the AES-256 keys used to encrypt values at rest (Config.Encryption).
+// New values are sealed with Primary; values sealed with any key in Keys stay
+// readable, so a key is rotated by adding the new key, switching Primary to it,
+// and removing the old key once every value written with it has expired.
+type Keyring struct {
+	Primary string            // key ID used for new values; must be present in Keys
+	Keys    map[string][]byte // key ID (1–255 bytes) → 32-byte AES-256 key
+}
+
+// KeyringFromString parses "id:base64key,id:base64key,…" as read from
+// {prefix}_ENCRYPTION_KEYS. The first entry is the primary key.
+func KeyringFromString(s string) (*Keyring, error) {
+	k := &Keyring{Keys: make(map[string][]byte)}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, errors.New(`redis: encryption keys must be "id:base64key" pairs`)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("redis: encryption key %q is not valid base64: %w", id, err)
+		}
+		if _, dup := k.Keys[id]; dup {
+			return nil, fmt.Errorf("redis: duplicate encryption key ID %q", id)
+		}
+		if k.Primary == "" {
+			k.Primary = id
+		}
+		k.Keys[id] = key
+	}
+	if len(k.Keys) == 0 {
+		return nil, errors.New("redis: no encryption keys")
+	}
+	return k, nil
+}
+
+// maxKeyIDLen is the longest key ID the one-byte length in the value header can carry.
+const maxKeyIDLen = 255
+
+// keyring is a validated Keyring with one AEAD per key ID.
+type keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+func newKeyring(k Keyring) (*keyring, error) {
+	if len(k.Keys) == 0 {
+		return nil, errors.New("redis: encryption keyring has no keys")
+	}
+	if _, ok := k.Keys[k.Primary]; !ok {
+		return nil, fmt.Errorf("redis: primary encryption key %q is not in the keyring", k.Primary)
+	}
+	kr := &keyring{primary: k.Primary, aeads: make(map[string]cipher.AEAD, len(k.Keys))}
+	for id, key := range k.Keys {
+		if id == "" || len(id) > maxKeyIDLen {
+			return nil, fmt.Errorf("redis: encryption key ID %q must be 1-%d bytes", id, maxKeyIDLen)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("redis: encryption key %q must be 32 bytes for AES-256, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("redis: encryption key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("redis: encryption key %q: %w", id, err)
+		}
+		kr.aeads[id] = aead
+	}
+	return kr, nil
+}
+
+// seal encrypts an encoded value with the primary key. The result is
+//
+//	0xFF | codecEncryptedID | len(keyID) | keyID | nonce | AES-GCM ciphertext + tag
+//
+// where the plaintext is the value as it would be stored unencrypted (header,
+// codec and compression included) and everything before the nonce is
+// authenticated as additional data.
+func (k *keyring) seal(plain string) (string, error) {
+	aead := k.aeads[k.primary]
+	prefixLen := headerLen + 1 + len(k.primary)
+	out := make([]byte, prefixLen+aead.NonceSize(), prefixLen+aead.NonceSize()+len(plain)+aead.Overhead())
+	out[0], out[1], out[2] = headerMarker, codecEncryptedID, byte(len(k.primary))
+	copy(out[headerLen+1:], k.primary)
+	nonce := out[prefixLen:]
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("redis: failed to encrypt value: %w", err)
+	}
+	return string(aead.Seal(out, nonce, []byte(plain), out[:prefixLen])), nil
+}
+
+// open decrypts a value written by seal with any key in the ring.
+func (k *keyring) open(val string) (string, error) {
+	if len(val) < headerLen+1 {
+		return "", errors.New("redis: failed to decrypt value: truncated header")
+	}
+	prefixLen := headerLen + 1 + int(val[headerLen])
+	if len(val) < prefixLen {
+		return "", errors.New("redis: failed to decrypt value: truncated header")
+	}
+	id := val[headerLen+1 : prefixLen]
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("redis: failed to decrypt value: unknown encryption key %q", id)
+	}
+	if len(val) < prefixLen+aead.NonceSize()+aead.Overhead() {
+		return "", errors.New("redis: failed to decrypt value: truncated ciphertext")
+	}
+	data := []byte(val)
+	nonce := data[prefixLen : prefixLen+aead.NonceSize()]
+	plain, err := aead.Open(nil, nonce, data[prefixLen+aead.NonceSize():], data[:prefixLen])
+	if err != nil {
+		return "", fmt.Errorf("redis: failed to decrypt value with key %q: %w", id, err)
+	}
+	return string(plain), nil
+}
+
+// isEncrypted reports whether val was written by keyring.seal.
+func isEncrypted(val string) bool {
+	return len(val) >= headerLen && val[0] == headerMarker && val[1] == codecEncryptedID
+}
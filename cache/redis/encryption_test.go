@@ -0,0 +1,340 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var (
+	testKeyV1 = bytes.Repeat([]byte{1}, 32)
+	testKeyV2 = bytes.Repeat([]byte{2}, 32)
+)
+
+// newEncryptedCache returns a cache on mr that encrypts with keyring (nil = plaintext).
+func newEncryptedCache(t *testing.T, mr *miniredis.Miniredis, keyring *Keyring) Cache {
+	t.Helper()
+	enc, err := Config{Encryption: keyring}.newEncoder()
+	if err != nil {
+		t.Fatalf("newEncoder: %v", err)
+	}
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return &cache{instance: client, keyPrefix: "test", encoder: enc}
+}
+
+func TestEncryption_RoundTrip(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+
+	in := codecUser{ID: 7, Name: "alice@example.com"}
+	if err := c.Set(ctx, "user:7", in); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Set(ctx, "email:7", "alice@example.com"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	for _, key := range []string{"test:user:7", "test:email:7"} {
+		raw, _ := mr.Get(key)
+		if !strings.HasPrefix(raw, "\xff\x0e\x02v1") || strings.Contains(raw, "alice") {
+			t.Errorf("%s stored as %q, want ciphertext with key ID v1", key, raw)
+		}
+	}
+
+	var out codecUser
+	if err := c.Get(ctx, "user:7", &out); err != nil || out.Name != in.Name {
+		t.Errorf("Get = %+v, %v", out, err)
+	}
+	var email string
+	if err := c.Get(ctx, "email:7", &email); err != nil || email != "alice@example.com" {
+		t.Errorf("Get string = %q, %v", email, err)
+	}
+
+	got := map[string]codecUser{}
+	found, err := c.GetMany(ctx, []string{"user:7"}, &got)
+	if err != nil || !found[0] || got["user:7"].Name != in.Name {
+		t.Errorf("GetMany = %v, %+v, %v", found, got, err)
+	}
+
+	out = codecUser{}
+	err = c.GetOrSet(ctx, "user:8", &out, func() (any, error) { return codecUser{ID: 8, Name: "bob"}, nil })
+	if err != nil || out.Name != "bob" {
+		t.Errorf("GetOrSet = %+v, %v", out, err)
+	}
+	if raw, _ := mr.Get("test:user:8"); strings.Contains(raw, "bob") {
+		t.Errorf("GetOrSet stored plaintext %q", raw)
+	}
+}
+
+func TestEncryption_SameValueDifferentCiphertext(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+	_ = c.Set(ctx, "a", "secret")
+	_ = c.Set(ctx, "b", "secret")
+	a, _ := mr.Get("test:a")
+	b, _ := mr.Get("test:b")
+	if a == b {
+		t.Error("expected a fresh nonce per value")
+	}
+}
+
+func TestEncryption_NumbersStayPlain(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+	if err := c.Set(ctx, "counter", 41); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if n, err := c.Increment(ctx, "counter", 1); err != nil || n != 42 {
+		t.Errorf("Increment = %d, %v", n, err)
+	}
+}
+
+func TestEncryption_KeyRotation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	old := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+	if err := old.Set(ctx, "k", "written with v1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	rotated := newEncryptedCache(t, mr, &Keyring{Primary: "v2", Keys: map[string][]byte{"v1": testKeyV1, "v2": testKeyV2}})
+	var got string
+	if err := rotated.Get(ctx, "k", &got); err != nil || got != "written with v1" {
+		t.Errorf("Get old value after rotation = %q, %v", got, err)
+	}
+	_ = rotated.Set(ctx, "k2", "written with v2")
+	if raw, _ := mr.Get("test:k2"); !strings.HasPrefix(raw, "\xff\x0e\x02v2") {
+		t.Errorf("new value not sealed with primary v2: %q", raw)
+	}
+
+	retired := newEncryptedCache(t, mr, &Keyring{Primary: "v2", Keys: map[string][]byte{"v2": testKeyV2}})
+	if err := retired.Get(ctx, "k", &got); err == nil || !strings.Contains(err.Error(), `unknown encryption key "v1"`) {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestEncryption_Errors(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+	_ = c.Set(ctx, "k", "secret")
+
+	var got string
+	if err := newEncryptedCache(t, mr, nil).Get(ctx, "k", &got); err == nil || errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("plaintext cache reading ciphertext: expected error, got %v", err)
+	}
+
+	raw, _ := mr.Get("test:k")
+	tampered := []byte(raw)
+	tampered[len(tampered)-1] ^= 1
+	_ = mr.Set("test:k", string(tampered))
+	if err := c.Get(ctx, "k", &got); err == nil {
+		t.Error("expected authentication error for tampered ciphertext")
+	}
+
+	_ = mr.Set("test:short", "\xff\x0e\x02v1")
+	if err := c.Get(ctx, "short", &got); err == nil {
+		t.Error("expected error for truncated ciphertext")
+	}
+}
+
+func TestNewKeyring_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		k    Keyring
+	}{
+		{"no keys", Keyring{Primary: "v1"}},
+		{"missing primary", Keyring{Primary: "v2", Keys: map[string][]byte{"v1": testKeyV1}}},
+		{"short key", Keyring{Primary: "v1", Keys: map[string][]byte{"v1": make([]byte, 16)}}},
+		{"long id", Keyring{Primary: strings.Repeat("x", 256), Keys: map[string][]byte{strings.Repeat("x", 256): testKeyV1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newKeyring(tt.k); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv_EncryptionKeys(t *testing.T) {
+	t.Setenv("ENC_REDIS_HOST", "localhost")
+	t.Setenv("ENC_REDIS_PASSWORD", "secret")
+	t.Setenv("ENC_REDIS_TLS_SERVER_NAME", "localhost")
+	t.Setenv("ENC_REDIS_KEY_PREFIX", "svc")
+	t.Setenv("ENC_REDIS_ENCRYPTION_KEYS",
+		"v2:"+base64.StdEncoding.EncodeToString(testKeyV2)+", v1:"+base64.StdEncoding.EncodeToString(testKeyV1))
+	cfg, err := ConfigFromEnv("ENC_REDIS")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if cfg.Encryption == nil || cfg.Encryption.Primary != "v2" || len(cfg.Encryption.Keys) != 2 {
+		t.Fatalf("Encryption = %+v", cfg.Encryption)
+	}
+	if _, err := cfg.newEncoder(); err != nil {
+		t.Errorf("newEncoder: %v", err)
+	}
+
+	for _, bad := range []string{"v1", "v1:not-base64!", "v1:AA==,v1:AA=="} {
+		t.Setenv("ENC_REDIS_ENCRYPTION_KEYS", bad)
+		if _, err := ConfigFromEnv("ENC_REDIS"); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestEncryption_NearCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	remote := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+	near, err := NewNearCache(ctx, remote, NearCacheConfig{LocalTTL: time.Minute}, nil)
+	if err != nil {
+		t.Fatalf("NewNearCache: %v", err)
+	}
+	t.Cleanup(func() { _ = near.Close() })
+
+	_ = near.Set(ctx, "user:1", codecUser{Name: "alice"})
+	for range 2 { // Redis, then the local store
+		var out codecUser
+		if err := near.Get(ctx, "user:1", &out); err != nil || out.Name != "alice" {
+			t.Errorf("near Get = %+v, %v", out, err)
+		}
+	}
+}
+
+func TestEncryption_HashValues(t *testing.T) {
+	type account struct {
+		Email  string `redis:"email"`
+		Visits int    `redis:"visits"`
+		Active bool   `redis:"active"`
+	}
+	mr := miniredis.RunT(t)
+	c := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+
+	if err := c.HSet(ctx, "account:1", account{Email: "alice@example.com", Visits: 3, Active: true}); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	if raw := mr.HGet("test:account:1", "email"); !strings.HasPrefix(raw, "\xff\x0e\x02v1") || strings.Contains(raw, "alice") {
+		t.Errorf("hash field stored as %q, want ciphertext", raw)
+	}
+	if raw := mr.HGet("test:account:1", "visits"); raw != "3" {
+		t.Errorf("numeric field stored as %q, want plaintext", raw)
+	}
+	if n, err := c.HIncrBy(ctx, "account:1", "visits", 1); err != nil || n != 4 {
+		t.Errorf("HIncrBy = %d, %v", n, err)
+	}
+
+	var email string
+	if err := c.HGet(ctx, "account:1", "email", &email); err != nil || email != "alice@example.com" {
+		t.Errorf("HGet = %q, %v", email, err)
+	}
+	var out account
+	if err := c.HGetAll(ctx, "account:1", &out); err != nil || out != (account{Email: "alice@example.com", Visits: 4, Active: true}) {
+		t.Errorf("HGetAll = %+v, %v", out, err)
+	}
+	var m map[string]string
+	if err := c.HGetAll(ctx, "account:1", &m); err != nil || m["email"] != "alice@example.com" {
+		t.Errorf("HGetAll map = %v, %v", m, err)
+	}
+}
+
+func TestEncryption_RejectsMembers(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+
+	if err := c.AddToSet(ctx, "s", []string{"a"}); !errors.Is(err, ErrEncryptionUnsupported) {
+		t.Errorf("AddToSet err = %v", err)
+	}
+	if err := c.ZAdd(ctx, "z", []ZMember{{Member: "a", Score: 1}}); !errors.Is(err, ErrEncryptionUnsupported) {
+		t.Errorf("ZAdd err = %v", err)
+	}
+	if _, err := c.ZIncrBy(ctx, "z", "a", 1); !errors.Is(err, ErrEncryptionUnsupported) {
+		t.Errorf("ZIncrBy err = %v", err)
+	}
+	if mr.Exists("test:s") || mr.Exists("test:z") {
+		t.Error("rejected writes must not reach Redis")
+	}
+}
+
+func TestEncryption_SealsMessages(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+	sub := c.Subscribe(ctx, "events")
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	if err := c.Publish(ctx, "events", codecUser{ID: 1, Name: "secret"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	msg, err := sub.ReceiveMessage(ctx)
+	if err != nil {
+		t.Fatalf("ReceiveMessage: %v", err)
+	}
+	if strings.Contains(msg.Payload, "secret") {
+		t.Errorf("payload is plaintext: %q", msg.Payload)
+	}
+	payload, err := OpenMessage(c, msg.Payload)
+	if err != nil || !strings.Contains(payload, `"name":"secret"`) {
+		t.Errorf("OpenMessage = %q, %v", payload, err)
+	}
+	if _, err := OpenMessage(newEncryptedCache(t, mr, nil), msg.Payload); err == nil {
+		t.Error("OpenMessage without a keyring must fail on sealed payloads")
+	}
+}
+
+func TestEncryption_SubscribeHandler(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newEncryptedCache(t, mr, &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}})
+	got := make(chan codecUser, 2)
+	startHandler(t, c, "users", SubscribeConfig{Workers: 1}, func(_ context.Context, _ string, u codecUser) error {
+		got <- u
+		return nil
+	})
+
+	_ = PublishTraced(ctx, c, "users", codecUser{ID: 1, Name: "traced"})
+	_ = c.Publish(ctx, "users", codecUser{ID: 2, Name: "plain"})
+	for _, want := range []int{1, 2} {
+		select {
+		case u := <-got:
+			if u.ID != want {
+				t.Errorf("got %+v, want id %d", u, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("message %d not handled", want)
+		}
+	}
+}
+
+func TestEncryption_NearCacheInvalidation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	keyring := &Keyring{Primary: "v1", Keys: map[string][]byte{"v1": testKeyV1}}
+	newReplica := func() NearCache {
+		n, err := NewNearCache(ctx, newEncryptedCache(t, mr, keyring), NearCacheConfig{}, nil)
+		if err != nil {
+			t.Fatalf("NewNearCache: %v", err)
+		}
+		t.Cleanup(func() { _ = n.Close() })
+		return n
+	}
+	a, b := newReplica(), newReplica()
+
+	_ = a.Set(ctx, "k", "v1")
+	var got string
+	eventually(t, func() bool {
+		_ = b.Get(ctx, "k", &got)
+		return b.Stats().Entries == 1
+	})
+
+	_ = a.Set(ctx, "k", "v2")
+	eventually(t, func() bool {
+		_ = b.Get(ctx, "k", &got)
+		return got == "v2"
+	})
+}
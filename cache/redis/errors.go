@@ -9,4 +9,14 @@ var (
 
 	// ErrKeyNotSet is returned by Set when a conditional write (NX/XX) did not apply.
 	ErrKeyNotSet = errors.New("key not set: condition not met")
+
+	// ErrLockNotAcquired is returned by Lock when the lock is held by another owner.
+	ErrLockNotAcquired = errors.New("lock not acquired")
+
+	// ErrLockNotHeld is returned by Unlock and Refresh when the lock expired or belongs to another owner.
+	ErrLockNotHeld = errors.New("lock not held")
+
+	// ErrEncryptionUnsupported is returned by set and sorted-set writes when
+	// Config.Encryption is set: members must stay comparable, so they cannot be sealed.
+	ErrEncryptionUnsupported = errors.New("redis: operation not supported with encryption")
 )
@@ -0,0 +1,267 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// structFieldsCache memoizes the redis-tagged fields of each struct type.
+var structFieldsCache sync.Map // reflect.Type → []hashField
+
+type hashField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// HSet writes fields to the hash stored at key. values is either a struct whose
+// fields carry `redis:"field"` tags (untagged fields are skipped) or a map with string keys.
+// Field values follow the same rules as Set: primitives as-is, anything else JSON.
+// If opts include WithTTL, HSET and EXPIRE are applied atomically via a Lua script.
+//
+//	type Profile struct {
+//	    Name  string `redis:"name"`
+//	    Email string `redis:"email,omitempty"`
+//	    Score int    `redis:"score"`
+//	}
+//	cache.HSet(ctx, "profile:123", Profile{Name: "alice", Score: 10}, redis.WithTTL(time.Hour))
+//	cache.HSet(ctx, "profile:123", map[string]any{"score": 11}) // partial update
+func (c *cache) HSet(ctx context.Context, key string, values any, opts ...SetOption) error {
+	if err := validateKV(ctx, key, values); err != nil {
+		return err
+	}
+	pairs, err := c.encoder.hashPairs(values)
+	if err != nil {
+		return err
+	}
+	if len(pairs) == 0 {
+		return errors.New("redis: at least one field is required")
+	}
+
+	o := &setOptions{TTL: 0}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.TTL <= 0 {
+		if err := c.instance.HSet(ctx, c.key(key), pairs...).Err(); err != nil {
+			return c.logErr(ctx, "cache.hash_set", err)
+		}
+		return nil
+	}
+
+	// Atomic HSET + EXPIRE via Lua
+	const luaHSetWithTTL = `
+		redis.call('HSET', KEYS[1], unpack(ARGV, 2))
+		redis.call('EXPIRE', KEYS[1], ARGV[1])
+		return 1
+	`
+	args := make([]any, 0, len(pairs)+1)
+	args = append(args, int64(o.TTL.Seconds()))
+	args = append(args, pairs...)
+	if err := c.instance.Eval(ctx, luaHSetWithTTL, []string{c.key(key)}, args...).Err(); err != nil {
+		return c.logErr(ctx, "cache.hash_set", err)
+	}
+	return nil
+}
+
+// HGet reads a single hash field into dest (must be a pointer).
+// Returns ErrKeyNotFound when the key or the field does not exist.
+func (c *cache) HGet(ctx context.Context, key, field string, dest any) error {
+	if err := validateDest(ctx, key, dest); err != nil {
+		return err
+	}
+	if field == "" {
+		return errors.New("redis: field is required")
+	}
+	val, err := c.instance.HGet(ctx, c.key(key), field).Result()
+	if err != nil {
+		if err == goredis.Nil {
+			return ErrKeyNotFound
+		}
+		return c.logErr(ctx, "cache.hash_get", err)
+	}
+	return c.encoder.decodeField(val, dest)
+}
+
+// HGetAll reads the whole hash into dest: a pointer to a struct with `redis` tags
+// (fields absent from the hash keep their current value) or a *map[string]string.
+// Returns ErrKeyNotFound when the key does not exist.
+func (c *cache) HGetAll(ctx context.Context, key string, dest any) error {
+	if err := validateDest(ctx, key, dest); err != nil {
+		return err
+	}
+	vals, err := c.instance.HGetAll(ctx, c.key(key)).Result()
+	if err != nil {
+		return c.logErr(ctx, "cache.hash_get_all", err)
+	}
+	if len(vals) == 0 {
+		return ErrKeyNotFound
+	}
+	return c.encoder.scanHash(vals, dest)
+}
+
+// scanHash copies hash fields into a *map[string]string or a `redis`-tagged struct pointer.
+// Encrypted values are decrypted before they are copied into a map.
+func (e encoder) scanHash(vals map[string]string, dest any) error {
+	if m, ok := dest.(*map[string]string); ok {
+		for field, val := range vals {
+			if !isEncrypted(val) {
+				continue
+			}
+			if e.keyring == nil {
+				return fmt.Errorf("redis: field %q: value is encrypted and Config.Encryption is not set", field)
+			}
+			plain, err := e.keyring.open(val)
+			if err != nil {
+				return fmt.Errorf("redis: field %q: %w", field, err)
+			}
+			vals[field] = plain
+		}
+		*m = vals
+		return nil
+	}
+	rv := reflect.ValueOf(dest).Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("redis: HGetAll dest must be a pointer to a struct or *map[string]string")
+	}
+	for _, f := range structFields(rv.Type()) {
+		val, ok := vals[f.name]
+		if !ok {
+			continue
+		}
+		if err := e.decodeField(val, rv.FieldByIndex(f.index).Addr().Interface()); err != nil {
+			return fmt.Errorf("redis: field %q: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// HDel removes fields from the hash. Redis deletes the key once the hash is empty.
+func (c *cache) HDel(ctx context.Context, key string, fields ...string) error {
+	if err := validateKey(ctx, key); err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return errors.New("redis: at least one field is required")
+	}
+	if err := c.instance.HDel(ctx, c.key(key), fields...).Err(); err != nil {
+		return c.logErr(ctx, "cache.hash_delete", err)
+	}
+	return nil
+}
+
+// HIncrBy atomically adds delta to the integer stored in field (HINCRBY).
+// A missing key or field is initialized to 0 before the operation.
+func (c *cache) HIncrBy(ctx context.Context, key, field string, delta int64) (int64, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	if field == "" {
+		return 0, errors.New("redis: field is required")
+	}
+	result, err := c.instance.HIncrBy(ctx, c.key(key), field, delta).Result()
+	if err != nil {
+		return 0, c.logErr(ctx, "cache.hash_increment", err)
+	}
+	return result, nil
+}
+
+// --- struct ↔ hash mapping ---
+
+// hashPairs flattens a tagged struct or a string-keyed map into HSET field/value pairs.
+func (e encoder) hashPairs(values any) ([]any, error) {
+	rv := reflect.ValueOf(values)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("redis: value is required")
+		}
+		rv = rv.Elem()
+	}
+
+	var pairs []any
+	switch rv.Kind() {
+	case reflect.Struct:
+		for _, f := range structFields(rv.Type()) {
+			fv := rv.FieldByIndex(f.index)
+			if f.omitEmpty && fv.IsZero() {
+				continue
+			}
+			enc, err := e.encodeField(fv.Interface())
+			if err != nil {
+				return nil, fmt.Errorf("redis: field %q: %w", f.name, err)
+			}
+			pairs = append(pairs, f.name, enc)
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, errors.New("redis: hash map must have string keys")
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			enc, err := e.encodeField(iter.Value().Interface())
+			if err != nil {
+				return nil, fmt.Errorf("redis: field %q: %w", iter.Key().String(), err)
+			}
+			pairs = append(pairs, iter.Key().String(), enc)
+		}
+	default:
+		return nil, errors.New("redis: hash values must be a struct or a map with string keys")
+	}
+	return pairs, nil
+}
+
+// structFields returns the fields of t tagged `redis:"name[,omitempty]"`.
+func structFields(t reflect.Type) []hashField {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.([]hashField)
+	}
+	var fields []hashField
+	for _, sf := range reflect.VisibleFields(t) {
+		tag, ok := sf.Tag.Lookup("redis")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, hashField{name: name, index: sf.Index, omitEmpty: opts == "omitempty"})
+	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// encodeField serializes a field value like Set does. Booleans are written as
+// "true"/"false" so they round-trip through the JSON decoder. With encryption,
+// the value goes through encode and is sealed unless it is a number.
+func (e encoder) encodeField(v any) (any, error) {
+	if b, ok := v.(bool); ok {
+		return strconv.FormatBool(b), nil
+	}
+	if e.keyring != nil {
+		return e.encode(v, nil)
+	}
+	return serialize(v)
+}
+
+// decodeField deserializes a field value like Get does, additionally accepting
+// "1"/"0" booleans written by other Redis clients.
+func (e encoder) decodeField(val string, dest any) error {
+	if b, ok := dest.(*bool); ok {
+		parsed, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("redis: failed to deserialize value: %w", err)
+		}
+		*b = parsed
+		return nil
+	}
+	return e.decode(val, dest)
+}
@@ -0,0 +1,140 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testProfile struct {
+	Name    string    `redis:"name"`
+	Email   string    `redis:"email,omitempty"`
+	Score   int       `redis:"score"`
+	Active  bool      `redis:"active"`
+	Tags    []string  `redis:"tags"`
+	Updated time.Time `redis:"updated"`
+	Ignored string
+}
+
+func TestHSet_HGetAll_Struct(t *testing.T) {
+	c, mr := newTestCache(t)
+	in := testProfile{
+		Name:    "alice",
+		Score:   10,
+		Active:  true,
+		Tags:    []string{"a", "b"},
+		Updated: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Ignored: "skip",
+	}
+	if err := c.HSet(ctx, "profile:1", in); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+
+	fields, _ := mr.HKeys("test:profile:1")
+	if len(fields) != 5 {
+		t.Errorf("stored fields = %v, want 5 (omitempty and untagged skipped)", fields)
+	}
+	if got := mr.HGet("test:profile:1", "name"); got != "alice" {
+		t.Errorf("raw name = %q, want \"alice\"", got)
+	}
+
+	var out testProfile
+	if err := c.HGetAll(ctx, "profile:1", &out); err != nil {
+		t.Fatalf("HGetAll: %v", err)
+	}
+	in.Ignored = ""
+	if out.Name != in.Name || out.Score != in.Score || !out.Active ||
+		len(out.Tags) != 2 || !out.Updated.Equal(in.Updated) || out.Ignored != "" {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestHSet_Map_HGet(t *testing.T) {
+	c, _ := newTestCache(t)
+	if err := c.HSet(ctx, "h", map[string]any{"count": 3, "label": "x"}); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	var count int
+	if err := c.HGet(ctx, "h", "count", &count); err != nil || count != 3 {
+		t.Errorf("HGet count = %d, %v; want 3", count, err)
+	}
+	var label string
+	if err := c.HGet(ctx, "h", "label", &label); err != nil || label != "x" {
+		t.Errorf("HGet label = %q, %v; want \"x\"", label, err)
+	}
+	var all map[string]string
+	if err := c.HGetAll(ctx, "h", &all); err != nil || len(all) != 2 {
+		t.Errorf("HGetAll map = %v, %v", all, err)
+	}
+}
+
+func TestHGet_ErrKeyNotFound(t *testing.T) {
+	c, _ := newTestCache(t)
+	var s string
+	if err := c.HGet(ctx, "missing", "f", &s); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("missing key: expected ErrKeyNotFound, got %v", err)
+	}
+	_ = c.HSet(ctx, "h", map[string]string{"a": "1"})
+	if err := c.HGet(ctx, "h", "b", &s); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("missing field: expected ErrKeyNotFound, got %v", err)
+	}
+	var p testProfile
+	if err := c.HGetAll(ctx, "missing", &p); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("HGetAll: expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestHGet_BoolFromOtherClients(t *testing.T) {
+	c, mr := newTestCache(t)
+	mr.HSet("test:flags", "on", "1")
+	var on bool
+	if err := c.HGet(ctx, "flags", "on", &on); err != nil || !on {
+		t.Errorf("HGet = %v, %v; want true", on, err)
+	}
+}
+
+func TestHSet_WithTTL(t *testing.T) {
+	c, mr := newTestCache(t)
+	if err := c.HSet(ctx, "h", map[string]int{"a": 1}, WithTTL(time.Minute)); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	if ttl := mr.TTL("test:h"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want 1m", ttl)
+	}
+	if err := c.HSet(ctx, "plain", map[string]int{"a": 1}); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	if ttl := mr.TTL("test:plain"); ttl != 0 {
+		t.Errorf("TTL without option = %v, want none", ttl)
+	}
+}
+
+func TestHSet_InvalidValues(t *testing.T) {
+	c, _ := newTestCache(t)
+	if err := c.HSet(ctx, "h", 42); err == nil {
+		t.Error("expected error for non-struct, non-map value")
+	}
+	if err := c.HSet(ctx, "h", map[int]string{1: "a"}); err == nil {
+		t.Error("expected error for non-string map keys")
+	}
+	if err := c.HSet(ctx, "h", map[string]string{}); err == nil {
+		t.Error("expected error for empty map")
+	}
+}
+
+func TestHDel_HIncrBy(t *testing.T) {
+	c, mr := newTestCache(t)
+	n, err := c.HIncrBy(ctx, "stats", "views", 5)
+	if err != nil || n != 5 {
+		t.Fatalf("HIncrBy = %d, %v; want 5", n, err)
+	}
+	if n, _ = c.HIncrBy(ctx, "stats", "views", -2); n != 3 {
+		t.Errorf("HIncrBy = %d, want 3", n)
+	}
+	if err := c.HDel(ctx, "stats", "views"); err != nil {
+		t.Fatalf("HDel: %v", err)
+	}
+	if mr.Exists("test:stats") {
+		t.Error("expected empty hash to be removed")
+	}
+}
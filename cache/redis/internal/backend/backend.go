@@ -0,0 +1,80 @@
+// Package backend shares the redis package internals that the in-memory Cache in
+// redistest needs, without exporting them from redis. The option structs live
+// here; everything else is set by package redis when it is initialised.
+package backend
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Encoder encodes values exactly as the Redis cache stores them.
+type Encoder interface {
+	Encode(value any, codec Codec) (string, error)
+	Decode(val string, dest any) error
+	// SetBatchElem decodes val into the GetMany target slot for keys[i].
+	SetBatchElem(target reflect.Value, keys []string, i int, val string) error
+	// HashPairs flattens HSet values into field/value pairs.
+	HashPairs(values any) ([]any, error)
+	DecodeField(val string, dest any) error
+	ScanHash(vals map[string]string, dest any) error
+}
+
+// LockBackend stores lock keys for AcquireLock.
+type LockBackend interface {
+	// TryLock sets key to token with ttl unless key exists.
+	TryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// RefreshLock resets key's ttl if it still holds token.
+	RefreshLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// ReleaseLock deletes key if it still holds token.
+	ReleaseLock(ctx context.Context, key, token string) (bool, error)
+	LogErr(ctx context.Context, step string, err error) error
+}
+
+// SemaphoreStore stores semaphore permits for AcquireSemaphore.
+type SemaphoreStore interface {
+	// TryAcquire adds token to key with a lease of ttl if fewer than limit leases are live.
+	TryAcquire(ctx context.Context, key, token string, limit int, ttl time.Duration) (bool, error)
+	// RefreshPermit extends the lease of token if it has not expired.
+	RefreshPermit(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// ReleasePermit removes token, reporting whether its lease was still live.
+	ReleasePermit(ctx context.Context, key, token string) (bool, error)
+	LogErr(ctx context.Context, step string, err error) error
+}
+
+// Set by package redis.
+var (
+	DefaultTTL      time.Duration
+	AbsentMarker    string // value of a key cached as absent by WithNegativeTTL
+	StaleCopySuffix string // WithStaleOnError shadow copy
+	TagKeyPrefix    string // tag sets, before KeyPrefix is applied
+
+	// NewEncoder returns the encoder of a cache configured with codec (nil = JSON).
+	NewEncoder func(codec Codec) Encoder
+	// AcquireLock implements Cache.Lock on top of b; the result is a redis.Lock.
+	AcquireLock func(ctx context.Context, b LockBackend, key string, ttl time.Duration, opts []func(*LockOptions)) (any, error)
+	// AcquireSemaphore implements Cache.AcquireSemaphore on top of s; the result is a redis.Lock.
+	AcquireSemaphore func(ctx context.Context, s SemaphoreStore, key string, limit int, ttl time.Duration, opts []func(*LockOptions)) (any, error)
+
+	ValidateKey     func(ctx context.Context, key string) error
+	ValidateKV      func(ctx context.Context, key string, value any) error
+	ValidateDest    func(ctx context.Context, key string, dest any) error
+	ValidatePattern func(ctx context.Context, pattern string) error
+	ValidateTags    func(tags []string) error
+	// BatchTarget validates dest for GetMany and prepares it to receive values.
+	BatchTarget func(dest any, n int) (reflect.Value, error)
+
+	// MatchGlob reports whether s matches a Redis glob pattern.
+	MatchGlob func(pattern, s string) bool
+	// SidecarKeys returns the GetOrSet sidecar keys of each key.
+	SidecarKeys func(keys []string) []string
+	// WrittenTTL is the remaining TTL of a value GetOrSet just wrote.
+	WrittenTTL func(o *SetOptions) time.Duration
+	// StaleCopyTTL is the TTL of the WithStaleOnError shadow copy.
+	StaleCopyTTL func(o *SetOptions) time.Duration
+	NewToken     func() (string, error)
+	// Serialize and FormatArg render a Publish message as the Redis cache sends it.
+	Serialize func(value any) (any, error)
+	FormatArg func(v any) string
+)
@@ -0,0 +1,47 @@
+package backend
+
+import "time"
+
+// Codec mirrors redis.Codec so option structs can carry one.
+type Codec interface {
+	ID() byte
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// SetOptions collects redis.SetOption values.
+type SetOptions struct {
+	TTL        time.Duration
+	IfNotExist bool     // NX — only set if key does not exist
+	IfExist    bool     // XX — only set if key already exists
+	KeepTTL    bool     // KEEPTTL — retain current TTL (Redis 6.0+)
+	Codec      Codec    // overrides Config.Codec for this write
+	Tags       []string // WithTags — index the key for InvalidateTags
+
+	// GetOrSet only — stampede protection
+	Singleflight     bool          // dedupe concurrent in-process misses per key
+	RecomputeLockTTL time.Duration // > 0 — only the lock holder across replicas calls fn
+	RecomputeWait    time.Duration // how long non-holders wait for the value before computing themselves
+	EarlyRefreshBeta float64       // > 0 — XFetch probabilistic early refresh
+
+	// GetOrSet only — producer outcomes
+	NegativeTTL time.Duration // > 0 — cache ErrKeyNotFound from fn for this long
+	StaleWindow time.Duration // > 0 — serve the last value this long past expiry when fn fails
+
+	RemainingTTL *time.Duration // internal — see getOrSetRemainingTTL
+}
+
+// GetOptions collects redis.GetOption values.
+type GetOptions struct {
+	DeleteAfterGet bool           // GETDEL — atomic get-and-delete
+	RemainingTTL   *time.Duration // internal — see getRemainingTTL
+}
+
+// LockOptions collects redis.LockOption values.
+type LockOptions struct {
+	Wait        bool          // retry until acquired instead of failing fast
+	WaitTimeout time.Duration // max time to wait; 0 = until ctx is done
+	RetryMin    time.Duration // initial retry delay
+	RetryMax    time.Duration // retry delay cap
+	AutoRefresh bool          // renew the TTL in the background while held
+}
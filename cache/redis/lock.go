@@ -0,0 +1,240 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLockRetryMin = 50 * time.Millisecond
+	defaultLockRetryMax = time.Second
+	tokenBytes          = 16
+)
+
+// Compare-and-delete: only the owner token may release the lock.
+const luaUnlock = `
+	if redis.call('GET', KEYS[1]) == ARGV[1] then
+		return redis.call('DEL', KEYS[1])
+	end
+	return 0
+`
+
+// Compare-and-expire: only the owner token may extend the lock.
+const luaRefresh = `
+	if redis.call('GET', KEYS[1]) == ARGV[1] then
+		return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	end
+	return 0
+`
+
+// lockBackend stores lock keys. The Redis cache implements it with SET NX and
+// compare-and-set Lua scripts; the in-memory cache in redistest under its mutex.
+type lockBackend interface {
+	// tryLock sets key to token with ttl unless key exists.
+	tryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// refreshLock resets key's ttl if it still holds token.
+	refreshLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// unlock deletes key if it still holds token.
+	unlock(ctx context.Context, key, token string) (bool, error)
+	logErr(ctx context.Context, step string, err error) error
+}
+
+type lock struct {
+	b     lockBackend
+	key   string
+	token string
+	ttl   time.Duration
+
+	stop     chan struct{} // closed by Unlock to stop auto-refresh
+	done     chan struct{} // closed when the auto-refresh goroutine exits; nil without auto-refresh
+	lost     chan struct{}
+	stopOnce sync.Once
+	lostOnce sync.Once
+}
+
+// Lock acquires a distributed lock on key (SET NX PX) holding a random owner token.
+// Returns ErrLockNotAcquired when the lock is held by someone else.
+// By default a single attempt is made — use WithLockWait to block with backoff.
+//
+//	l, err := cache.Lock(ctx, "lock:reconcile", 30*time.Second, redis.WithLockAutoRefresh())
+//	if errors.Is(err, redis.ErrLockNotAcquired) {
+//	    return nil // another replica is running the job
+//	}
+//	defer l.Unlock(ctx)
+func (c *cache) Lock(ctx context.Context, key string, ttl time.Duration, opts ...LockOption) (Lock, error) {
+	return acquireLock(ctx, c, key, ttl, opts)
+}
+
+func (c *cache) tryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return c.instance.SetNX(ctx, c.key(key), token, ttl).Result()
+}
+
+func (c *cache) refreshLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := c.instance.Eval(ctx, luaRefresh, []string{c.key(key)}, token, ttl.Milliseconds()).Int64()
+	return n == 1, err
+}
+
+func (c *cache) unlock(ctx context.Context, key, token string) (bool, error) {
+	n, err := c.instance.Eval(ctx, luaUnlock, []string{c.key(key)}, token).Int64()
+	return n == 1, err
+}
+
+// acquireLock implements Cache.Lock on top of b.
+func acquireLock(ctx context.Context, b lockBackend, key string, ttl time.Duration, opts []LockOption) (Lock, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		return nil, errors.New("redis: lock ttl must be positive")
+	}
+
+	o := &lockOptions{RetryMin: defaultLockRetryMin, RetryMax: defaultLockRetryMax}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.RetryMin <= 0 {
+		o.RetryMin = defaultLockRetryMin
+	}
+	if o.RetryMax < o.RetryMin {
+		o.RetryMax = o.RetryMin
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var deadline <-chan time.Time
+	if o.Wait && o.WaitTimeout > 0 {
+		timer := time.NewTimer(o.WaitTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	delay := o.RetryMin
+	for {
+		ok, err := b.tryLock(ctx, key, token, ttl)
+		if err != nil {
+			return nil, b.logErr(ctx, "cache.lock", err)
+		}
+		if ok {
+			break
+		}
+		if !o.Wait {
+			return nil, ErrLockNotAcquired
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("redis: context cancelled while waiting for lock: %w", ctx.Err())
+		case <-deadline:
+			return nil, ErrLockNotAcquired
+		case <-time.After(jitter(delay)):
+		}
+		delay = min(delay*2, o.RetryMax)
+	}
+
+	l := &lock{
+		b:     b,
+		key:   key,
+		token: token,
+		ttl:   ttl,
+		stop:  make(chan struct{}),
+		lost:  make(chan struct{}),
+	}
+	if o.AutoRefresh {
+		l.done = make(chan struct{})
+		// Renewal must outlive the acquiring request — keep ctx values (trace) but not its cancellation.
+		go l.autoRefresh(context.WithoutCancel(ctx), max(ttl/3, time.Millisecond))
+	}
+	return l, nil
+}
+
+func (l *lock) Key() string           { return l.key }
+func (l *lock) Token() string         { return l.token }
+func (l *lock) Lost() <-chan struct{} { return l.lost }
+
+// Refresh resets the lock TTL if this handle still owns it.
+func (l *lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	if ttl <= 0 {
+		return errors.New("redis: lock ttl must be positive")
+	}
+	ok, err := l.b.refreshLock(ctx, l.key, l.token, ttl)
+	if err != nil {
+		return l.b.logErr(ctx, "cache.lock_refresh", err)
+	}
+	if !ok {
+		l.markLost()
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Unlock stops auto-refresh and releases the lock if this handle still owns it.
+func (l *lock) Unlock(ctx context.Context) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	l.stopOnce.Do(func() { close(l.stop) })
+	if l.done != nil {
+		<-l.done
+	}
+
+	ok, err := l.b.unlock(ctx, l.key, l.token)
+	if err != nil {
+		return l.b.logErr(ctx, "cache.unlock", err)
+	}
+	if !ok {
+		l.markLost()
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// autoRefresh renews the lock every interval until Unlock is called or the lock is lost.
+// Transient Redis errors are logged and retried on the next tick.
+func (l *lock) autoRefresh(ctx context.Context, interval time.Duration) {
+	defer close(l.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.Refresh(ctx, l.ttl); errors.Is(err, ErrLockNotHeld) {
+				_ = l.b.logErr(ctx, "cache.lock_refresh", fmt.Errorf("redis: lock %q lost before unlock", l.key))
+				return
+			}
+		}
+	}
+}
+
+func (l *lock) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
+
+func newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("redis: failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// jitter returns a random duration in [d/2, d] to spread out competing retries.
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + mathrand.N(half+1)
+}
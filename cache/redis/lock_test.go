@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLock_AcquireAndUnlock(t *testing.T) {
+	c, mr := newTestCache(t)
+	l, err := c.Lock(ctx, "job", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if got, _ := mr.Get("test:job"); got != l.Token() {
+		t.Errorf("stored token = %q, want %q", got, l.Token())
+	}
+	if err := l.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if mr.Exists("test:job") {
+		t.Error("lock key should be deleted after Unlock")
+	}
+}
+
+func TestLock_HeldByOther_ErrLockNotAcquired(t *testing.T) {
+	c, _ := newTestCache(t)
+	if _, err := c.Lock(ctx, "job", time.Minute); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	_, err := c.Lock(ctx, "job", time.Minute)
+	if !errors.Is(err, ErrLockNotAcquired) {
+		t.Errorf("expected ErrLockNotAcquired, got %v", err)
+	}
+}
+
+func TestLock_UnlockAfterTakeover_ErrLockNotHeld(t *testing.T) {
+	c, mr := newTestCache(t)
+	l, _ := c.Lock(ctx, "job", time.Second)
+
+	mr.FastForward(2 * time.Second)
+	other, err := c.Lock(ctx, "job", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock after expiry: %v", err)
+	}
+
+	if err := l.Unlock(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("expected ErrLockNotHeld, got %v", err)
+	}
+	select {
+	case <-l.Lost():
+	default:
+		t.Error("Lost channel should be closed")
+	}
+	if got, _ := mr.Get("test:job"); got != other.Token() {
+		t.Error("stale Unlock must not release the new owner's lock")
+	}
+}
+
+func TestLock_Refresh(t *testing.T) {
+	c, mr := newTestCache(t)
+	l, _ := c.Lock(ctx, "job", time.Second)
+	if err := l.Refresh(ctx, time.Minute); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if ttl := mr.TTL("test:job"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want 1m", ttl)
+	}
+}
+
+func TestLock_WaitAcquiresAfterRelease(t *testing.T) {
+	c, _ := newTestCache(t)
+	held, _ := c.Lock(ctx, "job", time.Minute)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = held.Unlock(ctx)
+	}()
+
+	l, err := c.Lock(ctx, "job", time.Minute,
+		WithLockWait(2*time.Second), WithLockBackoff(10*time.Millisecond, 20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Lock with wait: %v", err)
+	}
+	_ = l.Unlock(ctx)
+}
+
+func TestLock_WaitTimeout(t *testing.T) {
+	c, _ := newTestCache(t)
+	_, _ = c.Lock(ctx, "job", time.Minute)
+	_, err := c.Lock(ctx, "job", time.Minute,
+		WithLockWait(50*time.Millisecond), WithLockBackoff(10*time.Millisecond, 10*time.Millisecond))
+	if !errors.Is(err, ErrLockNotAcquired) {
+		t.Errorf("expected ErrLockNotAcquired, got %v", err)
+	}
+}
+
+func TestLock_WaitContextCancelled(t *testing.T) {
+	c, _ := newTestCache(t)
+	_, _ = c.Lock(ctx, "job", time.Minute)
+
+	cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Lock(cctx, "job", time.Minute, WithLockWait(0))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestLock_AutoRefresh(t *testing.T) {
+	c, mr := newTestCache(t)
+	l, err := c.Lock(ctx, "job", 90*time.Millisecond, WithLockAutoRefresh())
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	mr.SetTTL("test:job", time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if ttl := mr.TTL("test:job"); ttl != 90*time.Millisecond {
+		t.Errorf("TTL = %v, want auto-refresh to restore 90ms", ttl)
+	}
+	if err := l.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+}
+
+func TestLock_InvalidTTL(t *testing.T) {
+	c, _ := newTestCache(t)
+	if _, err := c.Lock(ctx, "job", 0); err == nil {
+		t.Error("expected error for zero ttl")
+	}
+}
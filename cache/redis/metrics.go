@@ -0,0 +1,127 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	opGet      = "get"
+	opGetOrSet = "get_or_set"
+	opSet      = "set"
+
+	resultHit         = "hit"
+	resultNegativeHit = "negative_hit" // key cached as absent by WithNegativeTTL
+	resultMiss        = "miss"
+	resultError       = "error"
+
+	directionRead  = "read"
+	directionWrite = "write"
+
+	// noNamespace labels keys without a ':' separator under the default extractor.
+	noNamespace = "none"
+)
+
+// cacheMetrics records lookup outcomes per key namespace. Redis command metrics
+// come from redisotel; these answer "what is the hit ratio of user:* keys".
+// A nil *cacheMetrics records nothing.
+type cacheMetrics struct {
+	namespace func(key string) string
+	requests  metric.Int64Counter
+	duration  metric.Float64Histogram
+	producer  metric.Float64Histogram
+	size      metric.Int64Histogram
+}
+
+func newCacheMetrics(mp metric.MeterProvider, namespace func(key string) string) (*cacheMetrics, error) {
+	if namespace == nil {
+		namespace = FirstKeySegment
+	}
+	meter := mp.Meter(tracerName)
+	m := &cacheMetrics{namespace: namespace}
+	var err error
+	if m.requests, err = meter.Int64Counter("cache.requests",
+		metric.WithDescription("Get and GetOrSet calls by result (hit, miss, error)"),
+		metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if m.duration, err = meter.Float64Histogram("cache.request.duration",
+		metric.WithDescription("Duration of Get and GetOrSet calls, including the producer on a GetOrSet miss"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if m.producer, err = meter.Float64Histogram("cache.producer.duration",
+		metric.WithDescription("Duration of the GetOrSet producer function on a miss"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if m.size, err = meter.Int64Histogram("cache.value.size",
+		metric.WithDescription("Size of values as stored in Redis, after encoding and compression"),
+		metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FirstKeySegment is the default Config.KeyNamespace: the key up to the first ':'
+// ("user:123:profile" → "user"), or "none" for keys without one.
+func FirstKeySegment(key string) string {
+	ns, _, ok := strings.Cut(key, ":")
+	if !ok || ns == "" {
+		return noNamespace
+	}
+	return ns
+}
+
+func (m *cacheMetrics) recordRequest(ctx context.Context, op, key, result string, start time.Time) {
+	if m == nil {
+		return
+	}
+	attrs := metric.WithAttributes(
+		attribute.String("cache.operation", op),
+		attribute.String("cache.namespace", m.namespace(key)),
+		attribute.String("cache.result", result),
+	)
+	m.requests.Add(ctx, 1, attrs)
+	m.duration.Record(ctx, time.Since(start).Seconds(), attrs)
+}
+
+func (m *cacheMetrics) recordProducer(ctx context.Context, key string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	m.producer.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("cache.namespace", m.namespace(key)),
+		attribute.Bool("error", err != nil),
+	))
+}
+
+// recordSize records the size of an encoded value read from or written to Redis by op.
+func (m *cacheMetrics) recordSize(ctx context.Context, op, direction, key string, size int) {
+	if m == nil {
+		return
+	}
+	m.size.Record(ctx, int64(size), metric.WithAttributes(
+		attribute.String("cache.operation", op),
+		attribute.String("cache.direction", direction),
+		attribute.String("cache.namespace", m.namespace(key)),
+	))
+}
+
+// lookupResult maps a Get error to the cache.result attribute.
+func lookupResult(err error) string {
+	switch {
+	case err == nil:
+		return resultHit
+	case err == errCachedAbsent:
+		return resultNegativeHit
+	case err == ErrKeyNotFound:
+		return resultMiss
+	default:
+		return resultError
+	}
+}
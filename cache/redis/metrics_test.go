@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// newTestMetricsCache returns a cache whose metrics are collected by the returned reader.
+func newTestMetricsCache(t *testing.T) (Cache, *sdkmetric.ManualReader) {
+	t.Helper()
+	c, _ := newTestCache(t)
+	reader := sdkmetric.NewManualReader()
+	metrics, err := newCacheMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)), nil)
+	if err != nil {
+		t.Fatalf("newCacheMetrics: %v", err)
+	}
+	c.(*cache).metrics = metrics
+	return c, reader
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	out := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			out[m.Name] = m.Data
+		}
+	}
+	return out
+}
+
+// requestCount returns the cache.requests value for the given attributes.
+func requestCount(t *testing.T, data map[string]metricdata.Aggregation, op, ns, result string) int64 {
+	t.Helper()
+	sum, ok := data["cache.requests"].(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("cache.requests missing, have %v", data)
+	}
+	want := attribute.NewSet(
+		attribute.String("cache.operation", op),
+		attribute.String("cache.namespace", ns),
+		attribute.String("cache.result", result),
+	)
+	for _, dp := range sum.DataPoints {
+		if dp.Attributes.Equals(&want) {
+			return dp.Value
+		}
+	}
+	return 0
+}
+
+func TestMetrics_GetHitsAndMissesByNamespace(t *testing.T) {
+	c, reader := newTestMetricsCache(t)
+	_ = c.Set(ctx, "user:1", "alice")
+
+	var s string
+	_ = c.Get(ctx, "user:1", &s)
+	_ = c.Get(ctx, "user:1", &s)
+	_ = c.Get(ctx, "user:2", &s)
+	_ = c.Get(ctx, "plain", &s)
+
+	data := collect(t, reader)
+	if got := requestCount(t, data, opGet, "user", resultHit); got != 2 {
+		t.Errorf("user hits = %d, want 2", got)
+	}
+	if got := requestCount(t, data, opGet, "user", resultMiss); got != 1 {
+		t.Errorf("user misses = %d, want 1", got)
+	}
+	if got := requestCount(t, data, opGet, noNamespace, resultMiss); got != 1 {
+		t.Errorf("none misses = %d, want 1", got)
+	}
+	if hist, ok := data["cache.request.duration"].(metricdata.Histogram[float64]); !ok || len(hist.DataPoints) != 3 {
+		t.Errorf("cache.request.duration = %+v", data["cache.request.duration"])
+	}
+}
+
+func TestMetrics_GetOrSetRecordsProducerAndSize(t *testing.T) {
+	c, reader := newTestMetricsCache(t)
+	fn := func() (any, error) { return "0123456789", nil }
+
+	var s string
+	_ = c.GetOrSet(ctx, "report:1", &s, fn)
+	_ = c.GetOrSet(ctx, "report:1", &s, fn)
+	_ = c.GetOrSet(ctx, "report:2", &s, func() (any, error) { return nil, errors.New("db down") })
+
+	data := collect(t, reader)
+	if got := requestCount(t, data, opGetOrSet, "report", resultMiss); got != 2 {
+		t.Errorf("misses = %d, want 2", got)
+	}
+	if got := requestCount(t, data, opGetOrSet, "report", resultHit); got != 1 {
+		t.Errorf("hits = %d, want 1", got)
+	}
+	// The internal lookup must not be counted as a Get.
+	if got := requestCount(t, data, opGet, "report", resultMiss); got != 0 {
+		t.Errorf("GetOrSet leaked %d get misses", got)
+	}
+
+	producer := data["cache.producer.duration"].(metricdata.Histogram[float64])
+	var calls uint64
+	for _, dp := range producer.DataPoints {
+		calls += dp.Count
+	}
+	if calls != 2 {
+		t.Errorf("producer observations = %d, want 2", calls)
+	}
+
+	size := data["cache.value.size"].(metricdata.Histogram[int64])
+	for _, dp := range size.DataPoints {
+		if v, ok := dp.Max.Value(); !ok || v != 10 {
+			t.Errorf("value size max = %v, want 10", v)
+		}
+	}
+	if len(size.DataPoints) != 2 { // get_or_set read + get_or_set write
+		t.Errorf("value size series = %d, want 2", len(size.DataPoints))
+	}
+}
+
+func TestMetrics_RedisErrorsAreCounted(t *testing.T) {
+	c, reader := newTestMetricsCache(t)
+	c.(*cache).instance = goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+
+	var s string
+	_ = c.Get(ctx, "user:1", &s)
+	if got := requestCount(t, collect(t, reader), opGet, "user", resultError); got != 1 {
+		t.Errorf("errors = %d, want 1", got)
+	}
+}
+
+func TestFirstKeySegment(t *testing.T) {
+	for key, want := range map[string]string{
+		"user:1:profile": "user",
+		"session":        noNamespace,
+		":odd":           noNamespace,
+	} {
+		if got := FirstKeySegment(key); got != want {
+			t.Errorf("FirstKeySegment(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
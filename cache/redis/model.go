@@ -2,10 +2,12 @@ package redis
 
 import (
 	"context"
+	"iter"
 	"time"
 
 	"github.com/juanMaAV92/go-utils/logger"
 	goredis "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
 // Cache is the interface for all cache operations.
@@ -18,6 +20,19 @@ type Cache interface {
 	Exists(ctx context.Context, key string) (bool, error)
 	Increment(ctx context.Context, key string, delta int64) (int64, error)
 
+	// Batch (pipelined)
+	GetMany(ctx context.Context, keys []string, dest any) ([]bool, error)
+	SetMany(ctx context.Context, items []SetItem, opts ...SetOption) error
+	ExistsMany(ctx context.Context, keys []string) ([]bool, error)
+	TTLMany(ctx context.Context, keys []string) ([]time.Duration, error)
+
+	// Tag-based invalidation (keys are tagged with WithTags)
+	InvalidateTags(ctx context.Context, tags ...string) error
+
+	// Key scanning
+	Scan(ctx context.Context, pattern string) iter.Seq2[string, error]
+	DeleteByPattern(ctx context.Context, pattern string) (int64, error)
+
 	// TTL management
 	Expire(ctx context.Context, key string, ttl time.Duration) error
 	TTL(ctx context.Context, key string) (time.Duration, error)
@@ -28,16 +43,133 @@ type Cache interface {
 	GetSetMembers(ctx context.Context, key string) ([]string, error)
 	ExistsInSet(ctx context.Context, key, member string) (bool, error)
 
+	// Hashes
+	HSet(ctx context.Context, key string, values any, opts ...SetOption) error
+	HGet(ctx context.Context, key, field string, dest any) error
+	HGetAll(ctx context.Context, key string, dest any) error
+	HDel(ctx context.Context, key string, fields ...string) error
+	HIncrBy(ctx context.Context, key, field string, delta int64) (int64, error)
+
+	// Sorted sets
+	ZAdd(ctx context.Context, key string, members []ZMember, opts ...SetOption) error
+	ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error)
+	ZRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error)
+	ZRevRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error)
+	ZRangeByScore(ctx context.Context, key string, r ScoreRange) ([]ZMember, error)
+	ZRank(ctx context.Context, key, member string) (int64, error)
+	ZRevRank(ctx context.Context, key, member string) (int64, error)
+	ZRem(ctx context.Context, key string, members ...string) error
+	ZTrimTop(ctx context.Context, key string, n int64) (int64, error)
+	ZTrimBefore(ctx context.Context, key string, cutoff time.Time) (int64, error)
+
 	// Pub/Sub
 	Publish(ctx context.Context, channel string, message any) error
 	Subscribe(ctx context.Context, channels ...string) *goredis.PubSub
 
+	// Distributed locks
+	Lock(ctx context.Context, key string, ttl time.Duration, opts ...LockOption) (Lock, error)
+	AcquireSemaphore(ctx context.Context, key string, limit int, ttl time.Duration, opts ...LockOption) (Lock, error)
+
+	// Rate limiting
+	Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+
 	// Lifecycle
 	Close() error
 }
 
+// SetItem is one entry written by SetMany.
+type SetItem struct {
+	Key   string
+	Value any
+	TTL   time.Duration // > 0 overrides the call's TTL (WithTTL / default 7 days) for this item
+}
+
+// ZMember is a sorted-set member with its score.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// ScoreRange selects sorted-set members by score for ZRangeByScore.
+// Use math.Inf(-1) / math.Inf(1) for unbounded ends.
+type ScoreRange struct {
+	Min, Max  float64
+	Exclusive bool  // exclude members scored exactly Min or Max
+	Reverse   bool  // highest score first
+	Offset    int64 // members to skip (pagination)
+	Count     int64 // page size; 0 = no limit
+}
+
+// Lock is a handle to a distributed lock acquired with Cache.Lock.
+// The lock value is a random owner token, so Unlock and Refresh never affect
+// a lock that expired and was re-acquired by another instance.
+type Lock interface {
+	// Key returns the lock key as passed to Cache.Lock (without KeyPrefix).
+	Key() string
+	// Token returns the random owner token stored as the lock value.
+	Token() string
+	// Refresh resets the lock TTL. Returns ErrLockNotHeld if the lock is no longer owned.
+	Refresh(ctx context.Context, ttl time.Duration) error
+	// Unlock releases the lock and stops auto-refresh. Returns ErrLockNotHeld if the lock is no longer owned.
+	Unlock(ctx context.Context) error
+	// Lost is closed when the lock is detected as no longer owned (expired or taken over).
+	Lost() <-chan struct{}
+}
+
+// RateLimitAlgorithm selects how Allow counts requests.
+type RateLimitAlgorithm uint8
+
+const (
+	// SlidingWindow keeps a log of request timestamps and allows at most Limit
+	// requests in any window of Period. Exact, but stores one entry per request.
+	SlidingWindow RateLimitAlgorithm = iota
+	// TokenBucket refills Limit tokens per Period up to Burst. Constant memory per key
+	// and allows short bursts above the average rate.
+	TokenBucket
+)
+
+// RateLimit describes a limit passed to Allow.
+//
+//	redis.PerMinute(100)                                                  // sliding window
+//	redis.RateLimit{Algorithm: redis.TokenBucket, Limit: 10, Period: time.Second, Burst: 20}
+type RateLimit struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int           // requests allowed per Period
+	Period    time.Duration // window length (SlidingWindow) or refill period (TokenBucket)
+	Burst     int           // TokenBucket capacity; defaults to Limit
+}
+
+// RateLimitResult is the outcome of an Allow call.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int           // requests allowed per window (Burst for TokenBucket)
+	Remaining  int           // requests still allowed right now
+	RetryAfter time.Duration // when denied, wait this long before retrying; 0 when allowed
+	ResetAfter time.Duration // time until the limit is fully replenished
+}
+
+// NearCache is a Cache with an in-process LRU in front of Redis.
+// Created by NewNearCache.
+type NearCache interface {
+	Cache
+	// Stats returns local hit/miss counters and the current number of local entries.
+	Stats() NearCacheStats
+}
+
+// NearCacheStats is a snapshot of NearCache counters since creation.
+type NearCacheStats struct {
+	Hits      uint64 // Get/GetOrSet served from the local store
+	Misses    uint64 // Get/GetOrSet that went to Redis
+	Evictions uint64 // entries dropped for capacity
+	Entries   int    // entries currently held locally
+}
+
 type cache struct {
 	keyPrefix string
-	instance  *goredis.Client
+	instance  goredis.UniversalClient
+	cluster   bool // keys may live on different shards; split multi-key commands
 	logger    logger.Logger
+	encoder   encoder            // value codec and compression
+	group     singleflight.Group // GetOrSet WithSingleflight
+	metrics   *cacheMetrics      // nil disables hit/miss metrics
 }
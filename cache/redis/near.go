@@ -0,0 +1,421 @@
+package redis
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/logger"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// invalidationMessage is broadcast on NearCacheConfig.Channel after every write.
+// All clears the whole local store (pattern deletes).
+type invalidationMessage struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+	All    bool     `json:"all,omitempty"`
+}
+
+// nearCache serves Get/GetOrSet from a local LRU and delegates everything else to
+// the embedded remote Cache. Writes evict locally and broadcast an invalidation so
+// the same key is evicted on every other replica.
+type nearCache struct {
+	Cache
+
+	cfg    NearCacheConfig
+	local  *localStore
+	origin string
+	sub    *goredis.PubSub
+	done   chan struct{}
+	logger logger.Logger
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewNearCache wraps remote with a bounded in-process LRU consulted before Redis.
+// Invalidations are exchanged over remote's Pub/Sub, so every replica sharing the
+// same cfg.Channel evicts a key when any of them writes it.
+// Close stops the invalidation listener and closes remote.
+//
+//	remote, _ := redis.New(ctx, cfg, log)
+//	cache, err := redis.NewNearCache(ctx, remote, redis.NearCacheConfig{
+//	    MaxEntries: 5000,
+//	    LocalTTL:   30 * time.Second,
+//	    Channel:    "orders:cache:invalidate",
+//	}, log)
+func NewNearCache(ctx context.Context, remote Cache, cfg NearCacheConfig, log logger.Logger) (NearCache, error) {
+	if ctx == nil {
+		return nil, errors.New("redis: context is required")
+	}
+	if remote == nil {
+		return nil, errors.New("redis: remote cache is required")
+	}
+	cfg = cfg.withDefaults()
+
+	origin, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := remote.Subscribe(ctx, cfg.Channel)
+	// Wait for the subscription confirmation so no invalidation is missed after return.
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, fmt.Errorf("redis: failed to subscribe to invalidation channel: %w", err)
+	}
+
+	n := &nearCache{
+		Cache:  remote,
+		cfg:    cfg,
+		local:  newLocalStore(cfg.MaxEntries),
+		origin: origin,
+		sub:    sub,
+		done:   make(chan struct{}),
+		logger: log,
+	}
+	go n.listen(context.WithoutCancel(ctx))
+	return n, nil
+}
+
+// Get serves key from the local store when present, otherwise reads Redis and keeps
+// the raw payload locally. WithDeleteAfterGet always goes to Redis.
+func (n *nearCache) Get(ctx context.Context, key string, dest any, opts ...GetOption) error {
+	if err := validateDest(ctx, key, dest); err != nil {
+		return err
+	}
+	o := &getOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.DeleteAfterGet {
+		n.local.delete(key)
+		if err := n.Cache.Get(ctx, key, dest, opts...); err != nil {
+			return err
+		}
+		n.broadcast(ctx, key)
+		return nil
+	}
+
+	if raw, ok := n.local.get(key); ok {
+		n.hits.Add(1)
+		return deserialize(raw, dest)
+	}
+	n.misses.Add(1)
+
+	gen := n.local.generation()
+	var raw rawPayload
+	remaining := time.Duration(-1)
+	if err := n.Cache.Get(ctx, key, &raw, getRemainingTTL(&remaining)); err != nil {
+		return err
+	}
+	n.local.set(key, string(raw), n.localTTL(key, remaining), gen)
+	return deserialize(string(raw), dest)
+}
+
+// GetOrSet serves key from the local store when present, otherwise delegates to the
+// remote GetOrSet (keeping its stampede options) and broadcasts if fn produced a new value.
+func (n *nearCache) GetOrSet(ctx context.Context, key string, dest any, fn func() (any, error), opts ...SetOption) error {
+	if err := validateDest(ctx, key, dest); err != nil {
+		return err
+	}
+	if raw, ok := n.local.get(key); ok {
+		n.hits.Add(1)
+		return deserialize(raw, dest)
+	}
+	n.misses.Add(1)
+
+	gen := n.local.generation()
+	produced := false
+	var raw rawPayload
+	remaining := time.Duration(-1)
+	err := n.Cache.GetOrSet(ctx, key, &raw, func() (any, error) {
+		produced = true
+		return fn()
+	}, append(opts[:len(opts):len(opts)], getOrSetRemainingTTL(&remaining))...)
+	if err != nil {
+		return err
+	}
+	if produced {
+		n.broadcast(ctx, key)
+	}
+	n.local.set(key, string(raw), n.localTTL(key, remaining), gen)
+	return deserialize(string(raw), dest)
+}
+
+// Set writes to Redis, evicts the local entry and broadcasts the invalidation.
+func (n *nearCache) Set(ctx context.Context, key string, value any, opts ...SetOption) error {
+	if err := n.Cache.Set(ctx, key, value, opts...); err != nil {
+		return err
+	}
+	n.invalidate(ctx, key)
+	return nil
+}
+
+// SetMany writes to Redis and invalidates every written key.
+func (n *nearCache) SetMany(ctx context.Context, items []SetItem, opts ...SetOption) error {
+	if err := n.Cache.SetMany(ctx, items, opts...); err != nil {
+		return err
+	}
+	if len(items) > 0 {
+		keys := make([]string, len(items))
+		for i, it := range items {
+			keys[i] = it.Key
+		}
+		n.invalidate(ctx, keys...)
+	}
+	return nil
+}
+
+// Delete removes keys from Redis and from every replica's local store.
+func (n *nearCache) Delete(ctx context.Context, keys ...string) error {
+	if err := n.Cache.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		n.invalidate(ctx, keys...)
+	}
+	return nil
+}
+
+// DeleteByPattern removes matching keys from Redis and clears every replica's
+// local store, since the deleted keys are not known individually.
+func (n *nearCache) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	deleted, err := n.Cache.DeleteByPattern(ctx, pattern)
+	// Clear even on failure — some keys may already be gone.
+	n.local.clear()
+	n.publish(ctx, invalidationMessage{Origin: n.origin, All: true})
+	return deleted, err
+}
+
+// InvalidateTags deletes tagged keys in Redis and clears every replica's local
+// store, since the deleted keys are not known individually.
+func (n *nearCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	err := n.Cache.InvalidateTags(ctx, tags...)
+	n.local.clear()
+	n.publish(ctx, invalidationMessage{Origin: n.origin, All: true})
+	return err
+}
+
+// Increment updates the counter in Redis and invalidates any local copy.
+func (n *nearCache) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	result, err := n.Cache.Increment(ctx, key, delta)
+	if err != nil {
+		return 0, err
+	}
+	n.invalidate(ctx, key)
+	return result, nil
+}
+
+// Expire updates the TTL in Redis and invalidates any local copy, so a shortened
+// TTL is never outlived locally.
+func (n *nearCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := n.Cache.Expire(ctx, key, ttl); err != nil {
+		return err
+	}
+	n.invalidate(ctx, key)
+	return nil
+}
+
+// Stats returns local hit/miss counters.
+func (n *nearCache) Stats() NearCacheStats {
+	return NearCacheStats{
+		Hits:      n.hits.Load(),
+		Misses:    n.misses.Load(),
+		Evictions: n.local.evictions(),
+		Entries:   n.local.len(),
+	}
+}
+
+// Close stops the invalidation listener and closes the remote cache.
+func (n *nearCache) Close() error {
+	subErr := n.sub.Close()
+	<-n.done
+	return errors.Join(subErr, n.Cache.Close())
+}
+
+// --- internal helpers ---
+
+// localTTL is how long key is kept locally: the configured TTL, capped at the
+// remaining TTL of the Redis key (negative = no expiry) so a local copy never
+// outlives it.
+func (n *nearCache) localTTL(key string, remaining time.Duration) time.Duration {
+	ttl := n.cfg.LocalTTL
+	if n.cfg.KeyTTL != nil {
+		ttl = n.cfg.KeyTTL(key)
+	}
+	if remaining >= 0 {
+		ttl = min(ttl, remaining)
+	}
+	return ttl
+}
+
+func (n *nearCache) invalidate(ctx context.Context, keys ...string) {
+	n.local.delete(keys...)
+	n.broadcast(ctx, keys...)
+}
+
+// broadcast publishes an invalidation. A failed publish is logged, not returned:
+// the write itself succeeded and other replicas converge within LocalTTL.
+func (n *nearCache) broadcast(ctx context.Context, keys ...string) {
+	n.publish(ctx, invalidationMessage{Origin: n.origin, Keys: keys})
+}
+
+func (n *nearCache) publish(ctx context.Context, msg invalidationMessage) {
+	if err := n.Cache.Publish(ctx, n.cfg.Channel, msg); err != nil && n.logger != nil {
+		n.logger.Warning(ctx, "cache.near.broadcast", "failed to publish invalidation",
+			"channel", n.cfg.Channel, "error", err.Error())
+	}
+}
+
+// openMessage lets OpenMessage decrypt through the remote cache.
+func (n *nearCache) openMessage(payload string) (string, error) {
+	return OpenMessage(n.Cache, payload)
+}
+
+// listen evicts keys invalidated by other replicas until the subscription is closed.
+// go-redis resubscribes automatically after reconnects; messages published while
+// disconnected are lost, which LocalTTL bounds.
+func (n *nearCache) listen(ctx context.Context) {
+	defer close(n.done)
+	for msg := range n.sub.Channel() {
+		var inv invalidationMessage
+		payload, err := OpenMessage(n.Cache, msg.Payload)
+		if err == nil {
+			err = json.Unmarshal([]byte(payload), &inv)
+		}
+		if err != nil {
+			if n.logger != nil {
+				n.logger.Warning(ctx, "cache.near.listen", "invalid invalidation message",
+					"channel", msg.Channel, "error", err.Error())
+			}
+			continue
+		}
+		if inv.Origin == n.origin {
+			continue
+		}
+		if inv.All {
+			n.local.clear()
+			continue
+		}
+		n.local.delete(inv.Keys...)
+	}
+}
+
+// --- local LRU store ---
+
+type localEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// localStore is a mutex-guarded LRU with per-entry expiry.
+// gen is bumped on every delete so a value read from Redis before an
+// invalidation is never stored after it.
+type localStore struct {
+	mu      sync.Mutex
+	max     int
+	ll      *list.List
+	items   map[string]*list.Element
+	gen     uint64
+	evicted uint64
+	now     func() time.Time
+}
+
+func newLocalStore(maxEntries int) *localStore {
+	return &localStore{
+		max:   maxEntries,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+		now:   time.Now,
+	}
+}
+
+func (s *localStore) get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if !ok {
+		return "", false
+	}
+	e := el.Value.(*localEntry)
+	if !s.now().Before(e.expiresAt) {
+		s.remove(el)
+		return "", false
+	}
+	s.ll.MoveToFront(el)
+	return e.value, true
+}
+
+// set stores value unless an invalidation happened since gen was read.
+func (s *localStore) set(key, value string, ttl time.Duration, gen uint64) {
+	if ttl <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.gen {
+		return
+	}
+	expiresAt := s.now().Add(ttl)
+	if el, ok := s.items[key]; ok {
+		e := el.Value.(*localEntry)
+		e.value, e.expiresAt = value, expiresAt
+		s.ll.MoveToFront(el)
+		return
+	}
+	s.items[key] = s.ll.PushFront(&localEntry{key: key, value: value, expiresAt: expiresAt})
+	for s.ll.Len() > s.max {
+		s.remove(s.ll.Back())
+		s.evicted++
+	}
+}
+
+func (s *localStore) delete(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	for _, k := range keys {
+		if el, ok := s.items[k]; ok {
+			s.remove(el)
+		}
+	}
+}
+
+func (s *localStore) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	s.ll.Init()
+	clear(s.items)
+}
+
+func (s *localStore) remove(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*localEntry).key)
+}
+
+func (s *localStore) generation() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gen
+}
+
+func (s *localStore) evictions() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evicted
+}
+
+func (s *localStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
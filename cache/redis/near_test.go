@@ -0,0 +1,193 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// newTestNearCaches returns two near caches (two "replicas") sharing one miniredis.
+func newTestNearCaches(t *testing.T, cfg NearCacheConfig) (NearCache, NearCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	newReplica := func() NearCache {
+		client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+		n, err := NewNearCache(ctx, &cache{instance: client, keyPrefix: "test"}, cfg, nil)
+		if err != nil {
+			t.Fatalf("NewNearCache: %v", err)
+		}
+		t.Cleanup(func() { _ = n.Close() })
+		return n
+	}
+	return newReplica(), newReplica(), mr
+}
+
+// eventually polls cond until it holds or one second elapses.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNearCache_ServesLocalHit(t *testing.T) {
+	a, _, mr := newTestNearCaches(t, NearCacheConfig{})
+	_ = a.Set(ctx, "k", "v1")
+
+	var got string
+	_ = a.Get(ctx, "k", &got)
+	// Changed behind the cache's back — the local copy must still be served.
+	_ = mr.Set("test:k", "v2")
+	_ = a.Get(ctx, "k", &got)
+	if got != "v1" {
+		t.Errorf("got %q, want local v1", got)
+	}
+
+	s := a.Stats()
+	if s.Hits != 1 || s.Misses != 1 || s.Entries != 1 {
+		t.Errorf("stats = %+v, want 1 hit, 1 miss, 1 entry", s)
+	}
+}
+
+func TestNearCache_InvalidationAcrossReplicas(t *testing.T) {
+	a, b, _ := newTestNearCaches(t, NearCacheConfig{})
+	_ = a.Set(ctx, "user:1", "alice")
+
+	var got string
+	// a's own invalidation may still be in flight and legitimately veto b's first local store.
+	eventually(t, func() bool {
+		_ = b.Get(ctx, "user:1", &got)
+		return b.Stats().Entries == 1
+	})
+
+	_ = a.Set(ctx, "user:1", "bob")
+	eventually(t, func() bool { return b.Stats().Entries == 0 })
+
+	_ = b.Get(ctx, "user:1", &got)
+	if got != "bob" {
+		t.Errorf("got %q, want bob after invalidation", got)
+	}
+}
+
+func TestNearCache_DeleteInvalidatesOtherReplica(t *testing.T) {
+	a, b, _ := newTestNearCaches(t, NearCacheConfig{})
+	_ = a.Set(ctx, "k", "v")
+	var got string
+	eventually(t, func() bool {
+		_ = b.Get(ctx, "k", &got)
+		return b.Stats().Entries == 1
+	})
+
+	_ = a.Delete(ctx, "k")
+	eventually(t, func() bool { return b.Stats().Entries == 0 })
+}
+
+func TestNearCache_MaxEntries(t *testing.T) {
+	a, _, _ := newTestNearCaches(t, NearCacheConfig{MaxEntries: 2})
+	var got string
+	for _, k := range []string{"a", "b", "c"} {
+		_ = a.Set(ctx, k, k)
+		_ = a.Get(ctx, k, &got)
+	}
+	s := a.Stats()
+	if s.Entries != 2 || s.Evictions != 1 {
+		t.Errorf("stats = %+v, want 2 entries, 1 eviction", s)
+	}
+}
+
+func TestNearCache_KeyTTLZeroBypassesLocal(t *testing.T) {
+	a, _, _ := newTestNearCaches(t, NearCacheConfig{
+		KeyTTL: func(key string) time.Duration { return 0 },
+	})
+	_ = a.Set(ctx, "k", "v")
+	var got string
+	_ = a.Get(ctx, "k", &got)
+	if a.Stats().Entries != 0 {
+		t.Error("KeyTTL=0 should bypass the local store")
+	}
+}
+
+func TestNearCache_GetOrSet(t *testing.T) {
+	a, _, _ := newTestNearCaches(t, NearCacheConfig{})
+	calls := 0
+	fn := func() (any, error) {
+		calls++
+		return map[string]int{"n": 1}, nil
+	}
+	var got map[string]int
+	_ = a.GetOrSet(ctx, "computed", &got, fn)
+	_ = a.GetOrSet(ctx, "computed", &got, fn)
+	if calls != 1 || got["n"] != 1 {
+		t.Errorf("calls = %d, got %v", calls, got)
+	}
+	if a.Stats().Hits != 1 {
+		t.Errorf("expected second call to be a local hit, stats %+v", a.Stats())
+	}
+}
+
+func TestNearCache_LocalTTLCappedAtRemoteTTL(t *testing.T) {
+	a, _, mr := newTestNearCaches(t, NearCacheConfig{LocalTTL: time.Minute})
+	local := a.(*nearCache).local
+	localTTL := func(key string) time.Duration {
+		local.mu.Lock()
+		defer local.mu.Unlock()
+		el, ok := local.items[key]
+		if !ok {
+			return 0
+		}
+		return time.Until(el.Value.(*localEntry).expiresAt)
+	}
+
+	var got string
+	_ = a.Set(ctx, "session", "s1", WithTTL(2*time.Second))
+	_ = a.Get(ctx, "session", &got)
+	if ttl := localTTL("session"); ttl <= 0 || ttl > 2*time.Second {
+		t.Errorf("Get: local TTL = %v, want at most the remote 2s", ttl)
+	}
+
+	_ = a.GetOrSet(ctx, "token", &got, func() (any, error) { return "t1", nil }, WithTTL(time.Second))
+	if ttl := localTTL("token"); ttl <= 0 || ttl > time.Second {
+		t.Errorf("GetOrSet miss: local TTL = %v, want at most the remote 1s", ttl)
+	}
+
+	_ = mr.Set("test:remote", "r1")
+	mr.SetTTL("test:remote", 3*time.Second)
+	_ = a.GetOrSet(ctx, "remote", &got, func() (any, error) { return "unused", nil })
+	if ttl := localTTL("remote"); got != "r1" || ttl <= 0 || ttl > 3*time.Second {
+		t.Errorf("GetOrSet hit: got %q, local TTL = %v, want at most the remote 3s", got, ttl)
+	}
+
+	_ = a.Set(ctx, "forever", "f1", WithPersist())
+	_ = a.Get(ctx, "forever", &got)
+	if ttl := localTTL("forever"); ttl <= 30*time.Second {
+		t.Errorf("key without expiry: local TTL = %v, want LocalTTL", ttl)
+	}
+}
+
+func TestLocalStore_Expiry(t *testing.T) {
+	s := newLocalStore(10)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	s.set("k", "v", time.Second, s.generation())
+
+	now = now.Add(2 * time.Second)
+	if _, ok := s.get("k"); ok {
+		t.Error("entry should have expired")
+	}
+}
+
+func TestLocalStore_StaleGenerationNotStored(t *testing.T) {
+	s := newLocalStore(10)
+	gen := s.generation()
+	s.delete("k") // invalidation arrives while the remote read is in flight
+	s.set("k", "stale", time.Minute, gen)
+	if _, ok := s.get("k"); ok {
+		t.Error("value read before an invalidation must not be stored")
+	}
+}
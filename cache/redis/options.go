@@ -1,24 +1,27 @@
 package redis
 
-import "time"
+import (
+	"time"
 
-type setOptions struct {
-	TTL        time.Duration
-	IfNotExist bool // NX — only set if key does not exist
-	IfExist    bool // XX — only set if key already exists
-	KeepTTL    bool // KEEPTTL — retain current TTL (Redis 6.0+)
-}
+	"github.com/juanMaAV92/go-utils/cache/redis/internal/backend"
+)
 
-type getOptions struct {
-	DeleteAfterGet bool // GETDEL — atomic get-and-delete
-}
+// The option structs live in internal/backend so redistest can apply options too.
+type (
+	setOptions  = backend.SetOptions
+	getOptions  = backend.GetOptions
+	lockOptions = backend.LockOptions
+)
 
-// SetOption configures a Set or AddToSet call.
+// SetOption configures a Set, AddToSet or GetOrSet call.
 type SetOption func(*setOptions)
 
 // GetOption configures a Get call.
 type GetOption func(*getOptions)
 
+// LockOption configures a Lock call.
+type LockOption func(*lockOptions)
+
 // WithTTL sets the expiry duration for the key.
 func WithTTL(ttl time.Duration) SetOption {
 	return func(o *setOptions) { o.TTL = ttl }
@@ -44,7 +47,100 @@ func WithKeepTTL() SetOption {
 	return func(o *setOptions) { o.KeepTTL = true }
 }
 
+// WithCodec encodes structured values of this Set, SetMany or GetOrSet call with codec
+// instead of Config.Codec. Readers need no option: the codec is recorded in the value header.
+//
+//	cache.Set(ctx, "user:123", userProto, redis.WithCodec(redis.ProtobufCodec))
+func WithCodec(codec Codec) SetOption {
+	return func(o *setOptions) { o.Codec = codec }
+}
+
+// WithSingleflight deduplicates concurrent GetOrSet misses for the same key within
+// this process — fn runs once and every waiting caller receives its result.
+func WithSingleflight() SetOption {
+	return func(o *setOptions) { o.Singleflight = true }
+}
+
+// WithRecomputeLock makes GetOrSet take a distributed lock before calling fn, so only
+// one replica recomputes a missing key. Other replicas poll for the value for up to wait
+// (serving the current value immediately when combined with WithEarlyRefresh) and
+// compute it themselves if it still has not appeared. lockTTL should exceed fn's duration.
+func WithRecomputeLock(lockTTL, wait time.Duration) SetOption {
+	return func(o *setOptions) {
+		o.RecomputeLockTTL = lockTTL
+		o.RecomputeWait = wait
+	}
+}
+
+// WithEarlyRefresh enables XFetch probabilistic early expiration for GetOrSet:
+// a hit may trigger a recompute before the TTL elapses, with probability growing
+// as expiry approaches and proportional to how long fn took last time.
+// beta = 1 is the recommended default; > 1 favours earlier refreshes.
+func WithEarlyRefresh(beta float64) SetOption {
+	return func(o *setOptions) { o.EarlyRefreshBeta = beta }
+}
+
+// WithNegativeTTL makes GetOrSet remember for ttl that fn found nothing: when fn returns
+// an error wrapping ErrKeyNotFound, an "absent" marker is stored under the key and
+// GetOrSet returns ErrKeyNotFound without calling fn until it expires. Get and GetMany
+// also report the key as missing. Set or Delete the key when the entity is created.
+//
+//	err := cache.GetOrSet(ctx, "user:"+id, &user, func() (any, error) {
+//	    u, err := repo.FindByID(ctx, id)
+//	    if errors.Is(err, gorm.ErrRecordNotFound) {
+//	        return nil, redis.ErrKeyNotFound
+//	    }
+//	    return u, err
+//	}, redis.WithTTL(10*time.Minute), redis.WithNegativeTTL(30*time.Second))
+func WithNegativeTTL(ttl time.Duration) SetOption {
+	return func(o *setOptions) { o.NegativeTTL = ttl }
+}
+
+// WithStaleOnError makes GetOrSet keep a shadow copy of every value it produces for
+// window past the value's TTL. When the key is missing and fn fails, the shadow copy
+// is returned instead of the error. The shadow lives in a sidecar key "{key}:__stale",
+// removed together with the value by Delete, InvalidateTags and DeleteByPattern.
+func WithStaleOnError(window time.Duration) SetOption {
+	return func(o *setOptions) { o.StaleWindow = window }
+}
+
 // WithDeleteAfterGet performs an atomic GETDEL — get the value and delete the key.
 func WithDeleteAfterGet() GetOption {
 	return func(o *getOptions) { o.DeleteAfterGet = true }
 }
+
+// getRemainingTTL makes Get store the key's remaining TTL, read together with the
+// value, in *ttl: negative when the key has no expiry, 0 when it is unknown. The
+// near cache uses it so local copies never outlive the Redis key.
+func getRemainingTTL(ttl *time.Duration) GetOption {
+	return func(o *getOptions) { o.RemainingTTL = ttl }
+}
+
+// getOrSetRemainingTTL is getRemainingTTL for GetOrSet. A value loaded by another
+// caller (singleflight, recompute lock, stale copy) reports 0.
+func getOrSetRemainingTTL(ttl *time.Duration) SetOption {
+	return func(o *setOptions) { o.RemainingTTL = ttl }
+}
+
+// WithLockWait blocks until the lock is acquired, retrying with exponential backoff.
+// Gives up with ErrLockNotAcquired after timeout; timeout <= 0 waits until ctx is done.
+func WithLockWait(timeout time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.Wait = true
+		o.WaitTimeout = timeout
+	}
+}
+
+// WithLockBackoff sets the initial and maximum delay between acquire attempts.
+// Defaults: 50ms initial, 1s maximum.
+func WithLockBackoff(initial, maxDelay time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.RetryMin = initial
+		o.RetryMax = maxDelay
+	}
+}
+
+// WithLockAutoRefresh renews the lock TTL every ttl/3 until Unlock is called.
+func WithLockAutoRefresh() LockOption {
+	return func(o *lockOptions) { o.AutoRefresh = true }
+}
@@ -0,0 +1,230 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/juanMaAV92/go-utils/logger"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/juanMaAV92/go-utils/cache/redis"
+
+// MessageHandler processes one decoded Pub/Sub message. A returned error is logged
+// and recorded on the span; Pub/Sub has no redelivery.
+type MessageHandler[T any] func(ctx context.Context, channel string, msg T) error
+
+// envelope wraps a payload published by PublishTraced with its W3C trace context.
+type envelope struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Payload *string           `json:"payload"`
+}
+
+// messageOpener is implemented by caches that seal Pub/Sub payloads.
+type messageOpener interface {
+	openMessage(payload string) (string, error)
+}
+
+// OpenMessage returns the payload of a message received through c.Subscribe,
+// decrypted when c was configured with Encryption. Unencrypted payloads are
+// returned unchanged. SubscribeHandler and NearCache call it themselves.
+//
+//	for msg := range sub.Channel() {
+//	    payload, err := redis.OpenMessage(cache, msg.Payload)
+//	}
+func OpenMessage(c Cache, payload string) (string, error) {
+	if o, ok := c.(messageOpener); ok {
+		return o.openMessage(payload)
+	}
+	if isEncrypted(payload) {
+		return "", errors.New("redis: message is encrypted and the cache cannot decrypt it")
+	}
+	return payload, nil
+}
+
+// PublishTraced publishes message like Cache.Publish, wrapped in an envelope that
+// carries the W3C trace context of ctx so SubscribeHandler continues the trace.
+// Plain Subscribe receivers see the envelope JSON, not the bare message.
+//
+//	err := redis.PublishTraced(ctx, cache, "orders:events", OrderEvent{ID: "ord_1", Status: "paid"})
+func PublishTraced(ctx context.Context, c Cache, channel string, message any) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	if c == nil {
+		return errors.New("redis: cache is required")
+	}
+	if channel == "" {
+		return errors.New("redis: channel is required")
+	}
+	serialized, err := serialize(message)
+	if err != nil {
+		return err
+	}
+	payload := formatArg(serialized)
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx,
+		fmt.Sprintf("Redis pubsub publish %s", channel),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(pubsubAttrs(channel, "publish")...),
+	)
+	defer span.End()
+	span.SetAttributes(attribute.Int("messaging.message.body.size", len(payload)))
+
+	carrier := &headerCarrier{headers: make(map[string]string)}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	if err := c.Publish(ctx, channel, envelope{Headers: carrier.headers, Payload: &payload}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+// SubscribeHandler subscribes to channels and calls handler for every message,
+// decoded into T like Get decodes values, on a pool of cfg.Workers goroutines.
+// Payloads published with PublishTraced continue the publisher's trace; plain
+// Publish payloads start a new one. Messages that fail to decode are logged and skipped.
+//
+// It returns an error if the initial subscription fails, otherwise it blocks until
+// ctx is cancelled. go-redis reconnects and resubscribes after disconnects;
+// messages published while disconnected are lost. log may be nil.
+//
+//	go redis.SubscribeHandler(ctx, cache, []string{"orders:events"},
+//	    func(ctx context.Context, channel string, ev OrderEvent) error {
+//	        return svc.OnOrderEvent(ctx, ev)
+//	    }, redis.SubscribeConfig{Workers: 4}, log)
+func SubscribeHandler[T any](ctx context.Context, c Cache, channels []string, handler MessageHandler[T], cfg SubscribeConfig, log logger.Logger) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	if c == nil {
+		return errors.New("redis: cache is required")
+	}
+	if len(channels) == 0 {
+		return errors.New("redis: at least one channel is required")
+	}
+	if handler == nil {
+		return errors.New("redis: handler is required")
+	}
+	cfg = cfg.withDefaults()
+
+	sub := c.Subscribe(ctx, channels...)
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return fmt.Errorf("redis: failed to subscribe: %w", err)
+	}
+
+	ch := sub.Channel(goredis.WithChannelSize(cfg.Buffer))
+	tracer := otel.Tracer(tracerName)
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Workers; i++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			for msg := range ch {
+				handleMessage(ctx, tracer, c, msg, handler, workerID, log)
+			}
+		}(i)
+	}
+
+	<-ctx.Done()
+	_ = sub.Close()
+	wg.Wait()
+	return ctx.Err()
+}
+
+func handleMessage[T any](ctx context.Context, tracer trace.Tracer, c Cache, msg *goredis.Message, handler MessageHandler[T], workerID int, log logger.Logger) {
+	raw, err := OpenMessage(c, msg.Payload)
+	if err != nil {
+		if log != nil {
+			log.Warning(ctx, "cache.subscribe.decode", "failed to decrypt message",
+				"channel", msg.Channel, "error", err.Error())
+		}
+		return
+	}
+	payload, headers := openEnvelope(raw)
+	if len(headers) > 0 {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, &headerCarrier{headers: headers})
+	}
+
+	ctx, span := tracer.Start(ctx,
+		fmt.Sprintf("Redis pubsub process %s", msg.Channel),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(pubsubAttrs(msg.Channel, "process")...),
+		trace.WithAttributes(
+			attribute.Int("messaging.worker.id", workerID),
+			attribute.Int("messaging.message.body.size", len(payload)),
+		),
+	)
+	defer span.End()
+
+	var v T
+	if err := deserialize(payload, &v); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if log != nil {
+			log.Warning(ctx, "cache.subscribe.decode", "failed to decode message",
+				"channel", msg.Channel, "error", err.Error())
+		}
+		return
+	}
+	if err := handler(ctx, msg.Channel, v); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if log != nil {
+			log.Error(ctx, "cache.subscribe.handle", "message handler failed",
+				"channel", msg.Channel, "worker", workerID, "error", err.Error())
+		}
+		return
+	}
+	span.SetStatus(codes.Ok, "")
+}
+
+// openEnvelope returns the payload and trace headers of an envelope, or the raw
+// payload when it was not published with PublishTraced.
+func openEnvelope(raw string) (string, map[string]string) {
+	if len(raw) == 0 || raw[0] != '{' {
+		return raw, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var env envelope
+	if err := dec.Decode(&env); err != nil || env.Payload == nil {
+		return raw, nil
+	}
+	return *env.Payload, env.Headers
+}
+
+func pubsubAttrs(channel, operation string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "redis"),
+		attribute.String("messaging.operation", operation),
+		attribute.String("messaging.destination.name", channel),
+		attribute.String("messaging.destination.kind", "channel"),
+	}
+}
+
+// headerCarrier implements propagation.TextMapCarrier for envelope headers.
+type headerCarrier struct {
+	headers map[string]string
+}
+
+func (c *headerCarrier) Get(key string) string { return c.headers[key] }
+func (c *headerCarrier) Set(key, value string) { c.headers[key] = value }
+func (c *headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.headers))
+	for k := range c.headers {
+		keys = append(keys, k)
+	}
+	return keys
+}
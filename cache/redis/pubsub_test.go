@@ -0,0 +1,171 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type pubsubEvent struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// startHandler runs SubscribeHandler until the test ends and waits for the subscription.
+func startHandler[T any](t *testing.T, c Cache, channel string, cfg SubscribeConfig, h MessageHandler[T]) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- SubscribeHandler(ctx, c, []string{channel}, h, cfg, nil) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	// Wait until the subscription is registered so the first publish is not lost.
+	eventually(t, func() bool {
+		n, _ := c.(*cache).instance.PubSubNumSub(context.Background(), channel).Result()
+		return n[channel] > 0
+	})
+}
+
+func TestSubscribeHandler_DecodesTypedMessages(t *testing.T) {
+	c, _ := newTestCache(t)
+	got := make(chan pubsubEvent, 2)
+	startHandler(t, c, "orders", SubscribeConfig{}, func(_ context.Context, channel string, ev pubsubEvent) error {
+		if channel != "orders" {
+			t.Errorf("channel = %q", channel)
+		}
+		got <- ev
+		return nil
+	})
+
+	_ = PublishTraced(ctx, c, "orders", pubsubEvent{ID: "1", Status: "paid"})
+	_ = c.Publish(ctx, "orders", pubsubEvent{ID: "2", Status: "raw"})
+
+	for _, want := range []string{"1", "2"} {
+		select {
+		case ev := <-got:
+			if ev.ID != want {
+				t.Errorf("got %+v, want id %s", ev, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("message %s not handled", want)
+		}
+	}
+}
+
+func TestSubscribeHandler_RestoresTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	c, _ := newTestCache(t)
+	got := make(chan trace.TraceID, 1)
+	startHandler(t, c, "orders", SubscribeConfig{}, func(ctx context.Context, _ string, _ string) error {
+		got <- trace.SpanContextFromContext(ctx).TraceID()
+		return nil
+	})
+
+	traceID := trace.TraceID{9, 8, 7}
+	parent := trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{1, 2, 3},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	if err := PublishTraced(parent, c, "orders", "hello"); err != nil {
+		t.Fatalf("PublishTraced: %v", err)
+	}
+
+	select {
+	case id := <-got:
+		if id != traceID {
+			t.Errorf("trace id = %s, want %s", id, traceID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not handled")
+	}
+}
+
+func TestSubscribeHandler_SkipsUndecodableMessages(t *testing.T) {
+	c, _ := newTestCache(t)
+	got := make(chan int, 2)
+	startHandler(t, c, "counts", SubscribeConfig{Workers: 1}, func(_ context.Context, _ string, n int) error {
+		got <- n
+		return nil
+	})
+
+	_ = c.Publish(ctx, "counts", "not a number")
+	_ = c.Publish(ctx, "counts", 42)
+	select {
+	case n := <-got:
+		if n != 42 {
+			t.Errorf("got %d, want 42", n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("valid message not handled")
+	}
+}
+
+func TestSubscribeHandler_BoundedWorkers(t *testing.T) {
+	c, _ := newTestCache(t)
+	var running, peak, handled atomic.Int32
+	startHandler(t, c, "jobs", SubscribeConfig{Workers: 2}, func(context.Context, string, string) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		handled.Add(1)
+		return errors.New("handler errors are logged, not fatal")
+	})
+
+	for range 6 {
+		_ = c.Publish(ctx, "jobs", "x")
+	}
+	eventually(t, func() bool { return handled.Load() == 6 })
+	if peak.Load() != 2 {
+		t.Errorf("peak concurrency = %d, want 2", peak.Load())
+	}
+}
+
+func TestSubscribeHandler_ResubscribesAfterRestart(t *testing.T) {
+	c, mr := newTestCache(t)
+	var handled atomic.Int32
+	startHandler(t, c, "events", SubscribeConfig{}, func(context.Context, string, string) error {
+		handled.Add(1)
+		return nil
+	})
+
+	mr.Restart()
+	// Messages published before the resubscription are lost; keep publishing until one lands.
+	eventually(t, func() bool {
+		_ = c.Publish(ctx, "events", "after restart")
+		return handled.Load() > 0
+	})
+}
+
+func TestSubscribeHandler_InitialSubscribeFails(t *testing.T) {
+	c, mr := newTestCache(t)
+	mr.Close()
+	err := SubscribeHandler(ctx, c, []string{"events"}, func(context.Context, string, string) error { return nil }, SubscribeConfig{}, nil)
+	if err == nil {
+		t.Fatal("expected subscribe error")
+	}
+}
+
+func TestOpenEnvelope_LeavesForeignJSONUntouched(t *testing.T) {
+	raw := `{"payload":"x","other":1}`
+	if payload, headers := openEnvelope(raw); payload != raw || headers != nil {
+		t.Errorf("openEnvelope = %q, %v", payload, headers)
+	}
+	if payload, _ := openEnvelope(`{"headers":{"traceparent":"t"},"payload":"x"}`); payload != "x" {
+		t.Errorf("payload = %q, want x", payload)
+	}
+}
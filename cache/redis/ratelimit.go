@@ -0,0 +1,133 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Both scripts read the clock with Redis TIME so every replica shares one time source.
+// They return {allowed, remaining, retry_after_ms, reset_after_ms}.
+
+// Sliding window log: one sorted-set member per request, scored by its timestamp (ms).
+const luaSlidingWindow = `
+	local t = redis.call('TIME')
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	local window = tonumber(ARGV[1])
+	local limit = tonumber(ARGV[2])
+
+	redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+	local count = redis.call('ZCARD', KEYS[1])
+	if count < limit then
+		redis.call('ZADD', KEYS[1], now, ARGV[3])
+		redis.call('PEXPIRE', KEYS[1], window)
+		return {1, limit - count - 1, 0, window}
+	end
+
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+	return {0, 0, tonumber(oldest[2]) + window - now, tonumber(newest[2]) + window - now}
+`
+
+// Token bucket: a hash holding the fractional token count and the last refill time (ms).
+const luaTokenBucket = `
+	local t = redis.call('TIME')
+	local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+	local rate = tonumber(ARGV[1])
+	local burst = tonumber(ARGV[2])
+
+	local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+	local tokens = tonumber(state[1])
+	local ts = tonumber(state[2])
+	if tokens == nil or ts == nil then
+		tokens = burst
+		ts = now
+	end
+	tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+	local allowed = 0
+	local retry = 0
+	if tokens >= 1 then
+		tokens = tokens - 1
+		allowed = 1
+	else
+		retry = math.ceil((1 - tokens) / rate)
+	end
+
+	local reset = math.ceil((burst - tokens) / rate)
+	redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+	redis.call('PEXPIRE', KEYS[1], math.max(reset, 1))
+	return {allowed, math.floor(tokens), retry, reset}
+`
+
+// PerSecond returns a sliding-window limit of n requests per second.
+func PerSecond(n int) RateLimit {
+	return RateLimit{Algorithm: SlidingWindow, Limit: n, Period: time.Second}
+}
+
+// PerMinute returns a sliding-window limit of n requests per minute.
+func PerMinute(n int) RateLimit {
+	return RateLimit{Algorithm: SlidingWindow, Limit: n, Period: time.Minute}
+}
+
+// PerHour returns a sliding-window limit of n requests per hour.
+func PerHour(n int) RateLimit {
+	return RateLimit{Algorithm: SlidingWindow, Limit: n, Period: time.Hour}
+}
+
+// Allow records one request against key and reports whether it is within limit.
+// The check and the update run atomically in a Lua script, so the limit holds
+// across any number of replicas.
+//
+//	res, err := cache.Allow(ctx, "ratelimit:user:123", redis.PerMinute(100))
+//	if err == nil && !res.Allowed {
+//	    // reject; retry after res.RetryAfter
+//	}
+func (c *cache) Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return RateLimitResult{}, err
+	}
+	if limit.Limit <= 0 || limit.Period < time.Millisecond {
+		return RateLimitResult{}, errors.New("redis: rate limit requires Limit > 0 and Period >= 1ms")
+	}
+
+	var (
+		res   []int64
+		err   error
+		quota = limit.Limit
+	)
+	switch limit.Algorithm {
+	case SlidingWindow:
+		member, tokenErr := newToken()
+		if tokenErr != nil {
+			return RateLimitResult{}, tokenErr
+		}
+		res, err = c.instance.Eval(ctx, luaSlidingWindow, []string{c.key(key)},
+			limit.Period.Milliseconds(), limit.Limit, member).Int64Slice()
+	case TokenBucket:
+		if limit.Burst > 0 {
+			quota = limit.Burst
+		}
+		perMs := float64(limit.Limit) / (float64(limit.Period) / float64(time.Millisecond))
+		res, err = c.instance.Eval(ctx, luaTokenBucket, []string{c.key(key)},
+			strconv.FormatFloat(perMs, 'g', -1, 64), quota).Int64Slice()
+	default:
+		return RateLimitResult{}, fmt.Errorf("redis: unknown rate limit algorithm %d", limit.Algorithm)
+	}
+	if err != nil {
+		return RateLimitResult{}, c.logErr(ctx, "cache.rate_limit", err)
+	}
+	if len(res) != 4 {
+		return RateLimitResult{}, c.logErr(ctx, "cache.rate_limit", fmt.Errorf("redis: unexpected rate limit reply %v", res))
+	}
+
+	return RateLimitResult{
+		Allowed:    res[0] == 1,
+		Limit:      quota,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		ResetAfter: time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
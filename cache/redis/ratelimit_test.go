@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllow_SlidingWindow(t *testing.T) {
+	c, mr := newTestCache(t)
+	now := time.Now()
+	mr.SetTime(now)
+
+	limit := RateLimit{Algorithm: SlidingWindow, Limit: 3, Period: time.Minute}
+	for i := 0; i < 3; i++ {
+		res, err := c.Allow(ctx, "rl:user", limit)
+		if err != nil || !res.Allowed {
+			t.Fatalf("request %d: allowed=%v err=%v", i+1, res.Allowed, err)
+		}
+		if res.Remaining != 2-i {
+			t.Errorf("request %d: remaining = %d, want %d", i+1, res.Remaining, 2-i)
+		}
+	}
+
+	res, _ := c.Allow(ctx, "rl:user", limit)
+	if res.Allowed {
+		t.Fatal("4th request should be denied")
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %v, want (0, 1m]", res.RetryAfter)
+	}
+
+	mr.SetTime(now.Add(61 * time.Second))
+	res, _ = c.Allow(ctx, "rl:user", limit)
+	if !res.Allowed {
+		t.Error("request should be allowed once the window has slid past")
+	}
+}
+
+func TestAllow_TokenBucket(t *testing.T) {
+	c, mr := newTestCache(t)
+	now := time.Now()
+	mr.SetTime(now)
+
+	limit := RateLimit{Algorithm: TokenBucket, Limit: 1, Period: time.Second, Burst: 2}
+	for i := 0; i < 2; i++ {
+		if res, _ := c.Allow(ctx, "rl:ip", limit); !res.Allowed {
+			t.Fatalf("burst request %d should be allowed", i+1)
+		}
+	}
+	res, _ := c.Allow(ctx, "rl:ip", limit)
+	if res.Allowed {
+		t.Fatal("request beyond burst should be denied")
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > time.Second {
+		t.Errorf("RetryAfter = %v, want (0, 1s]", res.RetryAfter)
+	}
+	if res.Limit != 2 {
+		t.Errorf("Limit = %d, want burst 2", res.Limit)
+	}
+
+	mr.SetTime(now.Add(time.Second))
+	if res, _ := c.Allow(ctx, "rl:ip", limit); !res.Allowed {
+		t.Error("one token should have refilled after 1s")
+	}
+}
+
+func TestAllow_InvalidLimit(t *testing.T) {
+	c, _ := newTestCache(t)
+	if _, err := c.Allow(ctx, "rl", RateLimit{Limit: 0, Period: time.Second}); err == nil {
+		t.Error("expected error for zero limit")
+	}
+}
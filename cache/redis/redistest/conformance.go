@@ -0,0 +1,587 @@
+package redistest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/cache/redis"
+)
+
+// Harness is a Cache under test plus a way to move its clock forward.
+type Harness struct {
+	Cache redis.Cache
+	// Advance moves time forward by d for TTLs, locks and rate limits.
+	Advance func(d time.Duration)
+}
+
+// RunConformance checks that caches created by newHarness behave like the Redis-backed
+// Cache. Each subtest gets a fresh, empty Cache; newHarness should register cleanup
+// with t. Caches that scope Scan and DeleteByPattern to a KeyPrefix must set one.
+//
+//	func TestMyCache(t *testing.T) {
+//	    redistest.RunConformance(t, func(t *testing.T) redistest.Harness {
+//	        cache, clock := redistest.NewCache(t)
+//	        return redistest.Harness{Cache: NewMyDecorator(cache), Advance: clock.Advance}
+//	    })
+//	}
+func RunConformance(t *testing.T, newHarness func(t *testing.T) Harness) {
+	for _, tc := range conformanceTests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newHarness(t))
+		})
+	}
+}
+
+type profile struct {
+	Name   string `redis:"name" json:"name"`
+	Score  int    `redis:"score" json:"score"`
+	Active bool   `redis:"active" json:"active"`
+}
+
+var conformanceTests = []struct {
+	name string
+	run  func(t *testing.T, h Harness)
+}{
+	{"SetGet", testSetGet},
+	{"TTL", testTTL},
+	{"Conditional", testConditional},
+	{"KeepTTL", testKeepTTL},
+	{"DeleteAfterGet", testDeleteAfterGet},
+	{"Increment", testIncrement},
+	{"Expire", testExpire},
+	{"WrongType", testWrongType},
+	{"GetOrSet", testGetOrSet},
+	{"NegativeCaching", testNegativeCaching},
+	{"StaleOnError", testStaleOnError},
+	{"Batch", testBatch},
+	{"Sets", testSets},
+	{"Hashes", testHashes},
+	{"SortedSets", testSortedSets},
+	{"Tags", testTags},
+	{"Scan", testScan},
+	{"PubSub", testPubSub},
+	{"Lock", testLock},
+	{"Semaphore", testSemaphore},
+	{"Allow", testAllow},
+}
+
+func testSetGet(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.Set(ctx, "str", "hello"))
+	mustNoErr(t, c.Set(ctx, "num", 42))
+	mustNoErr(t, c.Set(ctx, "flag", true))
+	mustNoErr(t, c.Set(ctx, "struct", profile{Name: "alice", Score: 7}))
+
+	var s string
+	mustNoErr(t, c.Get(ctx, "str", &s))
+	var n int
+	mustNoErr(t, c.Get(ctx, "num", &n))
+	var b bool
+	mustNoErr(t, c.Get(ctx, "flag", &b))
+	var p profile
+	mustNoErr(t, c.Get(ctx, "struct", &p))
+	if s != "hello" || n != 42 || !b || p.Name != "alice" || p.Score != 7 {
+		t.Errorf("got %q %d %v %+v", s, n, b, p)
+	}
+
+	if err := c.Get(ctx, "missing", &s); !errors.Is(err, redis.ErrKeyNotFound) {
+		t.Errorf("Get missing: expected ErrKeyNotFound, got %v", err)
+	}
+	mustNoErr(t, c.Delete(ctx, "str", "num", "missing"))
+	if ok, _ := c.Exists(ctx, "str"); ok {
+		t.Error("deleted key still exists")
+	}
+}
+
+func testTTL(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.Set(ctx, "short", "v", redis.WithTTL(10*time.Second)))
+	mustNoErr(t, c.Set(ctx, "forever", "v", redis.WithPersist()))
+	if ttl, _ := c.TTL(ctx, "short"); ttl != 10*time.Second {
+		t.Errorf("TTL = %v, want 10s", ttl)
+	}
+	if ttl, _ := c.TTL(ctx, "forever"); ttl != -1 {
+		t.Errorf("TTL persistent = %v, want -1", ttl)
+	}
+	if ttl, _ := c.TTL(ctx, "missing"); ttl != -2 {
+		t.Errorf("TTL missing = %v, want -2", ttl)
+	}
+
+	h.Advance(11 * time.Second)
+	var s string
+	if err := c.Get(ctx, "short", &s); !errors.Is(err, redis.ErrKeyNotFound) {
+		t.Errorf("expired key: expected ErrKeyNotFound, got %v", err)
+	}
+	if ok, _ := c.Exists(ctx, "forever"); !ok {
+		t.Error("persistent key expired")
+	}
+}
+
+func testConditional(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	if err := c.Set(ctx, "k", "1", redis.WithXX()); !errors.Is(err, redis.ErrKeyNotSet) {
+		t.Errorf("XX on missing: expected ErrKeyNotSet, got %v", err)
+	}
+	mustNoErr(t, c.Set(ctx, "k", "1", redis.WithNX()))
+	if err := c.Set(ctx, "k", "2", redis.WithNX()); !errors.Is(err, redis.ErrKeyNotSet) {
+		t.Errorf("NX on existing: expected ErrKeyNotSet, got %v", err)
+	}
+	mustNoErr(t, c.Set(ctx, "k", "3", redis.WithXX()))
+	var s string
+	mustNoErr(t, c.Get(ctx, "k", &s))
+	if s != "3" {
+		t.Errorf("value = %q, want 3", s)
+	}
+	if err := c.Set(ctx, "k", "4", redis.WithNX(), redis.WithXX()); err == nil {
+		t.Error("expected error for NX with XX")
+	}
+}
+
+func testKeepTTL(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.Set(ctx, "k", "1", redis.WithTTL(time.Minute)))
+	mustNoErr(t, c.Set(ctx, "k", "2", redis.WithKeepTTL()))
+	if ttl, _ := c.TTL(ctx, "k"); ttl != time.Minute {
+		t.Errorf("TTL after KEEPTTL = %v, want 1m", ttl)
+	}
+	mustNoErr(t, c.Set(ctx, "k", "3", redis.WithPersist()))
+	if ttl, _ := c.TTL(ctx, "k"); ttl != -1 {
+		t.Errorf("TTL after plain Set = %v, want -1", ttl)
+	}
+}
+
+func testDeleteAfterGet(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.Set(ctx, "otp", "998877"))
+	var s string
+	mustNoErr(t, c.Get(ctx, "otp", &s, redis.WithDeleteAfterGet()))
+	if s != "998877" {
+		t.Errorf("value = %q", s)
+	}
+	if err := c.Get(ctx, "otp", &s, redis.WithDeleteAfterGet()); !errors.Is(err, redis.ErrKeyNotFound) {
+		t.Errorf("second GETDEL: expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func testIncrement(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	if n, err := c.Increment(ctx, "counter", 5); err != nil || n != 5 {
+		t.Errorf("Increment missing = %d, %v", n, err)
+	}
+	if n, err := c.Increment(ctx, "counter", -2); err != nil || n != 3 {
+		t.Errorf("Increment = %d, %v", n, err)
+	}
+	mustNoErr(t, c.Expire(ctx, "counter", time.Minute))
+	_, _ = c.Increment(ctx, "counter", 1)
+	if ttl, _ := c.TTL(ctx, "counter"); ttl != time.Minute {
+		t.Errorf("Increment must keep TTL, got %v", ttl)
+	}
+	var n int
+	mustNoErr(t, c.Get(ctx, "counter", &n))
+	if n != 4 {
+		t.Errorf("Get counter = %d", n)
+	}
+
+	mustNoErr(t, c.Set(ctx, "text", "abc"))
+	if _, err := c.Increment(ctx, "text", 1); err == nil {
+		t.Error("expected error incrementing a non-integer")
+	}
+}
+
+func testExpire(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.Expire(ctx, "missing", time.Minute))
+	mustNoErr(t, c.Set(ctx, "k", "v", redis.WithPersist()))
+	mustNoErr(t, c.Expire(ctx, "k", 30*time.Second))
+	if ttl, _ := c.TTL(ctx, "k"); ttl != 30*time.Second {
+		t.Errorf("TTL = %v, want 30s", ttl)
+	}
+	h.Advance(31 * time.Second)
+	if ok, _ := c.Exists(ctx, "k"); ok {
+		t.Error("key should have expired")
+	}
+}
+
+func testWrongType(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.AddToSet(ctx, "set", []string{"a"}))
+	var s string
+	if err := c.Get(ctx, "set", &s); err == nil || errors.Is(err, redis.ErrKeyNotFound) {
+		t.Errorf("Get on a set: expected a type error, got %v", err)
+	}
+	mustNoErr(t, c.Set(ctx, "set", "now a string"))
+	mustNoErr(t, c.Get(ctx, "set", &s))
+}
+
+func testGetOrSet(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	calls := 0
+	fn := func() (any, error) {
+		calls++
+		return profile{Name: "bob"}, nil
+	}
+	var p profile
+	mustNoErr(t, c.GetOrSet(ctx, "user", &p, fn, redis.WithTTL(time.Minute)))
+	p = profile{}
+	mustNoErr(t, c.GetOrSet(ctx, "user", &p, fn))
+	if calls != 1 || p.Name != "bob" {
+		t.Errorf("calls = %d, value = %+v", calls, p)
+	}
+
+	if err := c.GetOrSet(ctx, "failing", &p, func() (any, error) {
+		return nil, errors.New("db down")
+	}); err == nil {
+		t.Error("expected producer error")
+	}
+	if ok, _ := c.Exists(ctx, "failing"); ok {
+		t.Error("failed producer must not write")
+	}
+}
+
+func testNegativeCaching(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	calls := 0
+	fn := func() (any, error) {
+		calls++
+		return nil, redis.ErrKeyNotFound
+	}
+	var p profile
+	for range 2 {
+		if err := c.GetOrSet(ctx, "ghost", &p, fn, redis.WithNegativeTTL(time.Minute)); !errors.Is(err, redis.ErrKeyNotFound) {
+			t.Fatalf("GetOrSet = %v, want ErrKeyNotFound", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if err := c.Get(ctx, "ghost", &p); !errors.Is(err, redis.ErrKeyNotFound) {
+		t.Errorf("Get = %v, want ErrKeyNotFound", err)
+	}
+
+	h.Advance(2 * time.Minute)
+	_ = c.GetOrSet(ctx, "ghost", &p, fn, redis.WithNegativeTTL(time.Minute))
+	if calls != 2 {
+		t.Errorf("calls after marker expiry = %d, want 2", calls)
+	}
+}
+
+func testStaleOnError(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	var p profile
+	mustNoErr(t, c.GetOrSet(ctx, "user", &p, func() (any, error) {
+		return profile{Name: "bob"}, nil
+	}, redis.WithTTL(time.Minute), redis.WithStaleOnError(time.Hour)))
+
+	h.Advance(2 * time.Minute)
+	failing := func() (any, error) { return nil, errors.New("db down") }
+	p = profile{}
+	if err := c.GetOrSet(ctx, "user", &p, failing, redis.WithStaleOnError(time.Hour)); err != nil || p.Name != "bob" {
+		t.Errorf("stale fallback = %+v, %v", p, err)
+	}
+
+	h.Advance(2 * time.Hour)
+	if err := c.GetOrSet(ctx, "user", &p, failing, redis.WithStaleOnError(time.Hour)); err == nil {
+		t.Error("expected producer error past the stale window")
+	}
+}
+
+func testBatch(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.SetMany(ctx, []redis.SetItem{
+		{Key: "a", Value: profile{Name: "a"}},
+		{Key: "b", Value: profile{Name: "b"}, TTL: time.Minute},
+	}, redis.WithTTL(time.Hour)))
+
+	got := map[string]profile{}
+	found, err := c.GetMany(ctx, []string{"a", "missing", "b"}, &got)
+	mustNoErr(t, err)
+	if !slices.Equal(found, []bool{true, false, true}) || got["a"].Name != "a" || got["b"].Name != "b" {
+		t.Errorf("GetMany = %v, %+v", found, got)
+	}
+
+	exists, err := c.ExistsMany(ctx, []string{"a", "missing"})
+	mustNoErr(t, err)
+	if !slices.Equal(exists, []bool{true, false}) {
+		t.Errorf("ExistsMany = %v", exists)
+	}
+	ttls, err := c.TTLMany(ctx, []string{"a", "b", "missing"})
+	mustNoErr(t, err)
+	if !slices.Equal(ttls, []time.Duration{time.Hour, time.Minute, -2}) {
+		t.Errorf("TTLMany = %v", ttls)
+	}
+}
+
+func testSets(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.AddToSet(ctx, "roles", []string{"admin", "editor"}, redis.WithTTL(time.Minute)))
+	mustNoErr(t, c.AddToSet(ctx, "roles", []string{"viewer"}))
+	members, err := c.GetSetMembers(ctx, "roles")
+	mustNoErr(t, err)
+	slices.Sort(members)
+	if !slices.Equal(members, []string{"admin", "editor", "viewer"}) {
+		t.Errorf("members = %v", members)
+	}
+	if ok, _ := c.ExistsInSet(ctx, "roles", "admin"); !ok {
+		t.Error("admin should be a member")
+	}
+	if ttl, _ := c.TTL(ctx, "roles"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want 1m", ttl)
+	}
+
+	mustNoErr(t, c.RemoveFromSet(ctx, "roles", []string{"admin", "editor", "viewer"}))
+	if ok, _ := c.Exists(ctx, "roles"); ok {
+		t.Error("empty set should be deleted")
+	}
+	if members, _ := c.GetSetMembers(ctx, "roles"); len(members) != 0 {
+		t.Errorf("members of missing set = %v", members)
+	}
+}
+
+func testHashes(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.HSet(ctx, "profile", profile{Name: "alice", Score: 10, Active: true}, redis.WithTTL(time.Hour)))
+	var got profile
+	mustNoErr(t, c.HGetAll(ctx, "profile", &got))
+	if got != (profile{Name: "alice", Score: 10, Active: true}) {
+		t.Errorf("HGetAll = %+v", got)
+	}
+	if n, err := c.HIncrBy(ctx, "profile", "score", 5); err != nil || n != 15 {
+		t.Errorf("HIncrBy = %d, %v", n, err)
+	}
+	var name string
+	mustNoErr(t, c.HGet(ctx, "profile", "name", &name))
+	if name != "alice" {
+		t.Errorf("HGet = %q", name)
+	}
+	if err := c.HGet(ctx, "profile", "missing", &name); !errors.Is(err, redis.ErrKeyNotFound) {
+		t.Errorf("HGet missing field: expected ErrKeyNotFound, got %v", err)
+	}
+
+	mustNoErr(t, c.HDel(ctx, "profile", "name", "score", "active"))
+	if err := c.HGetAll(ctx, "profile", &got); !errors.Is(err, redis.ErrKeyNotFound) {
+		t.Errorf("HGetAll emptied hash: expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func testSortedSets(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.ZAdd(ctx, "board", []redis.ZMember{
+		{Member: "a", Score: 1}, {Member: "b", Score: 2}, {Member: "c", Score: 3}, {Member: "d", Score: 4},
+	}))
+	if score, err := c.ZIncrBy(ctx, "board", "a", 10); err != nil || score != 11 {
+		t.Errorf("ZIncrBy = %v, %v", score, err)
+	}
+
+	top, err := c.ZRevRange(ctx, "board", 0, 1)
+	mustNoErr(t, err)
+	if len(top) != 2 || top[0].Member != "a" || top[1].Member != "d" {
+		t.Errorf("ZRevRange = %v", top)
+	}
+	all, err := c.ZRange(ctx, "board", 0, -1)
+	mustNoErr(t, err)
+	if len(all) != 4 || all[0].Member != "b" {
+		t.Errorf("ZRange = %v", all)
+	}
+
+	page, err := c.ZRangeByScore(ctx, "board", redis.ScoreRange{Min: 2, Max: math.Inf(1), Exclusive: true, Offset: 1, Count: 1})
+	mustNoErr(t, err)
+	if len(page) != 1 || page[0].Member != "d" {
+		t.Errorf("ZRangeByScore = %v", page)
+	}
+
+	if rank, err := c.ZRevRank(ctx, "board", "a"); err != nil || rank != 0 {
+		t.Errorf("ZRevRank = %d, %v", rank, err)
+	}
+	if _, err := c.ZRank(ctx, "board", "zz"); !errors.Is(err, redis.ErrKeyNotFound) {
+		t.Errorf("ZRank missing: expected ErrKeyNotFound, got %v", err)
+	}
+
+	if removed, err := c.ZTrimTop(ctx, "board", 2); err != nil || removed != 2 {
+		t.Errorf("ZTrimTop = %d, %v", removed, err)
+	}
+	mustNoErr(t, c.ZRem(ctx, "board", "a", "d"))
+	if ok, _ := c.Exists(ctx, "board"); ok {
+		t.Error("empty sorted set should be deleted")
+	}
+}
+
+func testTags(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	mustNoErr(t, c.Set(ctx, "view:1", "x", redis.WithTags("product:1")))
+	mustNoErr(t, c.SetMany(ctx, []redis.SetItem{{Key: "view:2", Value: "y"}}, redis.WithTags("product:1")))
+	mustNoErr(t, c.Set(ctx, "view:3", "z", redis.WithTags("product:3")))
+	if err := c.Set(ctx, "view:4", "z", redis.WithTags("")); err == nil {
+		t.Error("expected error for an empty tag")
+	}
+
+	mustNoErr(t, c.InvalidateTags(ctx, "product:1"))
+	exists, _ := c.ExistsMany(ctx, []string{"view:1", "view:2", "view:3"})
+	if !slices.Equal(exists, []bool{false, false, true}) {
+		t.Errorf("after InvalidateTags exists = %v", exists)
+	}
+}
+
+func testScan(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	for _, k := range []string{"user:1:perms", "user:2:perms", "user:2:name", "order:1"} {
+		mustNoErr(t, c.Set(ctx, k, "v"))
+	}
+	var keys []string
+	for k, err := range c.Scan(ctx, "user:*:perms") {
+		mustNoErr(t, err)
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	if !slices.Equal(keys, []string{"user:1:perms", "user:2:perms"}) {
+		t.Errorf("Scan = %v", keys)
+	}
+
+	n, err := c.DeleteByPattern(ctx, "user:*")
+	mustNoErr(t, err)
+	if n != 3 {
+		t.Errorf("DeleteByPattern = %d, want 3", n)
+	}
+	if ok, _ := c.Exists(ctx, "order:1"); !ok {
+		t.Error("non-matching key was deleted")
+	}
+}
+
+func testPubSub(t *testing.T, h Harness) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := h.Cache
+
+	sub := c.Subscribe(ctx, "events")
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatalf("Receive subscription: %v", err)
+	}
+	mustNoErr(t, c.Publish(ctx, "other", "ignored"))
+	mustNoErr(t, c.Publish(ctx, "events", profile{Name: "alice"}))
+	msg, err := sub.ReceiveMessage(ctx)
+	if err != nil {
+		t.Fatalf("ReceiveMessage: %v", err)
+	}
+	if msg.Channel != "events" || msg.Payload != `{"name":"alice","score":0,"active":false}` {
+		t.Errorf("message = %s %s", msg.Channel, msg.Payload)
+	}
+}
+
+func testLock(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	l, err := c.Lock(ctx, "lock:job", 10*time.Second)
+	mustNoErr(t, err)
+	if _, err := c.Lock(ctx, "lock:job", 10*time.Second); !errors.Is(err, redis.ErrLockNotAcquired) {
+		t.Errorf("second Lock: expected ErrLockNotAcquired, got %v", err)
+	}
+	mustNoErr(t, l.Refresh(ctx, 20*time.Second))
+
+	h.Advance(21 * time.Second)
+	other, err := c.Lock(ctx, "lock:job", 10*time.Second)
+	mustNoErr(t, err)
+	if err := l.Unlock(ctx); !errors.Is(err, redis.ErrLockNotHeld) {
+		t.Errorf("stale Unlock: expected ErrLockNotHeld, got %v", err)
+	}
+	mustNoErr(t, other.Unlock(ctx))
+}
+
+func testSemaphore(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	a, err := c.AcquireSemaphore(ctx, "sem:job", 2, 10*time.Second)
+	mustNoErr(t, err)
+	_, err = c.AcquireSemaphore(ctx, "sem:job", 2, 10*time.Second)
+	mustNoErr(t, err)
+	if _, err := c.AcquireSemaphore(ctx, "sem:job", 2, 10*time.Second); !errors.Is(err, redis.ErrLockNotAcquired) {
+		t.Errorf("third permit: expected ErrLockNotAcquired, got %v", err)
+	}
+	mustNoErr(t, a.Unlock(ctx))
+	b, err := c.AcquireSemaphore(ctx, "sem:job", 2, 10*time.Second)
+	mustNoErr(t, err)
+
+	h.Advance(5 * time.Second)
+	mustNoErr(t, b.Refresh(ctx, 20*time.Second))
+	h.Advance(6 * time.Second) // the unrefreshed permit expired
+	c2, err := c.AcquireSemaphore(ctx, "sem:job", 2, 10*time.Second)
+	mustNoErr(t, err)
+	if _, err := c.AcquireSemaphore(ctx, "sem:job", 2, 10*time.Second); !errors.Is(err, redis.ErrLockNotAcquired) {
+		t.Errorf("refreshed permit should still count, got %v", err)
+	}
+	mustNoErr(t, b.Unlock(ctx))
+	mustNoErr(t, c2.Unlock(ctx))
+}
+
+func testAllow(t *testing.T, h Harness) {
+	ctx := context.Background()
+	c := h.Cache
+
+	limit := redis.PerMinute(2)
+	for i := range 2 {
+		res, err := c.Allow(ctx, "rl", limit)
+		mustNoErr(t, err)
+		if !res.Allowed || res.Remaining != 1-i {
+			t.Errorf("request %d = %+v", i, res)
+		}
+	}
+	res, err := c.Allow(ctx, "rl", limit)
+	mustNoErr(t, err)
+	if res.Allowed || res.RetryAfter <= 0 {
+		t.Errorf("third request = %+v, want denied with RetryAfter", res)
+	}
+
+	h.Advance(time.Minute + time.Second)
+	res, err = c.Allow(ctx, "rl", limit)
+	mustNoErr(t, err)
+	if !res.Allowed {
+		t.Errorf("after window = %+v, want allowed", res)
+	}
+}
+
+func mustNoErr(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
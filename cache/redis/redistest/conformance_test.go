@@ -0,0 +1,42 @@
+package redistest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/juanMaAV92/go-utils/cache/redis"
+	"github.com/juanMaAV92/go-utils/cache/redis/redistest"
+)
+
+func TestConformance_Memory(t *testing.T) {
+	redistest.RunConformance(t, func(t *testing.T) redistest.Harness {
+		c, clock := redistest.NewCache(t)
+		return redistest.Harness{Cache: c, Advance: clock.Advance}
+	})
+}
+
+// TestConformance_Redis runs the suite against the Redis-backed Cache so the
+// memory cache is always checked against the same expectations.
+func TestConformance_Redis(t *testing.T) {
+	redistest.RunConformance(t, func(t *testing.T) redistest.Harness {
+		mr := miniredis.RunT(t)
+		c, err := redis.New(context.Background(), redis.Config{
+			Host: mr.Host(), Port: mr.Port(), KeyPrefix: "conformance",
+		}, nil)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		t.Cleanup(func() { _ = c.Close() })
+
+		now := time.Now()
+		mr.SetTime(now)
+		return redistest.Harness{Cache: c, Advance: func(d time.Duration) {
+			// TTLs follow FastForward, rate limits read the server TIME.
+			now = now.Add(d)
+			mr.SetTime(now)
+			mr.FastForward(d)
+		}}
+	})
+}
@@ -0,0 +1,854 @@
+package redistest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/cache/redis"
+	"github.com/juanMaAV92/go-utils/cache/redis/internal/backend"
+	goredis "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+type memKind uint8
+
+const (
+	kindString memKind = iota
+	kindSet
+	kindHash
+	kindZSet
+)
+
+// memEntry is one key of the memory cache. Only the field matching kind is used.
+type memEntry struct {
+	kind      memKind
+	str       string
+	members   map[string]struct{}
+	fields    map[string]string
+	scores    map[string]float64
+	expiresAt time.Time // zero = no expiry
+}
+
+var (
+	errWrongType  = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errNotInteger = errors.New("ERR value is not an integer or out of range")
+)
+
+// memoryCache is a Cache kept in a map guarded by one mutex. Values are encoded
+// exactly as the Redis cache stores them, so decoding behaves identically.
+type memoryCache struct {
+	mu      sync.Mutex
+	data    map[string]*memEntry
+	now     func() time.Time
+	encoder backend.Encoder
+	group   singleflight.Group
+	broker  *memoryBroker
+}
+
+// newMemory creates a Cache held in process memory. It follows Redis semantics —
+// TTLs (against now, so tests can move the clock), NX/XX/KEEPTTL, GETDEL, type
+// errors, sets, hashes, sorted sets, tags, locks, semaphores and rate limits —
+// without a server.
+//
+// Pub/Sub is local: Subscribe returns a *goredis.PubSub connected to an in-process
+// broker that receives everything Publish sends through this Cache.
+// GetOrSet treats WithRecomputeLock like WithSingleflight and ignores WithEarlyRefresh.
+func newMemory(now func() time.Time) redis.Cache {
+	return &memoryCache{
+		data:    make(map[string]*memEntry),
+		now:     now,
+		encoder: backend.NewEncoder(nil),
+		broker:  newMemoryBroker(),
+	}
+}
+
+// --- Key-value ---
+
+func (m *memoryCache) Set(ctx context.Context, key string, value any, opts ...redis.SetOption) error {
+	if err := backend.ValidateKV(ctx, key, value); err != nil {
+		return err
+	}
+	o := &backend.SetOptions{TTL: backend.DefaultTTL}
+	for _, opt := range opts {
+		opt(o)
+	}
+	payload, err := m.encoder.Encode(value, o.Codec)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.write(key, payload, o)
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string, dest any, opts ...redis.GetOption) error {
+	if err := backend.ValidateDest(ctx, key, dest); err != nil {
+		return err
+	}
+	o := &backend.GetOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	m.mu.Lock()
+	val, ok, err := m.getString(key)
+	if ok && o.RemainingTTL != nil {
+		*o.RemainingTTL = m.remaining(key)
+	}
+	if ok && o.DeleteAfterGet {
+		delete(m.data, key)
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return redis.ErrKeyNotFound
+	}
+	return m.encoder.Decode(val, dest)
+}
+
+func (m *memoryCache) GetOrSet(ctx context.Context, key string, dest any, fn func() (any, error), opts ...redis.SetOption) error {
+	if err := backend.ValidateDest(ctx, key, dest); err != nil {
+		return err
+	}
+	o := &backend.SetOptions{TTL: backend.DefaultTTL}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	m.mu.Lock()
+	val, ok, err := m.getString(key)
+	if o.RemainingTTL != nil {
+		*o.RemainingTTL = 0
+		if ok {
+			*o.RemainingTTL = m.remaining(key)
+		}
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if ok {
+		return m.encoder.Decode(val, dest)
+	}
+
+	load := func() (any, error) {
+		value, err := fn()
+		if err != nil {
+			return m.producerFailed(key, o, err)
+		}
+		if value == nil {
+			return nil, errors.New("redis: value is required")
+		}
+		payload, err := m.encoder.Encode(value, o.Codec)
+		if err != nil {
+			return nil, err
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if err := m.write(key, payload, o); err != nil {
+			return nil, err
+		}
+		if o.StaleWindow > 0 {
+			m.data[key+backend.StaleCopySuffix] = &memEntry{kind: kindString, str: payload, expiresAt: m.expiresAt(backend.StaleCopyTTL(o))}
+		}
+		if o.RemainingTTL != nil {
+			*o.RemainingTTL = backend.WrittenTTL(o)
+		}
+		return payload, nil
+	}
+
+	var payload any
+	if o.Singleflight || o.RecomputeLockTTL > 0 {
+		payload, err, _ = m.group.Do(key, load)
+	} else {
+		payload, err = load()
+	}
+	if err != nil {
+		return err
+	}
+	return m.encoder.Decode(payload.(string), dest)
+}
+
+// producerFailed mirrors the Redis cache: negative caching and stale fallback.
+func (m *memoryCache) producerFailed(key string, o *backend.SetOptions, err error) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if o.NegativeTTL > 0 && errors.Is(err, redis.ErrKeyNotFound) {
+		if m.lookup(key) == nil {
+			m.data[key] = &memEntry{kind: kindString, str: backend.AbsentMarker, expiresAt: m.expiresAt(o.NegativeTTL)}
+		}
+		return nil, redis.ErrKeyNotFound
+	}
+	if o.StaleWindow > 0 && !errors.Is(err, redis.ErrKeyNotFound) {
+		if val, ok, getErr := m.getString(key + backend.StaleCopySuffix); getErr == nil && ok {
+			return val, nil
+		}
+	}
+	return nil, fmt.Errorf("redis: GetOrSet producer failed: %w", err)
+}
+
+func (m *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range keys {
+		m.deleteValue(k)
+	}
+	return nil
+}
+
+func (m *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lookup(key) != nil, nil
+}
+
+func (m *memoryCache) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.typed(key, kindString)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	if e != nil {
+		if n, err = strconv.ParseInt(e.str, 10, 64); err != nil {
+			return 0, errNotInteger
+		}
+	} else {
+		e = &memEntry{kind: kindString}
+		m.data[key] = e
+	}
+	if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+		return 0, errors.New("ERR increment or decrement would overflow")
+	}
+	n += delta
+	e.str = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+// --- Batch ---
+
+func (m *memoryCache) GetMany(ctx context.Context, keys []string, dest any) ([]bool, error) {
+	if ctx == nil {
+		return nil, errors.New("redis: context is required")
+	}
+	target, err := backend.BatchTarget(dest, len(keys))
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]*string, len(keys))
+	m.mu.Lock()
+	for i, k := range keys {
+		if k == "" {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("redis: key at index %d is empty", i)
+		}
+		if e := m.lookup(k); e != nil && e.kind == kindString {
+			val := e.str
+			vals[i] = &val
+		}
+	}
+	m.mu.Unlock()
+
+	found := make([]bool, len(keys))
+	for i, val := range vals {
+		if val == nil || *val == backend.AbsentMarker {
+			continue
+		}
+		if err := m.encoder.SetBatchElem(target, keys, i, *val); err != nil {
+			return nil, err
+		}
+		found[i] = true
+	}
+	return found, nil
+}
+
+func (m *memoryCache) SetMany(ctx context.Context, items []redis.SetItem, opts ...redis.SetOption) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	o := &backend.SetOptions{TTL: backend.DefaultTTL}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.IfNotExist || o.IfExist {
+		return errors.New("redis: SetMany does not support redis.WithNX or redis.WithXX")
+	}
+
+	payloads := make([]string, len(items))
+	for i, it := range items {
+		if err := backend.ValidateKV(ctx, it.Key, it.Value); err != nil {
+			return fmt.Errorf("item at index %d: %w", i, err)
+		}
+		payload, err := m.encoder.Encode(it.Value, o.Codec)
+		if err != nil {
+			return fmt.Errorf("redis: key %q: %w", it.Key, err)
+		}
+		payloads[i] = payload
+	}
+	if err := backend.ValidateTags(o.Tags); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, it := range items {
+		itemOpts := *o
+		if it.TTL > 0 {
+			itemOpts.TTL, itemOpts.KeepTTL = it.TTL, false
+		}
+		if err := m.write(it.Key, payloads[i], &itemOpts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryCache) ExistsMany(ctx context.Context, keys []string) ([]bool, error) {
+	if ctx == nil {
+		return nil, errors.New("redis: context is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]bool, len(keys))
+	for i, k := range keys {
+		if k == "" {
+			return nil, fmt.Errorf("redis: key at index %d is empty", i)
+		}
+		out[i] = m.lookup(k) != nil
+	}
+	return out, nil
+}
+
+func (m *memoryCache) TTLMany(ctx context.Context, keys []string) ([]time.Duration, error) {
+	if ctx == nil {
+		return nil, errors.New("redis: context is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]time.Duration, len(keys))
+	for i, k := range keys {
+		if k == "" {
+			return nil, fmt.Errorf("redis: key at index %d is empty", i)
+		}
+		out[i] = m.ttl(k)
+	}
+	return out, nil
+}
+
+// --- Tags ---
+
+func (m *memoryCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	if err := backend.ValidateTags(tags); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tag := range tags {
+		tagKey := backend.TagKeyPrefix + tag
+		if e := m.lookup(tagKey); e != nil && e.kind == kindSet {
+			for k := range e.members {
+				m.deleteValue(k)
+			}
+		}
+		delete(m.data, tagKey)
+	}
+	return nil
+}
+
+// --- Key scanning ---
+
+func (m *memoryCache) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if err := backend.ValidatePattern(ctx, pattern); err != nil {
+			yield("", err)
+			return
+		}
+		for _, k := range m.match(pattern) {
+			if !yield(k, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (m *memoryCache) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	if err := backend.ValidatePattern(ctx, pattern); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for k := range m.data {
+		if m.lookup(k) != nil && backend.MatchGlob(pattern, k) {
+			m.deleteValue(k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// deleteValue removes key and its GetOrSet sidecar keys. Callers hold m.mu.
+func (m *memoryCache) deleteValue(key string) {
+	delete(m.data, key)
+	for _, k := range backend.SidecarKeys([]string{key}) {
+		delete(m.data, k)
+	}
+}
+
+// match returns the live keys matching pattern, sorted.
+func (m *memoryCache) match(pattern string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for k := range m.data {
+		if m.lookup(k) != nil && backend.MatchGlob(pattern, k) {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// --- TTL management ---
+
+func (m *memoryCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire(key, ttl)
+	return nil
+}
+
+func (m *memoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ttl(key), nil
+}
+
+// --- Pub/Sub ---
+
+func (m *memoryCache) Publish(ctx context.Context, channel string, message any) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	if channel == "" {
+		return errors.New("redis: channel is required")
+	}
+	payload, err := backend.Serialize(message)
+	if err != nil {
+		return err
+	}
+	m.broker.publish(channel, backend.FormatArg(payload))
+	return nil
+}
+
+func (m *memoryCache) Subscribe(ctx context.Context, channels ...string) *goredis.PubSub {
+	return m.broker.client.Subscribe(ctx, channels...)
+}
+
+// --- Distributed locks ---
+
+func (m *memoryCache) Lock(ctx context.Context, key string, ttl time.Duration, opts ...redis.LockOption) (redis.Lock, error) {
+	l, err := backend.AcquireLock(ctx, m, key, ttl, backendLockOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return l.(redis.Lock), nil
+}
+
+func (m *memoryCache) TryLock(_ context.Context, key, token string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lookup(key) != nil {
+		return false, nil
+	}
+	m.data[key] = &memEntry{kind: kindString, str: token, expiresAt: m.expiresAt(ttl)}
+	return true, nil
+}
+
+func (m *memoryCache) RefreshLock(_ context.Context, key, token string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.lookup(key)
+	if e == nil || e.kind != kindString || e.str != token {
+		return false, nil
+	}
+	e.expiresAt = m.expiresAt(ttl)
+	return true, nil
+}
+
+func (m *memoryCache) ReleaseLock(_ context.Context, key, token string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.lookup(key)
+	if e == nil || e.kind != kindString || e.str != token {
+		return false, nil
+	}
+	delete(m.data, key)
+	return true, nil
+}
+
+func (m *memoryCache) LogErr(_ context.Context, _ string, err error) error {
+	return err
+}
+
+// backendLockOptions converts opts for backend.AcquireLock.
+func backendLockOptions(opts []redis.LockOption) []func(*backend.LockOptions) {
+	out := make([]func(*backend.LockOptions), len(opts))
+	for i, opt := range opts {
+		out[i] = opt
+	}
+	return out
+}
+
+// --- Semaphores ---
+
+func (m *memoryCache) AcquireSemaphore(ctx context.Context, key string, limit int, ttl time.Duration, opts ...redis.LockOption) (redis.Lock, error) {
+	l, err := backend.AcquireSemaphore(ctx, m, key, limit, ttl, backendLockOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return l.(redis.Lock), nil
+}
+
+// TryAcquire mirrors the Redis semaphore script: members scored by lease expiry (ms).
+func (m *memoryCache) TryAcquire(_ context.Context, key, token string, limit int, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.create(key, kindZSet)
+	if err != nil {
+		return false, err
+	}
+	nowMs := float64(m.now().UnixMilli())
+	for member, expiry := range e.scores {
+		if expiry <= nowMs {
+			delete(e.scores, member)
+		}
+	}
+	if len(e.scores) >= limit {
+		return false, nil
+	}
+	e.scores[token] = nowMs + float64(ttl.Milliseconds())
+	m.extendPermitKey(e, ttl)
+	return true, nil
+}
+
+func (m *memoryCache) RefreshPermit(_ context.Context, key, token string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.typed(key, kindZSet)
+	if err != nil || e == nil {
+		return false, err
+	}
+	nowMs := float64(m.now().UnixMilli())
+	if expiry, ok := e.scores[token]; !ok || expiry <= nowMs {
+		return false, nil
+	}
+	e.scores[token] = nowMs + float64(ttl.Milliseconds())
+	m.extendPermitKey(e, ttl)
+	return true, nil
+}
+
+func (m *memoryCache) ReleasePermit(_ context.Context, key, token string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.typed(key, kindZSet)
+	if err != nil || e == nil {
+		return false, err
+	}
+	expiry, ok := e.scores[token]
+	delete(e.scores, token)
+	if len(e.scores) == 0 {
+		delete(m.data, key)
+	}
+	return ok && expiry > float64(m.now().UnixMilli()), nil
+}
+
+// extendPermitKey keeps a semaphore key alive at least ttl from now.
+func (m *memoryCache) extendPermitKey(e *memEntry, ttl time.Duration) {
+	if at := m.expiresAt(ttl); e.expiresAt.Before(at) {
+		e.expiresAt = at
+	}
+}
+
+// --- Rate limiting ---
+
+// Allow mirrors the Redis rate limit scripts, reading time from the injected clock.
+func (m *memoryCache) Allow(ctx context.Context, key string, limit redis.RateLimit) (redis.RateLimitResult, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return redis.RateLimitResult{}, err
+	}
+	if limit.Limit <= 0 || limit.Period < time.Millisecond {
+		return redis.RateLimitResult{}, errors.New("redis: rate limit requires Limit > 0 and Period >= 1ms")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch limit.Algorithm {
+	case redis.SlidingWindow:
+		return m.slidingWindow(key, limit)
+	case redis.TokenBucket:
+		return m.tokenBucket(key, limit)
+	default:
+		return redis.RateLimitResult{}, fmt.Errorf("redis: unknown rate limit algorithm %d", limit.Algorithm)
+	}
+}
+
+func (m *memoryCache) slidingWindow(key string, limit redis.RateLimit) (redis.RateLimitResult, error) {
+	e, err := m.create(key, kindZSet)
+	if err != nil {
+		return redis.RateLimitResult{}, err
+	}
+	now := m.now()
+	nowMs := float64(now.UnixMilli())
+	window := limit.Period.Milliseconds()
+	for member, score := range e.scores {
+		if score <= nowMs-float64(window) {
+			delete(e.scores, member)
+		}
+	}
+
+	count := len(e.scores)
+	if count < limit.Limit {
+		member, err := backend.NewToken()
+		if err != nil {
+			return redis.RateLimitResult{}, err
+		}
+		e.scores[member] = nowMs
+		e.expiresAt = now.Add(limit.Period)
+		return redis.RateLimitResult{
+			Allowed:    true,
+			Limit:      limit.Limit,
+			Remaining:  limit.Limit - count - 1,
+			ResetAfter: limit.Period,
+		}, nil
+	}
+
+	members := sortedMembers(e)
+	oldest, newest := members[0].Score, members[len(members)-1].Score
+	return redis.RateLimitResult{
+		Limit:      limit.Limit,
+		RetryAfter: time.Duration(oldest+float64(window)-nowMs) * time.Millisecond,
+		ResetAfter: time.Duration(newest+float64(window)-nowMs) * time.Millisecond,
+	}, nil
+}
+
+func (m *memoryCache) tokenBucket(key string, limit redis.RateLimit) (redis.RateLimitResult, error) {
+	e, err := m.create(key, kindHash)
+	if err != nil {
+		return redis.RateLimitResult{}, err
+	}
+	burst := limit.Limit
+	if limit.Burst > 0 {
+		burst = limit.Burst
+	}
+	now := m.now()
+	nowMs := float64(now.UnixMicro()) / 1000
+	rate := float64(limit.Limit) / (float64(limit.Period) / float64(time.Millisecond))
+
+	tokens, tokensErr := strconv.ParseFloat(e.fields["tokens"], 64)
+	ts, tsErr := strconv.ParseFloat(e.fields["ts"], 64)
+	if tokensErr != nil || tsErr != nil {
+		tokens, ts = float64(burst), nowMs
+	}
+	tokens = math.Min(float64(burst), tokens+math.Max(0, nowMs-ts)*rate)
+
+	res := redis.RateLimitResult{Limit: burst}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1-tokens)/rate)) * time.Millisecond
+	}
+	reset := math.Ceil((float64(burst) - tokens) / rate)
+	res.Remaining = int(math.Floor(tokens))
+	res.ResetAfter = time.Duration(reset) * time.Millisecond
+
+	e.fields["tokens"] = strconv.FormatFloat(tokens, 'g', -1, 64)
+	e.fields["ts"] = strconv.FormatFloat(nowMs, 'g', -1, 64)
+	e.expiresAt = now.Add(time.Duration(math.Max(reset, 1)) * time.Millisecond)
+	return res, nil
+}
+
+// --- Lifecycle ---
+
+// Close shuts down the Pub/Sub broker. Stored data stays readable.
+func (m *memoryCache) Close() error {
+	return m.broker.close()
+}
+
+// --- internal helpers (callers hold m.mu) ---
+
+// lookup returns the live entry at key, dropping it if it has expired.
+func (m *memoryCache) lookup(key string) *memEntry {
+	e, ok := m.data[key]
+	if !ok {
+		return nil
+	}
+	if !e.expiresAt.IsZero() && !m.now().Before(e.expiresAt) {
+		delete(m.data, key)
+		return nil
+	}
+	return e
+}
+
+// typed returns the live entry at key if it holds kind, nil if key is missing,
+// and errWrongType otherwise.
+func (m *memoryCache) typed(key string, kind memKind) (*memEntry, error) {
+	e := m.lookup(key)
+	if e == nil {
+		return nil, nil
+	}
+	if e.kind != kind {
+		return nil, errWrongType
+	}
+	return e, nil
+}
+
+// getString returns the string value at key.
+func (m *memoryCache) getString(key string) (string, bool, error) {
+	e, err := m.typed(key, kindString)
+	if err != nil || e == nil {
+		return "", false, err
+	}
+	return e.str, true, nil
+}
+
+// create returns the entry at key, creating an empty one of kind if missing.
+func (m *memoryCache) create(key string, kind memKind) (*memEntry, error) {
+	e, err := m.typed(key, kind)
+	if err != nil || e != nil {
+		return e, err
+	}
+	e = &memEntry{kind: kind}
+	switch kind {
+	case kindSet:
+		e.members = make(map[string]struct{})
+	case kindHash:
+		e.fields = make(map[string]string)
+	case kindZSet:
+		e.scores = make(map[string]float64)
+	}
+	m.data[key] = e
+	return e, nil
+}
+
+// write stores payload at key like SET with NX/XX/KEEPTTL, then records key in its tag sets.
+func (m *memoryCache) write(key, payload string, o *backend.SetOptions) error {
+	if o.IfNotExist && o.IfExist {
+		return errors.New("redis: redis.WithNX and redis.WithXX are mutually exclusive")
+	}
+	if err := backend.ValidateTags(o.Tags); err != nil {
+		return err
+	}
+	existing := m.lookup(key)
+	if (o.IfNotExist && existing != nil) || (o.IfExist && existing == nil) {
+		return redis.ErrKeyNotSet
+	}
+
+	e := &memEntry{kind: kindString, str: payload}
+	switch {
+	case o.KeepTTL:
+		if existing != nil {
+			e.expiresAt = existing.expiresAt
+		}
+	default:
+		e.expiresAt = m.expiresAt(o.TTL)
+	}
+	m.data[key] = e
+
+	// Same bookkeeping as the Redis cache: a tag set lives as long as its longest-lived member.
+	for _, tag := range o.Tags {
+		tagKey := backend.TagKeyPrefix + tag
+		existed := m.lookup(tagKey) != nil
+		set, err := m.create(tagKey, kindSet)
+		if err != nil {
+			return err
+		}
+		set.members[key] = struct{}{}
+		switch {
+		case e.expiresAt.IsZero():
+			set.expiresAt = time.Time{}
+		case !existed || (!set.expiresAt.IsZero() && set.expiresAt.Before(e.expiresAt)):
+			set.expiresAt = e.expiresAt
+		}
+	}
+	return nil
+}
+
+// expire applies EXPIRE semantics: whole seconds (at least 1s for a positive ttl),
+// and a non-positive ttl deletes the key.
+func (m *memoryCache) expire(key string, ttl time.Duration) {
+	e := m.lookup(key)
+	if e == nil {
+		return
+	}
+	if ttl > 0 && ttl < time.Second {
+		ttl = time.Second
+	}
+	ttl = ttl.Truncate(time.Second)
+	if ttl <= 0 {
+		delete(m.data, key)
+		return
+	}
+	e.expiresAt = m.now().Add(ttl)
+}
+
+// ttl follows the TTL command: seconds rounded to nearest, -1 without expiry, -2 when missing.
+func (m *memoryCache) ttl(key string) time.Duration {
+	e := m.lookup(key)
+	if e == nil {
+		return -2
+	}
+	if e.expiresAt.IsZero() {
+		return -1
+	}
+	remaining := e.expiresAt.Sub(m.now())
+	return (remaining + 500*time.Millisecond).Truncate(time.Second)
+}
+
+// remaining is the exact remaining TTL of key for getRemainingTTL.
+func (m *memoryCache) remaining(key string) time.Duration {
+	e := m.lookup(key)
+	switch {
+	case e == nil:
+		return 0
+	case e.expiresAt.IsZero():
+		return -1
+	}
+	return max(e.expiresAt.Sub(m.now()), 0)
+}
+
+func (m *memoryCache) expiresAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return m.now().Add(ttl)
+}
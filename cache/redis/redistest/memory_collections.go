@@ -0,0 +1,462 @@
+package redistest
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/cache/redis"
+	"github.com/juanMaAV92/go-utils/cache/redis/internal/backend"
+)
+
+// --- Sets ---
+
+func (m *memoryCache) AddToSet(ctx context.Context, key string, members []string, opts ...redis.SetOption) error {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return errors.New("redis: at least one member is required")
+	}
+	o := &backend.SetOptions{TTL: 0}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.create(key, kindSet)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		e.members[member] = struct{}{}
+	}
+	if o.TTL > 0 {
+		m.expire(key, o.TTL)
+	}
+	return nil
+}
+
+func (m *memoryCache) RemoveFromSet(ctx context.Context, key string, members []string) error {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return errors.New("redis: at least one member is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.typed(key, kindSet)
+	if err != nil || e == nil {
+		return err
+	}
+	for _, member := range members {
+		delete(e.members, member)
+	}
+	if len(e.members) == 0 {
+		delete(m.data, key)
+	}
+	return nil
+}
+
+// GetSetMembers returns the members sorted; Redis returns them in no particular order.
+func (m *memoryCache) GetSetMembers(ctx context.Context, key string) ([]string, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.typed(key, kindSet)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return []string{}, nil
+	}
+	return slices.Sorted(maps.Keys(e.members)), nil
+}
+
+func (m *memoryCache) ExistsInSet(ctx context.Context, key, member string) (bool, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return false, err
+	}
+	if member == "" {
+		return false, errors.New("redis: member is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.typed(key, kindSet)
+	if err != nil || e == nil {
+		return false, err
+	}
+	_, ok := e.members[member]
+	return ok, nil
+}
+
+// --- Hashes ---
+
+func (m *memoryCache) HSet(ctx context.Context, key string, values any, opts ...redis.SetOption) error {
+	if err := backend.ValidateKV(ctx, key, values); err != nil {
+		return err
+	}
+	pairs, err := m.encoder.HashPairs(values)
+	if err != nil {
+		return err
+	}
+	if len(pairs) == 0 {
+		return errors.New("redis: at least one field is required")
+	}
+	o := &backend.SetOptions{TTL: 0}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.create(key, kindHash)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		e.fields[pairs[i].(string)] = backend.FormatArg(pairs[i+1])
+	}
+	if o.TTL > 0 {
+		m.expire(key, o.TTL)
+	}
+	return nil
+}
+
+func (m *memoryCache) HGet(ctx context.Context, key, field string, dest any) error {
+	if err := backend.ValidateDest(ctx, key, dest); err != nil {
+		return err
+	}
+	if field == "" {
+		return errors.New("redis: field is required")
+	}
+	m.mu.Lock()
+	e, err := m.typed(key, kindHash)
+	var (
+		val string
+		ok  bool
+	)
+	if e != nil {
+		val, ok = e.fields[field]
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return redis.ErrKeyNotFound
+	}
+	return m.encoder.DecodeField(val, dest)
+}
+
+func (m *memoryCache) HGetAll(ctx context.Context, key string, dest any) error {
+	if err := backend.ValidateDest(ctx, key, dest); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	e, err := m.typed(key, kindHash)
+	var vals map[string]string
+	if e != nil {
+		vals = maps.Clone(e.fields)
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(vals) == 0 {
+		return redis.ErrKeyNotFound
+	}
+	return m.encoder.ScanHash(vals, dest)
+}
+
+func (m *memoryCache) HDel(ctx context.Context, key string, fields ...string) error {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return errors.New("redis: at least one field is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.typed(key, kindHash)
+	if err != nil || e == nil {
+		return err
+	}
+	for _, f := range fields {
+		delete(e.fields, f)
+	}
+	if len(e.fields) == 0 {
+		delete(m.data, key)
+	}
+	return nil
+}
+
+func (m *memoryCache) HIncrBy(ctx context.Context, key, field string, delta int64) (int64, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	if field == "" {
+		return 0, errors.New("redis: field is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.create(key, kindHash)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	if cur, ok := e.fields[field]; ok {
+		if n, err = strconv.ParseInt(cur, 10, 64); err != nil {
+			return 0, errors.New("ERR hash value is not an integer")
+		}
+	}
+	n += delta
+	e.fields[field] = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+// --- Sorted sets ---
+
+func (m *memoryCache) ZAdd(ctx context.Context, key string, members []redis.ZMember, opts ...redis.SetOption) error {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return errors.New("redis: at least one member is required")
+	}
+	o := &backend.SetOptions{TTL: 0}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.create(key, kindZSet)
+	if err != nil {
+		return err
+	}
+	for _, z := range members {
+		e.scores[z.Member] = z.Score
+	}
+	if o.TTL > 0 {
+		m.expire(key, o.TTL)
+	}
+	return nil
+}
+
+func (m *memoryCache) ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	if member == "" {
+		return 0, errors.New("redis: member is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.create(key, kindZSet)
+	if err != nil {
+		return 0, err
+	}
+	e.scores[member] += delta
+	return e.scores[member], nil
+}
+
+func (m *memoryCache) ZRange(ctx context.Context, key string, start, stop int64) ([]redis.ZMember, error) {
+	return m.zrange(ctx, key, start, stop, false)
+}
+
+func (m *memoryCache) ZRevRange(ctx context.Context, key string, start, stop int64) ([]redis.ZMember, error) {
+	return m.zrange(ctx, key, start, stop, true)
+}
+
+func (m *memoryCache) ZRangeByScore(ctx context.Context, key string, r redis.ScoreRange) ([]redis.ZMember, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return nil, err
+	}
+	if r.Offset < 0 || r.Count < 0 {
+		return nil, errors.New("redis: offset and count must not be negative")
+	}
+	members, err := m.zmembers(key, r.Reverse)
+	if err != nil {
+		return nil, err
+	}
+	out := []redis.ZMember{}
+	skipped := int64(0)
+	for _, z := range members {
+		if !inScoreRange(z.Score, r) {
+			continue
+		}
+		if skipped < r.Offset {
+			skipped++
+			continue
+		}
+		if r.Count > 0 && int64(len(out)) == r.Count {
+			break
+		}
+		out = append(out, z)
+	}
+	return out, nil
+}
+
+func (m *memoryCache) ZRank(ctx context.Context, key, member string) (int64, error) {
+	return m.zrank(ctx, key, member, false)
+}
+
+func (m *memoryCache) ZRevRank(ctx context.Context, key, member string) (int64, error) {
+	return m.zrank(ctx, key, member, true)
+}
+
+func (m *memoryCache) ZRem(ctx context.Context, key string, members ...string) error {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return errors.New("redis: at least one member is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := m.zremove(key, func(z redis.ZMember, _ int) bool { return slices.Contains(members, z.Member) })
+	return err
+}
+
+func (m *memoryCache) ZTrimTop(ctx context.Context, key string, n int64) (int64, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("redis: n must not be negative")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.typed(key, kindZSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	drop := int64(len(e.scores)) - n
+	return m.zremove(key, func(_ redis.ZMember, rank int) bool { return int64(rank) < drop })
+}
+
+func (m *memoryCache) ZTrimBefore(ctx context.Context, key string, cutoff time.Time) (int64, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	limit := redis.TimeScore(cutoff)
+	return m.zremove(key, func(z redis.ZMember, _ int) bool { return z.Score < limit })
+}
+
+func (m *memoryCache) zrange(ctx context.Context, key string, start, stop int64, reverse bool) ([]redis.ZMember, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return nil, err
+	}
+	members, err := m.zmembers(key, reverse)
+	if err != nil {
+		return nil, err
+	}
+	n := int64(len(members))
+	if start < 0 {
+		start = max(start+n, 0)
+	}
+	if stop < 0 {
+		stop += n
+	}
+	stop = min(stop, n-1)
+	if start > stop {
+		return []redis.ZMember{}, nil
+	}
+	return members[start : stop+1], nil
+}
+
+func (m *memoryCache) zrank(ctx context.Context, key, member string, reverse bool) (int64, error) {
+	if err := backend.ValidateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	if member == "" {
+		return 0, errors.New("redis: member is required")
+	}
+	members, err := m.zmembers(key, reverse)
+	if err != nil {
+		return 0, err
+	}
+	for i, z := range members {
+		if z.Member == member {
+			return int64(i), nil
+		}
+	}
+	return 0, redis.ErrKeyNotFound
+}
+
+// zmembers returns the members of the sorted set at key in rank order.
+func (m *memoryCache) zmembers(key string, reverse bool) ([]redis.ZMember, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, err := m.typed(key, kindZSet)
+	if err != nil || e == nil {
+		return nil, err
+	}
+	members := sortedMembers(e)
+	if reverse {
+		slices.Reverse(members)
+	}
+	return members, nil
+}
+
+// zremove deletes the members for which drop returns true (rank is ascending)
+// and deletes the key once it is empty. Callers hold m.mu.
+func (m *memoryCache) zremove(key string, drop func(z redis.ZMember, rank int) bool) (int64, error) {
+	e, err := m.typed(key, kindZSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	var removed int64
+	for rank, z := range sortedMembers(e) {
+		if drop(z, rank) {
+			delete(e.scores, z.Member)
+			removed++
+		}
+	}
+	if len(e.scores) == 0 {
+		delete(m.data, key)
+	}
+	return removed, nil
+}
+
+// sortedMembers orders members by score, then lexicographically, like Redis.
+func sortedMembers(e *memEntry) []redis.ZMember {
+	out := make([]redis.ZMember, 0, len(e.scores))
+	for member, score := range e.scores {
+		out = append(out, redis.ZMember{Member: member, Score: score})
+	}
+	slices.SortFunc(out, func(a, b redis.ZMember) int {
+		if a.Score != b.Score {
+			if a.Score < b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Member, b.Member)
+	})
+	return out
+}
+
+func inScoreRange(score float64, r redis.ScoreRange) bool {
+	exclusiveMin := r.Exclusive && !math.IsInf(r.Min, 0)
+	exclusiveMax := r.Exclusive && !math.IsInf(r.Max, 0)
+	if score < r.Min || (exclusiveMin && score == r.Min) {
+		return false
+	}
+	if score > r.Max || (exclusiveMax && score == r.Max) {
+		return false
+	}
+	return true
+}
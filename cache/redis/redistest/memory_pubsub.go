@@ -0,0 +1,301 @@
+package redistest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/juanMaAV92/go-utils/cache/redis/internal/backend"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// memoryBroker is the in-process Pub/Sub server behind the memory cache.
+// Cache.Subscribe returns a *goredis.PubSub, so subscribers get a real go-redis
+// client whose connections are net.Pipe ends served here. The broker speaks the
+// RESP2 subset a subscription uses: (P)SUBSCRIBE, (P)UNSUBSCRIBE and PING.
+type memoryBroker struct {
+	mu     sync.Mutex
+	conns  map[*brokerConn]struct{}
+	closed bool
+	client *goredis.Client
+}
+
+func newMemoryBroker() *memoryBroker {
+	b := &memoryBroker{conns: make(map[*brokerConn]struct{})}
+	b.client = goredis.NewClient(&goredis.Options{
+		Addr:            "memory",
+		Protocol:        2,
+		DisableIdentity: true,
+		Dialer: func(context.Context, string, string) (net.Conn, error) {
+			return b.dial()
+		},
+	})
+	return b
+}
+
+func (b *memoryBroker) dial() (net.Conn, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, goredis.ErrClosed
+	}
+	client, server := net.Pipe()
+	bc := &brokerConn{
+		conn:     server,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		channels: make(map[string]struct{}),
+		patterns: make(map[string]struct{}),
+	}
+	b.conns[bc] = struct{}{}
+	go bc.writeLoop()
+	go b.serve(bc)
+	return client, nil
+}
+
+// publish delivers payload to every matching subscription and returns the
+// number of receivers, like PUBLISH.
+func (b *memoryBroker) publish(channel, payload string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for bc := range b.conns {
+		n += bc.deliver(channel, payload)
+	}
+	return n
+}
+
+func (b *memoryBroker) close() error {
+	b.mu.Lock()
+	b.closed = true
+	conns := make([]*brokerConn, 0, len(b.conns))
+	for bc := range b.conns {
+		conns = append(conns, bc)
+	}
+	b.mu.Unlock()
+
+	err := b.client.Close()
+	for _, bc := range conns {
+		bc.close()
+	}
+	return err
+}
+
+// serve reads commands from one connection until it is closed.
+func (b *memoryBroker) serve(bc *brokerConn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, bc)
+		b.mu.Unlock()
+		bc.close()
+	}()
+	r := bufio.NewReader(bc.conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		bc.handle(args)
+		b.mu.Unlock()
+	}
+}
+
+// brokerConn is the server side of one subscriber connection. Replies are queued
+// and written by writeLoop so a slow reader never blocks publishers.
+type brokerConn struct {
+	conn     net.Conn
+	mu       sync.Mutex
+	queue    [][]byte
+	wake     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+	channels map[string]struct{} // guarded by memoryBroker.mu
+	patterns map[string]struct{} // guarded by memoryBroker.mu
+}
+
+func (bc *brokerConn) handle(args []string) {
+	if len(args) == 0 {
+		return
+	}
+	cmd, args := strings.ToLower(args[0]), args[1:]
+	switch cmd {
+	case "subscribe", "psubscribe":
+		set := bc.channels
+		if cmd == "psubscribe" {
+			set = bc.patterns
+		}
+		for _, name := range args {
+			set[name] = struct{}{}
+			bc.send(respArray(cmd, name, bc.count()))
+		}
+	case "unsubscribe", "punsubscribe":
+		set := bc.channels
+		if cmd == "punsubscribe" {
+			set = bc.patterns
+		}
+		if len(args) == 0 {
+			for name := range set {
+				args = append(args, name)
+			}
+		}
+		if len(args) == 0 {
+			bc.send(respArray(cmd, nil, bc.count()))
+		}
+		for _, name := range args {
+			delete(set, name)
+			bc.send(respArray(cmd, name, bc.count()))
+		}
+	case "ping":
+		msg := ""
+		if len(args) > 0 {
+			msg = args[0]
+		}
+		switch {
+		case bc.count() > 0:
+			bc.send(respArray("pong", msg))
+		case len(args) > 0:
+			bc.send(appendBulk(nil, msg))
+		default:
+			bc.send([]byte("+PONG\r\n"))
+		}
+	default:
+		bc.send([]byte(fmt.Sprintf("-ERR unknown command '%s'\r\n", cmd)))
+	}
+}
+
+func (bc *brokerConn) count() int {
+	return len(bc.channels) + len(bc.patterns)
+}
+
+func (bc *brokerConn) deliver(channel, payload string) int {
+	n := 0
+	if _, ok := bc.channels[channel]; ok {
+		bc.send(respArray("message", channel, payload))
+		n++
+	}
+	for pattern := range bc.patterns {
+		if backend.MatchGlob(pattern, channel) {
+			bc.send(respArray("pmessage", pattern, channel, payload))
+			n++
+		}
+	}
+	return n
+}
+
+func (bc *brokerConn) send(reply []byte) {
+	bc.mu.Lock()
+	bc.queue = append(bc.queue, reply)
+	bc.mu.Unlock()
+	select {
+	case bc.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (bc *brokerConn) writeLoop() {
+	for {
+		select {
+		case <-bc.done:
+			return
+		case <-bc.wake:
+		}
+		bc.mu.Lock()
+		queue := bc.queue
+		bc.queue = nil
+		bc.mu.Unlock()
+		for _, reply := range queue {
+			if _, err := bc.conn.Write(reply); err != nil {
+				bc.close()
+				return
+			}
+		}
+	}
+}
+
+func (bc *brokerConn) close() {
+	bc.once.Do(func() {
+		close(bc.done)
+		_ = bc.conn.Close()
+	})
+}
+
+// --- RESP2 ---
+
+// readCommand reads one command sent by go-redis: an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("redis: unexpected RESP line %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for range n {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("redis: unexpected RESP line %q", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(line, "\r\n") {
+		return "", errors.New("redis: malformed RESP line")
+	}
+	return line[:len(line)-2], nil
+}
+
+// respArray encodes strings as bulk strings, ints as integers and nil as a null bulk string.
+func respArray(items ...any) []byte {
+	b := []byte("*" + strconv.Itoa(len(items)) + "\r\n")
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			b = appendBulk(b, v)
+		case int:
+			b = append(b, ':')
+			b = strconv.AppendInt(b, int64(v), 10)
+			b = append(b, "\r\n"...)
+		default:
+			b = append(b, "$-1\r\n"...)
+		}
+	}
+	return b
+}
+
+func appendBulk(b []byte, s string) []byte {
+	b = append(b, '$')
+	b = strconv.AppendInt(b, int64(len(s)), 10)
+	b = append(b, "\r\n"...)
+	b = append(b, s...)
+	return append(b, "\r\n"...)
+}
@@ -0,0 +1,121 @@
+package redistest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/cache/redis"
+)
+
+var ctx = context.Background()
+
+func newTestMemory(t *testing.T) redis.Cache {
+	t.Helper()
+	m := newMemory(time.Now)
+	t.Cleanup(func() { _ = m.Close() })
+	return m
+}
+
+// eventually polls cond until it holds or one second elapses.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMemory_PatternSubscribe(t *testing.T) {
+	m := newTestMemory(t)
+
+	sub := m.Subscribe(ctx)
+	defer sub.Close()
+	if err := sub.PSubscribe(ctx, "orders.*"); err != nil {
+		t.Fatalf("PSubscribe: %v", err)
+	}
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if err := m.Publish(ctx, "orders.created", "42"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	msg, err := sub.ReceiveMessage(ctx)
+	if err != nil {
+		t.Fatalf("ReceiveMessage: %v", err)
+	}
+	if msg.Pattern != "orders.*" || msg.Channel != "orders.created" || msg.Payload != "42" {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestMemory_NearCacheInvalidation(t *testing.T) {
+	m := newTestMemory(t)
+	newReplica := func() redis.NearCache {
+		n, err := redis.NewNearCache(ctx, m, redis.NearCacheConfig{}, nil)
+		if err != nil {
+			t.Fatalf("NewNearCache: %v", err)
+		}
+		t.Cleanup(func() { _ = n.Close() })
+		return n
+	}
+	a, b := newReplica(), newReplica()
+
+	_ = a.Set(ctx, "k", "v1")
+	var got string
+	eventually(t, func() bool {
+		_ = b.Get(ctx, "k", &got)
+		return b.Stats().Entries == 1
+	})
+	_ = a.Set(ctx, "k", "v2")
+	eventually(t, func() bool { return b.Stats().Entries == 0 })
+	_ = b.Get(ctx, "k", &got)
+	if got != "v2" {
+		t.Errorf("got %q, want v2", got)
+	}
+}
+
+func TestMemory_ExpireNonPositiveDeletes(t *testing.T) {
+	m := newTestMemory(t)
+
+	_ = m.Set(ctx, "k", "v", redis.WithTTL(time.Minute))
+	if err := m.Expire(ctx, "k", 0); err != nil {
+		t.Fatalf("Expire: %v", err)
+	}
+	if ok, _ := m.Exists(ctx, "k"); ok {
+		t.Error("Expire(0) must delete the key")
+	}
+}
+
+func TestMemory_DeleteRemovesSidecars(t *testing.T) {
+	failing := func() (any, error) { return nil, errors.New("db down") }
+	tests := []struct {
+		name   string
+		delete func(c redis.Cache) error
+	}{
+		{"Delete", func(c redis.Cache) error { return c.Delete(ctx, "k") }},
+		{"InvalidateTags", func(c redis.Cache) error { return c.InvalidateTags(ctx, "t") }},
+		{"DeleteByPattern", func(c redis.Cache) error {
+			_, err := c.DeleteByPattern(ctx, "k")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMemory(t)
+			var got string
+			_ = m.GetOrSet(ctx, "k", &got, func() (any, error) { return "v1", nil },
+				redis.WithTTL(time.Minute), redis.WithStaleOnError(time.Hour), redis.WithTags("t"))
+			if err := tt.delete(m); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.GetOrSet(ctx, "k", &got, failing, redis.WithStaleOnError(time.Hour)); err == nil {
+				t.Errorf("deleted value served as stale %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Package redistest provides helpers for testing code that depends on redis.Cache:
+// an in-memory Cache driven by a manual Clock, and a conformance suite that any
+// Cache implementation (or decorator) can run to check it behaves like Redis.
+package redistest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/cache/redis"
+)
+
+// Clock is a manually advanced clock for the caches returned by NewCache.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a Clock stopped at start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the current fake time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// NewCache returns an in-memory redis.Cache whose TTLs follow the returned Clock.
+// It follows Redis semantics without a server; Pub/Sub is local to the cache.
+// The cache is closed when the test ends.
+//
+//	cache, clock := redistest.NewCache(t)
+//	svc := NewOTPService(cache)
+//	_ = svc.Issue(ctx, "user:1")
+//	clock.Advance(10 * time.Minute)
+//	// the OTP has expired
+func NewCache(t testing.TB) (redis.Cache, *Clock) {
+	t.Helper()
+	clock := NewClock(time.Now())
+	c := newMemory(clock.Now)
+	t.Cleanup(func() { _ = c.Close() })
+	return c, clock
+}
@@ -0,0 +1,240 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// scanBatchSize is the SCAN COUNT hint and the number of keys per UNLINK.
+const scanBatchSize = 500
+
+// Scan iterates over keys matching pattern (Redis glob syntax) using SCAN, never KEYS.
+// pattern and the yielded keys are relative to KeyPrefix, so only this service's
+// keys are visited. In cluster mode every master is scanned in turn. Iteration stops at the first error, which is yielded with an empty key.
+// SCAN guarantees every key present for the whole iteration is returned at least once;
+// keys may repeat.
+//
+//	for key, err := range cache.Scan(ctx, "user:*:permissions") {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(key) // "user:123:permissions"
+//	}
+func (c *cache) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if err := validatePattern(ctx, pattern); err != nil {
+			yield("", err)
+			return
+		}
+		nodes, err := c.scanNodes(ctx)
+		if err != nil {
+			yield("", c.logErr(ctx, "cache.scan", err))
+			return
+		}
+		match := c.scopedPattern(pattern)
+		for _, node := range nodes {
+			if !c.scanNode(ctx, node, match, yield) {
+				return
+			}
+		}
+	}
+}
+
+// scanNode runs one full SCAN over node and reports whether iteration should continue.
+func (c *cache) scanNode(ctx context.Context, node goredis.Cmdable, match string, yield func(string, error) bool) bool {
+	var cursor uint64
+	for {
+		keys, next, err := node.Scan(ctx, cursor, match, scanBatchSize).Result()
+		if err != nil {
+			yield("", c.logErr(ctx, "cache.scan", err))
+			return false
+		}
+		for _, k := range keys {
+			if !yield(c.unprefixed(k), nil) {
+				return false
+			}
+		}
+		if next == 0 {
+			return true
+		}
+		cursor = next
+	}
+}
+
+// DeleteByPattern removes every key matching pattern (relative to KeyPrefix) and
+// returns how many were removed. Keys are collected with SCAN and removed with
+// UNLINK in batches, so memory is reclaimed in the background and Redis is never
+// blocked. Requires KeyPrefix, so one service can never wipe another's keys.
+// Not atomic: keys written while it runs may survive. The GetOrSet sidecar keys
+// of every removed key are removed too.
+//
+//	n, err := cache.DeleteByPattern(ctx, "user:*:permissions")
+func (c *cache) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	if err := validatePattern(ctx, pattern); err != nil {
+		return 0, err
+	}
+	if c.keyPrefix == "" {
+		return 0, errors.New("redis: DeleteByPattern requires KeyPrefix")
+	}
+
+	var (
+		deleted int64
+		batch   = make([]string, 0, scanBatchSize)
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := c.countKeys(ctx, goredis.Cmdable.Unlink, batch)
+		if err != nil {
+			return c.logErr(ctx, "cache.delete_by_pattern", err)
+		}
+		deleted += n
+		// Sidecars the pattern may not match; not counted as deleted keys.
+		if sidecars := sidecarKeys(batch); len(sidecars) > 0 {
+			if _, err := c.countKeys(ctx, goredis.Cmdable.Unlink, sidecars); err != nil {
+				return c.logErr(ctx, "cache.delete_by_pattern", err)
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for key, err := range c.Scan(ctx, pattern) {
+		if err != nil {
+			return deleted, err
+		}
+		batch = append(batch, c.key(key))
+		if len(batch) == scanBatchSize {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// scopedPattern prepends the escaped KeyPrefix so glob characters in the prefix
+// itself match literally.
+func (c *cache) scopedPattern(pattern string) string {
+	if c.keyPrefix == "" {
+		return pattern
+	}
+	return escapeGlob(c.keyPrefix) + ":" + pattern
+}
+
+func (c *cache) unprefixed(k string) string {
+	if c.keyPrefix == "" {
+		return k
+	}
+	return strings.TrimPrefix(k, c.keyPrefix+":")
+}
+
+func validatePattern(ctx context.Context, pattern string) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	if pattern == "" {
+		return errors.New("redis: pattern is required")
+	}
+	return nil
+}
+
+// escapeGlob escapes the characters Redis glob patterns treat specially.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// matchGlob reports whether s matches a Redis glob pattern: * and ? wildcards,
+// [abc], [^abc] and [a-z] classes, and \ escapes (Redis stringmatchlen).
+func matchGlob(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchGlob(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			rest, ok := matchClass(pattern[1:], s[0])
+			if !ok {
+				return false
+			}
+			s = s[1:]
+			pattern = rest
+		default:
+			if pattern[0] == '\\' && len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// matchClass matches c against the class starting after '[' and returns the
+// pattern after the closing ']'.
+func matchClass(pattern string, c byte) (string, bool) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) > 1:
+			matched = matched || pattern[1] == c
+			pattern = pattern[2:]
+		case len(pattern) > 2 && pattern[1] == '-' && pattern[2] != ']':
+			lo, hi := pattern[0], pattern[2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			matched = matched || (c >= lo && c <= hi)
+			pattern = pattern[3:]
+		default:
+			matched = matched || pattern[0] == c
+			pattern = pattern[1:]
+		}
+	}
+	if len(pattern) > 0 {
+		pattern = pattern[1:] // closing ']'
+	}
+	return pattern, matched != negate
+}
@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func collectKeys(t *testing.T, c Cache, pattern string) []string {
+	t.Helper()
+	var keys []string
+	for k, err := range c.Scan(ctx, pattern) {
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+func TestScan_ScopedToPrefix(t *testing.T) {
+	c, mr := newTestCache(t)
+	_ = c.Set(ctx, "user:1:permissions", "a")
+	_ = c.Set(ctx, "user:2:permissions", "b")
+	_ = c.Set(ctx, "user:1:profile", "c")
+	_ = mr.Set("other:user:3:permissions", "d") // another service's key
+
+	got := collectKeys(t, c, "user:*:permissions")
+	if want := []string{"user:1:permissions", "user:2:permissions"}; !slices.Equal(got, want) {
+		t.Errorf("Scan = %v, want %v", got, want)
+	}
+}
+
+func TestScan_ManyKeysAndEarlyStop(t *testing.T) {
+	c, mr := newTestCache(t)
+	for i := 0; i < 1200; i++ {
+		_ = mr.Set(fmt.Sprintf("test:k:%d", i), "v")
+	}
+	if got := collectKeys(t, c, "k:*"); len(got) != 1200 {
+		t.Errorf("Scan returned %d keys, want 1200", len(got))
+	}
+
+	n := 0
+	for range c.Scan(ctx, "k:*") {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("iterated %d keys after break, want 3", n)
+	}
+}
+
+func TestScan_EscapesPrefixGlob(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	c := &cache{instance: client, keyPrefix: "svc*"}
+	_ = mr.Set("svc*:a", "1")
+	_ = mr.Set("svcX:b", "2") // would match an unescaped "svc*" prefix
+
+	if got := collectKeys(t, c, "*"); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("Scan = %v, want [a]", got)
+	}
+}
+
+func TestScan_EmptyPattern(t *testing.T) {
+	c, _ := newTestCache(t)
+	for _, err := range c.Scan(ctx, "") {
+		if err == nil {
+			t.Error("expected error for empty pattern")
+		}
+	}
+}
+
+func TestDeleteByPattern(t *testing.T) {
+	c, mr := newTestCache(t)
+	for i := 0; i < 1100; i++ {
+		_ = mr.Set(fmt.Sprintf("test:user:%d:permissions", i), "p")
+	}
+	_ = c.Set(ctx, "user:1:profile", "keep")
+	_ = mr.Set("other:user:1:permissions", "keep")
+
+	n, err := c.DeleteByPattern(ctx, "user:*:permissions")
+	if err != nil {
+		t.Fatalf("DeleteByPattern: %v", err)
+	}
+	if n != 1100 {
+		t.Errorf("deleted %d, want 1100", n)
+	}
+	if !mr.Exists("test:user:1:profile") || !mr.Exists("other:user:1:permissions") {
+		t.Error("non-matching keys must survive")
+	}
+	if left := collectKeys(t, c, "user:*:permissions"); len(left) != 0 {
+		t.Errorf("left = %v", left)
+	}
+}
+
+func TestDeleteByPattern_RequiresPrefix(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := &cache{instance: goredis.NewClient(&goredis.Options{Addr: mr.Addr()})}
+	_ = mr.Set("k", "v")
+	if _, err := c.DeleteByPattern(ctx, "*"); err == nil {
+		t.Error("expected error without KeyPrefix")
+	}
+	if !mr.Exists("k") {
+		t.Error("key must not be deleted")
+	}
+}
+
+func TestNearCache_DeleteByPatternClearsReplicas(t *testing.T) {
+	a, b, _ := newTestNearCaches(t, NearCacheConfig{})
+	_ = a.Set(ctx, "user:1:permissions", "x")
+	var got string
+	eventually(t, func() bool {
+		_ = b.Get(ctx, "user:1:permissions", &got)
+		return b.Stats().Entries == 1
+	})
+
+	if _, err := a.DeleteByPattern(ctx, "user:*"); err != nil {
+		t.Fatalf("DeleteByPattern: %v", err)
+	}
+	eventually(t, func() bool { return b.Stats().Entries == 0 })
+	if err := b.Get(ctx, "user:1:permissions", &got); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound after pattern delete, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Permits are sorted-set members holding a random token, scored by their lease
+// expiry in Redis TIME (ms), so every replica shares one clock. Expired leases are
+// dropped before counting; the key lives as long as its longest lease.
+
+const luaSemaphoreAcquire = `
+	local t = redis.call('TIME')
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	local ttl = tonumber(ARGV[3])
+
+	redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+	if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+		return 0
+	end
+	redis.call('ZADD', KEYS[1], now + ttl, ARGV[1])
+	if redis.call('PTTL', KEYS[1]) < ttl then
+		redis.call('PEXPIRE', KEYS[1], ttl)
+	end
+	return 1
+`
+
+const luaSemaphoreRefresh = `
+	local t = redis.call('TIME')
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	local ttl = tonumber(ARGV[2])
+
+	local expiry = redis.call('ZSCORE', KEYS[1], ARGV[1])
+	if not expiry or tonumber(expiry) <= now then
+		return 0
+	end
+	redis.call('ZADD', KEYS[1], now + ttl, ARGV[1])
+	if redis.call('PTTL', KEYS[1]) < ttl then
+		redis.call('PEXPIRE', KEYS[1], ttl)
+	end
+	return 1
+`
+
+const luaSemaphoreRelease = `
+	local t = redis.call('TIME')
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+	local expiry = redis.call('ZSCORE', KEYS[1], ARGV[1])
+	redis.call('ZREM', KEYS[1], ARGV[1])
+	if not expiry or tonumber(expiry) <= now then
+		return 0
+	end
+	return 1
+`
+
+// semaphoreStore stores semaphore permits. The Redis cache implements it with the
+// Lua scripts above; the in-memory cache in redistest under its mutex.
+type semaphoreStore interface {
+	// tryAcquire adds token to key with a lease of ttl if fewer than limit leases are live.
+	tryAcquire(ctx context.Context, key, token string, limit int, ttl time.Duration) (bool, error)
+	// refreshPermit extends the lease of token if it has not expired.
+	refreshPermit(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// releasePermit removes token, reporting whether its lease was still live.
+	releasePermit(ctx context.Context, key, token string) (bool, error)
+	logErr(ctx context.Context, step string, err error) error
+}
+
+// semaphoreBackend adapts a semaphoreStore to lockBackend, so a permit is a Lock
+// with the same waiting, auto-refresh and Lost handling as Cache.Lock.
+type semaphoreBackend struct {
+	semaphoreStore
+	limit int
+}
+
+func (s semaphoreBackend) tryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return s.tryAcquire(ctx, key, token, s.limit, ttl)
+}
+
+func (s semaphoreBackend) refreshLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return s.refreshPermit(ctx, key, token, ttl)
+}
+
+func (s semaphoreBackend) unlock(ctx context.Context, key, token string) (bool, error) {
+	return s.releasePermit(ctx, key, token)
+}
+
+// AcquireSemaphore takes one of limit permits on key, leased for ttl, and returns it
+// as a Lock: Unlock releases it, Refresh extends the lease and WithLockAutoRefresh
+// keeps it alive. A permit whose holder dies is freed when its lease expires.
+// Returns ErrLockNotAcquired when all permits are taken; use WithLockWait to block.
+//
+//	permit, err := cache.AcquireSemaphore(ctx, "sem:report-export", 3, time.Minute, redis.WithLockAutoRefresh())
+//	if errors.Is(err, redis.ErrLockNotAcquired) {
+//	    return nil // three exports are already running
+//	}
+//	defer permit.Unlock(ctx)
+func (c *cache) AcquireSemaphore(ctx context.Context, key string, limit int, ttl time.Duration, opts ...LockOption) (Lock, error) {
+	return acquireSemaphore(ctx, c, key, limit, ttl, opts)
+}
+
+func (c *cache) tryAcquire(ctx context.Context, key, token string, limit int, ttl time.Duration) (bool, error) {
+	n, err := c.instance.Eval(ctx, luaSemaphoreAcquire, []string{c.key(key)}, token, limit, ttl.Milliseconds()).Int64()
+	return n == 1, err
+}
+
+func (c *cache) refreshPermit(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := c.instance.Eval(ctx, luaSemaphoreRefresh, []string{c.key(key)}, token, ttl.Milliseconds()).Int64()
+	return n == 1, err
+}
+
+func (c *cache) releasePermit(ctx context.Context, key, token string) (bool, error) {
+	n, err := c.instance.Eval(ctx, luaSemaphoreRelease, []string{c.key(key)}, token).Int64()
+	return n == 1, err
+}
+
+// acquireSemaphore implements Cache.AcquireSemaphore on top of s.
+func acquireSemaphore(ctx context.Context, s semaphoreStore, key string, limit int, ttl time.Duration, opts []LockOption) (Lock, error) {
+	if limit <= 0 {
+		return nil, errors.New("redis: semaphore limit must be positive")
+	}
+	if ttl < time.Millisecond {
+		return nil, errors.New("redis: semaphore ttl must be at least 1ms")
+	}
+	return acquireLock(ctx, semaphoreBackend{semaphoreStore: s, limit: limit}, key, ttl, opts)
+}
+
+// Semaphore bounds how many holders across all replicas run at once, e.g. a job
+// that may run on at most N pods. It is a named view over Cache.AcquireSemaphore.
+//
+//	sem := redis.NewSemaphore(cache, "sem:reindex", 2, 30*time.Second)
+//	permit, err := sem.Acquire(ctx, redis.WithLockWait(time.Minute), redis.WithLockAutoRefresh())
+//	if err != nil {
+//	    return err
+//	}
+//	defer sem.Release(ctx, permit)
+type Semaphore struct {
+	cache Cache
+	key   string
+	limit int
+	ttl   time.Duration
+}
+
+// NewSemaphore returns a semaphore of limit permits stored at key, each leased for ttl.
+func NewSemaphore(c Cache, key string, limit int, ttl time.Duration) *Semaphore {
+	return &Semaphore{cache: c, key: key, limit: limit, ttl: ttl}
+}
+
+// Acquire takes a permit. Returns ErrLockNotAcquired when all permits are taken.
+func (s *Semaphore) Acquire(ctx context.Context, opts ...LockOption) (Lock, error) {
+	return s.cache.AcquireSemaphore(ctx, s.key, s.limit, s.ttl, opts...)
+}
+
+// Release returns permit. Returns ErrLockNotHeld if its lease had already expired.
+func (s *Semaphore) Release(ctx context.Context, permit Lock) error {
+	if permit == nil {
+		return errors.New("redis: permit is required")
+	}
+	return permit.Unlock(ctx)
+}
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSemaphore_Limit(t *testing.T) {
+	c, mr := newTestCache(t)
+	now := time.Now()
+	mr.SetTime(now)
+
+	a, err := c.AcquireSemaphore(ctx, "sem:export", 2, time.Minute)
+	if err != nil {
+		t.Fatalf("first permit: %v", err)
+	}
+	if _, err := c.AcquireSemaphore(ctx, "sem:export", 2, time.Minute); err != nil {
+		t.Fatalf("second permit: %v", err)
+	}
+	if _, err := c.AcquireSemaphore(ctx, "sem:export", 2, time.Minute); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("third permit: expected ErrLockNotAcquired, got %v", err)
+	}
+	if members, _ := mr.ZMembers("test:sem:export"); len(members) != 2 {
+		t.Errorf("permits stored = %v, want 2", members)
+	}
+
+	if err := a.Unlock(ctx); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := c.AcquireSemaphore(ctx, "sem:export", 2, time.Minute); err != nil {
+		t.Errorf("permit after release: %v", err)
+	}
+}
+
+func TestSemaphore_ExpiredLeaseFreesPermit(t *testing.T) {
+	c, mr := newTestCache(t)
+	now := time.Now()
+	mr.SetTime(now)
+
+	held, _ := c.AcquireSemaphore(ctx, "sem", 1, 10*time.Second)
+	mr.SetTime(now.Add(5 * time.Second))
+	if err := held.Refresh(ctx, 10*time.Second); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	mr.SetTime(now.Add(12 * time.Second)) // past the original lease, within the refreshed one
+	if _, err := c.AcquireSemaphore(ctx, "sem", 1, 10*time.Second); !errors.Is(err, ErrLockNotAcquired) {
+		t.Errorf("refreshed lease should still hold the permit, got %v", err)
+	}
+
+	mr.SetTime(now.Add(16 * time.Second)) // holder died without releasing
+	other, err := c.AcquireSemaphore(ctx, "sem", 1, 10*time.Second)
+	if err != nil {
+		t.Fatalf("permit after lease expiry: %v", err)
+	}
+	if err := held.Refresh(ctx, 10*time.Second); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Refresh of expired permit: expected ErrLockNotHeld, got %v", err)
+	}
+	if err := held.Unlock(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Unlock of expired permit: expected ErrLockNotHeld, got %v", err)
+	}
+	if err := other.Unlock(ctx); err != nil {
+		t.Errorf("stale release must not affect other permits: %v", err)
+	}
+}
+
+func TestSemaphore_Wait(t *testing.T) {
+	c, _ := newTestCache(t)
+	sem := NewSemaphore(c, "sem", 1, time.Minute)
+	held, err := sem.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = sem.Release(ctx, held)
+	}()
+	permit, err := sem.Acquire(ctx, WithLockWait(2*time.Second))
+	if err != nil {
+		t.Fatalf("Acquire with wait: %v", err)
+	}
+	if err := sem.Release(ctx, permit); err != nil {
+		t.Errorf("Release: %v", err)
+	}
+}
+
+func TestSemaphore_Validation(t *testing.T) {
+	c, _ := newTestCache(t)
+	if _, err := c.AcquireSemaphore(ctx, "sem", 0, time.Minute); err == nil {
+		t.Error("expected error for zero limit")
+	}
+	if _, err := c.AcquireSemaphore(ctx, "sem", 1, 0); err == nil {
+		t.Error("expected error for zero ttl")
+	}
+	if err := NewSemaphore(c, "sem", 1, time.Minute).Release(ctx, nil); err == nil {
+		t.Error("expected error for nil permit")
+	}
+}
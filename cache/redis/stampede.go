@@ -0,0 +1,281 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	mathrand "math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// Sidecar keys live next to the value key so plain Get keeps working on the value.
+	xfetchDeltaSuffix   = ":__xfetch"
+	recomputeLockSuffix = ":__recompute"
+	staleCopySuffix     = ":__stale"
+
+	recomputePollInterval = 50 * time.Millisecond
+)
+
+// rawPayload is a value as stored in Redis (header included), decrypted when
+// encryption is enabled. It is decoded with deserialize; a *rawPayload dest makes
+// Get skip decoding.
+type rawPayload string
+
+// errCachedAbsent reports a key cached as absent by WithNegativeTTL. Public
+// methods return ErrKeyNotFound in its place.
+var errCachedAbsent = errors.New("redis: key cached as absent")
+
+// lookup reads key into dest. With early refresh enabled it also reports whether
+// the caller should recompute the value ahead of expiry (XFetch).
+func (c *cache) lookup(ctx context.Context, key string, dest any, o *setOptions) (found, refresh bool, err error) {
+	if o.EarlyRefreshBeta <= 0 {
+		size, err := c.get(ctx, key, dest, &getOptions{RemainingTTL: o.RemainingTTL})
+		switch {
+		case err == nil:
+			c.metrics.recordSize(ctx, opGetOrSet, directionRead, key, size)
+			return true, false, nil
+		case errors.Is(err, ErrKeyNotFound):
+			return false, false, nil
+		default:
+			return false, false, err
+		}
+	}
+
+	if err := validateDest(ctx, key, dest); err != nil {
+		return false, false, err
+	}
+	pipe := c.instance.Pipeline()
+	getCmd := pipe.Get(ctx, c.key(key))
+	ttlCmd := pipe.PTTL(ctx, c.key(key))
+	deltaCmd := pipe.Get(ctx, c.key(key)+xfetchDeltaSuffix)
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		return false, false, c.logErr(ctx, "cache.get_or_set", err)
+	}
+
+	val, err := getCmd.Result()
+	if err == goredis.Nil {
+		return false, false, nil
+	}
+	if val == absentMarker {
+		return false, false, errCachedAbsent
+	}
+	if err := c.encoder.decode(val, dest); err != nil {
+		return false, false, err
+	}
+	c.metrics.recordSize(ctx, opGetOrSet, directionRead, key, len(val))
+	if o.RemainingTTL != nil {
+		*o.RemainingTTL = remainingTTL(ttlCmd.Val())
+	}
+
+	deltaMs, _ := strconv.ParseInt(deltaCmd.Val(), 10, 64)
+	return true, shouldRefreshEarly(time.Duration(deltaMs)*time.Millisecond, ttlCmd.Val(), o.EarlyRefreshBeta), nil
+}
+
+// load runs recompute, deduplicated per key within this process when requested.
+// Every caller receives the encoded payload and decodes its own copy.
+//
+// The shared recompute runs detached from the leader's cancellation, so a
+// cancelled leader does not fail its followers; each caller stops waiting when
+// its own ctx is done.
+func (c *cache) load(ctx context.Context, key string, fn func() (any, error), o *setOptions, haveValue bool) (rawPayload, error) {
+	if !o.Singleflight {
+		return c.recompute(ctx, key, fn, o, haveValue)
+	}
+	// The recompute may outlive this call, so it reports the TTL into its own copy.
+	shared := *o
+	var ttl time.Duration
+	if o.RemainingTTL != nil {
+		ttl = *o.RemainingTTL
+		shared.RemainingTTL = &ttl
+	}
+	led := false
+	ch := c.group.DoChan(c.key(key), func() (any, error) {
+		led = true
+		return c.recompute(context.WithoutCancel(ctx), key, fn, &shared, haveValue)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		if led && o.RemainingTTL != nil {
+			*o.RemainingTTL = ttl
+		}
+		return res.Val.(rawPayload), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// recompute calls fn and stores its result. With WithRecomputeLock only the lock
+// holder calls fn; everyone else waits for the holder to write the value.
+func (c *cache) recompute(ctx context.Context, key string, fn func() (any, error), o *setOptions, haveValue bool) (rawPayload, error) {
+	if o.RecomputeLockTTL > 0 {
+		l, err := c.Lock(ctx, key+recomputeLockSuffix, o.RecomputeLockTTL)
+		switch {
+		case err == nil:
+			defer func() { _ = l.Unlock(ctx) }()
+			// Another replica may have written the value between our miss and the lock.
+			if !haveValue {
+				if raw, ok, err := c.getRaw(ctx, key); err != nil || ok {
+					return raw, err
+				}
+			}
+		case errors.Is(err, ErrLockNotAcquired):
+			if raw, ok := c.waitForValue(ctx, key, o.RecomputeWait); ok {
+				return raw, nil
+			}
+			// Holder is too slow or died — compute ourselves rather than fail.
+		default:
+			return "", err
+		}
+	}
+
+	start := time.Now()
+	value, err := fn()
+	c.metrics.recordProducer(ctx, key, start, err)
+	if err != nil {
+		return c.producerFailed(ctx, key, o, err)
+	}
+	delta := time.Since(start)
+
+	if value == nil {
+		return "", errors.New("redis: value is required")
+	}
+	payload, err := c.encoder.encode(value, o.Codec)
+	if err != nil {
+		return "", err
+	}
+	if err := c.write(ctx, key, payload, o); err != nil {
+		return "", err
+	}
+	c.metrics.recordSize(ctx, opGetOrSet, directionWrite, key, len(payload))
+	if o.RemainingTTL != nil {
+		*o.RemainingTTL = writtenTTL(o)
+	}
+	if o.EarlyRefreshBeta > 0 {
+		if err := c.instance.Set(ctx, c.key(key)+xfetchDeltaSuffix, delta.Milliseconds(), o.TTL).Err(); err != nil {
+			_ = c.logErr(ctx, "cache.get_or_set", err)
+		}
+	}
+	if o.StaleWindow > 0 {
+		if err := c.instance.Set(ctx, c.key(key)+staleCopySuffix, payload, staleCopyTTL(o)).Err(); err != nil {
+			_ = c.logErr(ctx, "cache.get_or_set", err)
+		}
+	}
+	return rawPayload(payload), nil
+}
+
+// producerFailed handles an error from the GetOrSet producer: with WithNegativeTTL a
+// not-found result is cached as absent; with WithStaleOnError other failures fall
+// back to the shadow copy.
+func (c *cache) producerFailed(ctx context.Context, key string, o *setOptions, err error) (rawPayload, error) {
+	if o.NegativeTTL > 0 && errors.Is(err, ErrKeyNotFound) {
+		// NX: a value written concurrently by another caller wins over the marker.
+		if err := c.instance.SetNX(ctx, c.key(key), absentMarker, o.NegativeTTL).Err(); err != nil {
+			_ = c.logErr(ctx, "cache.get_or_set", err)
+		}
+		return "", ErrKeyNotFound
+	}
+	if o.StaleWindow > 0 && !errors.Is(err, ErrKeyNotFound) {
+		if raw, ok, getErr := c.getRaw(ctx, key+staleCopySuffix); getErr == nil && ok {
+			if c.logger != nil {
+				c.logger.Warning(ctx, "cache.get_or_set", "producer failed, serving stale value",
+					"key", key, "error", err.Error())
+			}
+			return raw, nil
+		}
+	}
+	return "", fmt.Errorf("redis: GetOrSet producer failed: %w", err)
+}
+
+// sidecarKeys returns the stale copy and XFetch delta keys of each key, so that
+// deleting a value cannot leave a copy for WithStaleOnError to serve later.
+// Keys that are sidecars themselves are skipped.
+func sidecarKeys(keys []string) []string {
+	out := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		if strings.HasSuffix(k, staleCopySuffix) || strings.HasSuffix(k, xfetchDeltaSuffix) {
+			continue
+		}
+		out = append(out, k+staleCopySuffix, k+xfetchDeltaSuffix)
+	}
+	return out
+}
+
+// remainingTTL converts a PTTL reply for getRemainingTTL: -1 (no expiry) stays
+// negative, a missing key (-2) becomes 0.
+func remainingTTL(pttl time.Duration) time.Duration {
+	if pttl == -2 {
+		return 0
+	}
+	return pttl
+}
+
+// writtenTTL is the remaining TTL of a value GetOrSet just wrote.
+func writtenTTL(o *setOptions) time.Duration {
+	switch {
+	case o.KeepTTL:
+		return 0
+	case o.TTL <= 0:
+		return -1
+	}
+	return o.TTL
+}
+
+// staleCopyTTL outlives the value by the staleness window; values without
+// expiry get a shadow without expiry.
+func staleCopyTTL(o *setOptions) time.Duration {
+	if o.TTL <= 0 {
+		return 0
+	}
+	return o.TTL + o.StaleWindow
+}
+
+// getRaw returns the serialized value stored at key without deserializing it.
+func (c *cache) getRaw(ctx context.Context, key string) (rawPayload, bool, error) {
+	val, err := c.instance.Get(ctx, c.key(key)).Result()
+	if err != nil {
+		if err == goredis.Nil {
+			return "", false, nil
+		}
+		return "", false, c.logErr(ctx, "cache.get_or_set", err)
+	}
+	return rawPayload(val), true, nil
+}
+
+// waitForValue polls key until it appears, wait elapses or ctx is done.
+func (c *cache) waitForValue(ctx context.Context, key string, wait time.Duration) (rawPayload, bool) {
+	deadline := time.Now().Add(wait)
+	for {
+		raw, ok, err := c.getRaw(ctx, key)
+		if err == nil && ok {
+			return raw, true
+		}
+		if time.Now().After(deadline) {
+			return "", false
+		}
+		select {
+		case <-ctx.Done():
+			return "", false
+		case <-time.After(min(recomputePollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// shouldRefreshEarly implements XFetch: refresh when now - delta*beta*ln(rand) >= expiry.
+// delta is the last recompute duration; keys without expiry are never refreshed early.
+func shouldRefreshEarly(delta, ttl time.Duration, beta float64) bool {
+	if ttl <= 0 || delta <= 0 {
+		return false
+	}
+	r := 1 - mathrand.Float64() // (0, 1] — avoids ln(0)
+	gap := -float64(delta) * beta * math.Log(r)
+	return gap >= float64(ttl)
+}
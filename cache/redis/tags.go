@@ -0,0 +1,182 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// tagKeyPrefix namespaces tag sets next to regular keys: "{KeyPrefix}:__tag:{tag}".
+const tagKeyPrefix = "__tag:"
+
+// SET plus SADD of the key into every tag set, atomically. Each tag set's TTL is
+// stretched to cover its longest-lived member so tag sets never outlive or
+// undercut what they index; a persistent member makes the tag set persistent.
+//
+// KEYS[1] = value key, KEYS[2..] = tag sets
+// ARGV[1] = payload, ARGV[2] = ttl ms (0 = none), ARGV[3] = "NX" | "XX" | "", ARGV[4] = "1" for KEEPTTL
+const luaSetTagged = `
+	local args = {'SET', KEYS[1], ARGV[1]}
+	if ARGV[4] == '1' then
+		table.insert(args, 'KEEPTTL')
+	elseif tonumber(ARGV[2]) > 0 then
+		table.insert(args, 'PX')
+		table.insert(args, ARGV[2])
+	end
+	if ARGV[3] ~= '' then
+		table.insert(args, ARGV[3])
+	end
+	if not redis.call(unpack(args)) then
+		return 0
+	end
+
+	local ttl = redis.call('PTTL', KEYS[1])
+	for i = 2, #KEYS do
+		local existed = redis.call('EXISTS', KEYS[i]) == 1
+		redis.call('SADD', KEYS[i], KEYS[1])
+		if ttl < 0 then
+			redis.call('PERSIST', KEYS[i])
+		else
+			local current = redis.call('PTTL', KEYS[i])
+			if not existed or (current >= 0 and current < ttl) then
+				redis.call('PEXPIRE', KEYS[i], ttl)
+			end
+		end
+	end
+	return 1
+`
+
+// Deletes every key recorded in the given tag sets, then the tag sets themselves.
+// Not used in cluster mode: the members are keys the script does not declare.
+// DEL is issued in chunks to stay below Lua's unpack limit.
+const luaInvalidateTags = `
+	local deleted = 0
+	for i = 1, #KEYS do
+		local members = redis.call('SMEMBERS', KEYS[i])
+		for j = 1, #members, 1000 do
+			deleted = deleted + redis.call('DEL', unpack(members, j, math.min(j + 999, #members)))
+		end
+		for _, member in ipairs(members) do
+			redis.call('DEL', member .. ARGV[1], member .. ARGV[2])
+		end
+		redis.call('DEL', KEYS[i])
+	end
+	return deleted
+`
+
+// WithTags records the written key in one set per tag so InvalidateTags can later
+// delete every key carrying a tag. Applies to Set, SetMany and GetOrSet; the write
+// and the tag bookkeeping happen atomically in a Lua script.
+//
+//	cache.Set(ctx, "view:product:42:summary", summary,
+//	    redis.WithTags("tenant:7", "product:42"))
+func WithTags(tags ...string) SetOption {
+	return func(o *setOptions) { o.Tags = append(o.Tags, tags...) }
+}
+
+// InvalidateTags atomically deletes every key written WithTags for any of tags,
+// with its GetOrSet sidecar keys, and removes the tag sets. Keys already expired
+// or deleted are skipped. In cluster mode tagged keys may live on other shards
+// than their tag sets, so the members are read first and deleted one command per
+// key; a key tagged in between survives the invalidation.
+//
+//	cache.InvalidateTags(ctx, "product:42") // drop every view derived from product 42
+func (c *cache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if ctx == nil {
+		return errors.New("redis: context is required")
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	keys, err := c.tagKeys(tags)
+	if err != nil {
+		return err
+	}
+	if c.cluster {
+		return c.invalidateTagsCluster(ctx, keys)
+	}
+	if err := c.instance.Eval(ctx, luaInvalidateTags, keys, staleCopySuffix, xfetchDeltaSuffix).Err(); err != nil {
+		return c.logErr(ctx, "cache.invalidate_tags", err)
+	}
+	return nil
+}
+
+// invalidateTagsCluster is InvalidateTags without Lua: a script may only touch
+// the keys it declares, and the members of a tag set are not known up front.
+func (c *cache) invalidateTagsCluster(ctx context.Context, tagKeys []string) error {
+	pipe := c.instance.Pipeline()
+	cmds := make([]*goredis.StringSliceCmd, len(tagKeys))
+	for i, k := range tagKeys {
+		cmds[i] = pipe.SMembers(ctx, k)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return c.logErr(ctx, "cache.invalidate_tags", err)
+	}
+	var members []string
+	for _, cmd := range cmds {
+		members = append(members, cmd.Val()...)
+	}
+	keys := append(append(members, sidecarKeys(members)...), tagKeys...)
+	if _, err := c.countKeys(ctx, goredis.Cmdable.Del, keys); err != nil {
+		return c.logErr(ctx, "cache.invalidate_tags", err)
+	}
+	return nil
+}
+
+// writeTagged stores payload and records key in its tag sets (see luaSetTagged).
+func (c *cache) writeTagged(ctx context.Context, key, payload string, o *setOptions) error {
+	keys, args, err := c.taggedSetArgs(key, payload, o)
+	if err != nil {
+		return err
+	}
+	applied, err := c.instance.Eval(ctx, luaSetTagged, keys, args...).Int64()
+	if err != nil {
+		return c.logErr(ctx, "cache.set", err)
+	}
+	if applied == 0 {
+		return ErrKeyNotSet
+	}
+	return nil
+}
+
+func (c *cache) taggedSetArgs(key, payload string, o *setOptions) ([]string, []any, error) {
+	tagKeys, err := c.tagKeys(o.Tags)
+	if err != nil {
+		return nil, nil, err
+	}
+	mode := ""
+	switch {
+	case o.IfNotExist:
+		mode = "NX"
+	case o.IfExist:
+		mode = "XX"
+	}
+	keepTTL := "0"
+	if o.KeepTTL {
+		keepTTL = "1"
+	}
+	keys := append([]string{c.key(key)}, tagKeys...)
+	return keys, []any{payload, strconv.FormatInt(o.TTL.Milliseconds(), 10), mode, keepTTL}, nil
+}
+
+func (c *cache) tagKeys(tags []string) ([]string, error) {
+	if err := validateTags(tags); err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = c.key(tagKeyPrefix + tag)
+	}
+	return keys, nil
+}
+
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if tag == "" {
+			return errors.New("redis: tag must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithTags_InvalidateTags(t *testing.T) {
+	c, mr := newTestCache(t)
+	_ = c.Set(ctx, "view:p42:summary", "s", WithTags("product:42", "tenant:7"))
+	_ = c.Set(ctx, "view:p42:detail", "d", WithTags("product:42"))
+	_ = c.Set(ctx, "view:p43:summary", "s", WithTags("product:43", "tenant:7"))
+	_ = c.Set(ctx, "untagged", "u")
+
+	if members, _ := mr.Members("test:__tag:product:42"); len(members) != 2 {
+		t.Fatalf("tag set members = %v, want 2", members)
+	}
+
+	if err := c.InvalidateTags(ctx, "product:42"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	for _, k := range []string{"test:view:p42:summary", "test:view:p42:detail", "test:__tag:product:42"} {
+		if mr.Exists(k) {
+			t.Errorf("%s should be deleted", k)
+		}
+	}
+	if !mr.Exists("test:view:p43:summary") || !mr.Exists("test:untagged") {
+		t.Error("keys without the tag must survive")
+	}
+
+	if err := c.InvalidateTags(ctx, "tenant:7"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	if mr.Exists("test:view:p43:summary") {
+		t.Error("tenant-tagged key should be deleted")
+	}
+}
+
+func TestWithTags_TagSetTTLCoversMembers(t *testing.T) {
+	c, mr := newTestCache(t)
+	_ = c.Set(ctx, "a", "1", WithTTL(time.Minute), WithTags("t"))
+	if ttl := mr.TTL("test:__tag:t"); ttl != time.Minute {
+		t.Errorf("tag TTL = %v, want 1m", ttl)
+	}
+	_ = c.Set(ctx, "b", "1", WithTTL(time.Hour), WithTags("t"))
+	if ttl := mr.TTL("test:__tag:t"); ttl != time.Hour {
+		t.Errorf("tag TTL = %v, want extended to 1h", ttl)
+	}
+	_ = c.Set(ctx, "c", "1", WithTTL(time.Second), WithTags("t"))
+	if ttl := mr.TTL("test:__tag:t"); ttl != time.Hour {
+		t.Errorf("tag TTL = %v, must not shrink", ttl)
+	}
+	_ = c.Set(ctx, "d", "1", WithPersist(), WithTags("t"))
+	if ttl := mr.TTL("test:__tag:t"); ttl != 0 {
+		t.Errorf("tag TTL = %v, want persistent", ttl)
+	}
+}
+
+func TestWithTags_NXAndKeepTTL(t *testing.T) {
+	c, mr := newTestCache(t)
+	_ = c.Set(ctx, "k", "1", WithTTL(time.Minute))
+	if err := c.Set(ctx, "k", "2", WithNX(), WithTags("t")); !errors.Is(err, ErrKeyNotSet) {
+		t.Errorf("expected ErrKeyNotSet, got %v", err)
+	}
+	if mr.Exists("test:__tag:t") {
+		t.Error("tag must not be recorded when the write is skipped")
+	}
+
+	if err := c.Set(ctx, "k", "3", WithKeepTTL(), WithTags("t")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if ttl := mr.TTL("test:k"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want kept 1m", ttl)
+	}
+}
+
+func TestWithTags_SetManyAndGetOrSet(t *testing.T) {
+	c, mr := newTestCache(t)
+	err := c.SetMany(ctx, []SetItem{
+		{Key: "a", Value: "1"},
+		{Key: "b", Value: "2", TTL: time.Minute},
+	}, WithTags("batch"))
+	if err != nil {
+		t.Fatalf("SetMany: %v", err)
+	}
+	if ttl := mr.TTL("test:b"); ttl != time.Minute {
+		t.Errorf("item TTL = %v, want 1m", ttl)
+	}
+
+	var got string
+	_ = c.GetOrSet(ctx, "c", &got, func() (any, error) { return "3", nil }, WithTags("batch"))
+
+	if members, _ := mr.Members("test:__tag:batch"); len(members) != 3 {
+		t.Fatalf("tag members = %v, want 3", members)
+	}
+	if err := c.InvalidateTags(ctx, "batch"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	if mr.Exists("test:a") || mr.Exists("test:b") || mr.Exists("test:c") {
+		t.Error("all tagged keys should be deleted")
+	}
+}
+
+func TestWithTags_EmptyTag(t *testing.T) {
+	c, _ := newTestCache(t)
+	if err := c.Set(ctx, "k", "v", WithTags("")); err == nil {
+		t.Error("expected error for empty tag")
+	}
+}
+
+func TestNearCache_InvalidateTagsClearsReplicas(t *testing.T) {
+	a, b, _ := newTestNearCaches(t, NearCacheConfig{})
+	_ = a.Set(ctx, "view:1", "x", WithTags("product:1"))
+	var got string
+	eventually(t, func() bool {
+		_ = b.Get(ctx, "view:1", &got)
+		return b.Stats().Entries == 1
+	})
+
+	if err := a.InvalidateTags(ctx, "product:1"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	eventually(t, func() bool { return b.Stats().Entries == 0 })
+	if err := b.Get(ctx, "view:1", &got); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+}
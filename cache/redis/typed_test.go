@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type typedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestTyped_SetGet(t *testing.T) {
+	c, mr := newTestCache(t)
+	users := NewTyped[typedUser](c, Namespace("user"), WithTTL(time.Minute))
+
+	if err := users.Set(ctx, "1", typedUser{ID: 1, Name: "alice"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !mr.Exists("test:user:1") {
+		t.Fatal("expected key test:user:1")
+	}
+	if ttl := mr.TTL("test:user:1"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want default 1m", ttl)
+	}
+
+	got, err := users.Get(ctx, "1")
+	if err != nil || got != (typedUser{ID: 1, Name: "alice"}) {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+	if _, err := users.Get(ctx, "2"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestTyped_PerCallOptionsOverrideDefaults(t *testing.T) {
+	c, mr := newTestCache(t)
+	users := NewTyped[typedUser](c, Namespace("user"), WithTTL(time.Minute))
+
+	_ = users.Set(ctx, "1", typedUser{ID: 1}, WithTTL(time.Hour))
+	if ttl := mr.TTL("test:user:1"); ttl != time.Hour {
+		t.Errorf("TTL = %v, want 1h", ttl)
+	}
+}
+
+func TestTyped_GetOrSet(t *testing.T) {
+	c, _ := newTestCache(t)
+	users := NewTyped[*typedUser](c, Namespace("user"))
+
+	calls := 0
+	fn := func(context.Context) (*typedUser, error) {
+		calls++
+		return &typedUser{ID: 7, Name: "bob"}, nil
+	}
+	for range 2 {
+		got, err := users.GetOrSet(ctx, "7", fn)
+		if err != nil || got == nil || got.Name != "bob" {
+			t.Fatalf("GetOrSet = %+v, %v", got, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fn called %d times, want 1", calls)
+	}
+
+	_, err := users.GetOrSet(ctx, "8", func(context.Context) (*typedUser, error) {
+		return nil, errors.New("db down")
+	})
+	if err == nil {
+		t.Error("expected producer error")
+	}
+}
+
+func TestTyped_DeleteAndExists(t *testing.T) {
+	c, _ := newTestCache(t)
+	counts := NewTyped[int](c, Namespace("count"))
+	_ = counts.Set(ctx, "a", 1)
+	_ = counts.Set(ctx, "b", 2)
+
+	if err := counts.Delete(ctx, "a", "b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if ok, _ := counts.Exists(ctx, "a"); ok {
+		t.Error("a still exists")
+	}
+	if counts.Key("a") != "count:a" {
+		t.Errorf("Key = %q", counts.Key("a"))
+	}
+}
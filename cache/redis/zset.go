@@ -0,0 +1,269 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// TimeScore converts t to the score used by time-indexed sorted sets (Unix milliseconds).
+// ZTrimBefore assumes members were scored this way.
+func TimeScore(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
+
+// ZAdd adds members to the sorted set at key, updating the score of existing ones.
+// If opts include WithTTL, the TTL is applied atomically via a Lua script.
+// Returns ErrEncryptionUnsupported when Config.Encryption is set.
+//
+//	cache.ZAdd(ctx, "leaderboard", []redis.ZMember{{Member: "alice", Score: 120}})
+//	cache.ZAdd(ctx, "feed:123", []redis.ZMember{{Member: eventID, Score: redis.TimeScore(time.Now())}})
+func (c *cache) ZAdd(ctx context.Context, key string, members []ZMember, opts ...SetOption) error {
+	if err := validateKey(ctx, key); err != nil {
+		return err
+	}
+	if err := c.plaintextOnly(); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return errors.New("redis: at least one member is required")
+	}
+
+	o := &setOptions{TTL: 0}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.TTL <= 0 {
+		zs := make([]goredis.Z, len(members))
+		for i, m := range members {
+			zs[i] = goredis.Z{Score: m.Score, Member: m.Member}
+		}
+		if err := c.instance.ZAdd(ctx, c.key(key), zs...).Err(); err != nil {
+			return c.logErr(ctx, "cache.zset_add", err)
+		}
+		return nil
+	}
+
+	// Atomic ZADD + EXPIRE via Lua
+	const luaZAddWithTTL = `
+		redis.call('ZADD', KEYS[1], unpack(ARGV, 2))
+		redis.call('EXPIRE', KEYS[1], ARGV[1])
+		return 1
+	`
+	args := make([]any, 0, 2*len(members)+1)
+	args = append(args, int64(o.TTL.Seconds()))
+	for _, m := range members {
+		args = append(args, formatScore(m.Score), m.Member)
+	}
+	if err := c.instance.Eval(ctx, luaZAddWithTTL, []string{c.key(key)}, args...).Err(); err != nil {
+		return c.logErr(ctx, "cache.zset_add", err)
+	}
+	return nil
+}
+
+// ZIncrBy atomically adds delta to member's score and returns the new score.
+// A missing key or member starts at 0. Returns ErrEncryptionUnsupported when
+// Config.Encryption is set.
+func (c *cache) ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	if member == "" {
+		return 0, errors.New("redis: member is required")
+	}
+	if err := c.plaintextOnly(); err != nil {
+		return 0, err
+	}
+	score, err := c.instance.ZIncrBy(ctx, c.key(key), delta, member).Result()
+	if err != nil {
+		return 0, c.logErr(ctx, "cache.zset_increment", err)
+	}
+	return score, nil
+}
+
+// ZRange returns members ranked start..stop (inclusive, 0-based, negative counts from
+// the end) in ascending score order. Returns an empty slice if the key does not exist.
+func (c *cache) ZRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return nil, err
+	}
+	zs, err := c.instance.ZRangeWithScores(ctx, c.key(key), start, stop).Result()
+	if err != nil {
+		return nil, c.logErr(ctx, "cache.zset_range", err)
+	}
+	return toZMembers(zs), nil
+}
+
+// ZRevRange is ZRange in descending score order — ZRevRange(ctx, key, 0, 9) is a top 10.
+func (c *cache) ZRevRange(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return nil, err
+	}
+	zs, err := c.instance.ZRevRangeWithScores(ctx, c.key(key), start, stop).Result()
+	if err != nil {
+		return nil, c.logErr(ctx, "cache.zset_range", err)
+	}
+	return toZMembers(zs), nil
+}
+
+// ZRangeByScore returns members whose score falls within r, one page at a time.
+//
+//	// second page of events from the last hour, newest first
+//	page, _ := cache.ZRangeByScore(ctx, "feed:123", redis.ScoreRange{
+//	    Min:     redis.TimeScore(time.Now().Add(-time.Hour)),
+//	    Max:     math.Inf(1),
+//	    Reverse: true,
+//	    Offset:  20,
+//	    Count:   20,
+//	})
+func (c *cache) ZRangeByScore(ctx context.Context, key string, r ScoreRange) ([]ZMember, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return nil, err
+	}
+	if r.Offset < 0 || r.Count < 0 {
+		return nil, errors.New("redis: offset and count must not be negative")
+	}
+
+	by := &goredis.ZRangeBy{
+		Min:    scoreBound(r.Min, r.Exclusive),
+		Max:    scoreBound(r.Max, r.Exclusive),
+		Offset: r.Offset,
+		Count:  r.Count,
+	}
+	if r.Count == 0 && r.Offset > 0 {
+		by.Count = -1 // LIMIT requires a count; -1 means "all remaining"
+	}
+
+	var (
+		zs  []goredis.Z
+		err error
+	)
+	if r.Reverse {
+		zs, err = c.instance.ZRevRangeByScoreWithScores(ctx, c.key(key), by).Result()
+	} else {
+		zs, err = c.instance.ZRangeByScoreWithScores(ctx, c.key(key), by).Result()
+	}
+	if err != nil {
+		return nil, c.logErr(ctx, "cache.zset_range_by_score", err)
+	}
+	return toZMembers(zs), nil
+}
+
+// ZRank returns member's 0-based rank in ascending score order.
+// Returns ErrKeyNotFound when the key or the member does not exist.
+func (c *cache) ZRank(ctx context.Context, key, member string) (int64, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	if member == "" {
+		return 0, errors.New("redis: member is required")
+	}
+	rank, err := c.instance.ZRank(ctx, c.key(key), member).Result()
+	if err != nil {
+		if err == goredis.Nil {
+			return 0, ErrKeyNotFound
+		}
+		return 0, c.logErr(ctx, "cache.zset_rank", err)
+	}
+	return rank, nil
+}
+
+// ZRevRank returns member's 0-based rank in descending score order (0 = top of the leaderboard).
+// Returns ErrKeyNotFound when the key or the member does not exist.
+func (c *cache) ZRevRank(ctx context.Context, key, member string) (int64, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	if member == "" {
+		return 0, errors.New("redis: member is required")
+	}
+	rank, err := c.instance.ZRevRank(ctx, c.key(key), member).Result()
+	if err != nil {
+		if err == goredis.Nil {
+			return 0, ErrKeyNotFound
+		}
+		return 0, c.logErr(ctx, "cache.zset_rank", err)
+	}
+	return rank, nil
+}
+
+// ZRem removes members from the sorted set. Redis deletes the key once it is empty.
+func (c *cache) ZRem(ctx context.Context, key string, members ...string) error {
+	if err := validateKey(ctx, key); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return errors.New("redis: at least one member is required")
+	}
+	if err := c.instance.ZRem(ctx, c.key(key), toAny(members)...).Err(); err != nil {
+		return c.logErr(ctx, "cache.zset_remove", err)
+	}
+	return nil
+}
+
+// ZTrimTop keeps the n highest-scored members and removes the rest.
+// Returns the number of members removed.
+func (c *cache) ZTrimTop(ctx context.Context, key string, n int64) (int64, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("redis: n must not be negative")
+	}
+	removed, err := c.instance.ZRemRangeByRank(ctx, c.key(key), 0, -n-1).Result()
+	if err != nil {
+		return 0, c.logErr(ctx, "cache.zset_trim", err)
+	}
+	return removed, nil
+}
+
+// ZTrimBefore removes members scored before cutoff, for sets scored with TimeScore.
+// Returns the number of members removed.
+//
+//	cache.ZTrimBefore(ctx, "feed:123", time.Now().Add(-24*time.Hour))
+func (c *cache) ZTrimBefore(ctx context.Context, key string, cutoff time.Time) (int64, error) {
+	if err := validateKey(ctx, key); err != nil {
+		return 0, err
+	}
+	removed, err := c.instance.ZRemRangeByScore(ctx, c.key(key), "-inf", scoreBound(TimeScore(cutoff), true)).Result()
+	if err != nil {
+		return 0, c.logErr(ctx, "cache.zset_trim", err)
+	}
+	return removed, nil
+}
+
+// --- internal helpers ---
+
+func toZMembers(zs []goredis.Z) []ZMember {
+	out := make([]ZMember, len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		out[i] = ZMember{Member: member, Score: z.Score}
+	}
+	return out
+}
+
+// formatScore renders a score the way Redis parses it, including infinities.
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "+inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	default:
+		return strconv.FormatFloat(score, 'f', -1, 64)
+	}
+}
+
+// scoreBound renders a range bound; exclusive bounds get the "(" prefix.
+func scoreBound(score float64, exclusive bool) string {
+	if exclusive && !math.IsInf(score, 0) {
+		return "(" + formatScore(score)
+	}
+	return formatScore(score)
+}
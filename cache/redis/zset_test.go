@@ -0,0 +1,168 @@
+package redis
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func seedLeaderboard(t *testing.T, c Cache) {
+	t.Helper()
+	err := c.ZAdd(ctx, "board", []ZMember{
+		{Member: "alice", Score: 30},
+		{Member: "bob", Score: 10},
+		{Member: "carol", Score: 20},
+		{Member: "dave", Score: 40},
+	})
+	if err != nil {
+		t.Fatalf("ZAdd: %v", err)
+	}
+}
+
+func zsetMembers(zs []ZMember) []string {
+	out := make([]string, len(zs))
+	for i, z := range zs {
+		out[i] = z.Member
+	}
+	return out
+}
+
+func TestZAdd_ZRange_ZRevRange(t *testing.T) {
+	c, _ := newTestCache(t)
+	seedLeaderboard(t, c)
+
+	asc, err := c.ZRange(ctx, "board", 0, -1)
+	if err != nil {
+		t.Fatalf("ZRange: %v", err)
+	}
+	if want := []string{"bob", "carol", "alice", "dave"}; !slices.Equal(zsetMembers(asc), want) {
+		t.Errorf("ZRange = %v, want %v", zsetMembers(asc), want)
+	}
+	if asc[0].Score != 10 {
+		t.Errorf("score = %v, want 10", asc[0].Score)
+	}
+
+	top, _ := c.ZRevRange(ctx, "board", 0, 1)
+	if want := []string{"dave", "alice"}; !slices.Equal(zsetMembers(top), want) {
+		t.Errorf("ZRevRange = %v, want %v", zsetMembers(top), want)
+	}
+
+	empty, err := c.ZRange(ctx, "missing", 0, -1)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("missing key = %v, %v; want empty", empty, err)
+	}
+}
+
+func TestZAdd_WithTTL(t *testing.T) {
+	c, mr := newTestCache(t)
+	if err := c.ZAdd(ctx, "z", []ZMember{{Member: "a", Score: 1.5}}, WithTTL(time.Minute)); err != nil {
+		t.Fatalf("ZAdd: %v", err)
+	}
+	if ttl := mr.TTL("test:z"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want 1m", ttl)
+	}
+	if score, _ := mr.ZScore("test:z", "a"); score != 1.5 {
+		t.Errorf("score = %v, want 1.5", score)
+	}
+}
+
+func TestZIncrBy(t *testing.T) {
+	c, _ := newTestCache(t)
+	if s, err := c.ZIncrBy(ctx, "board", "alice", 5); err != nil || s != 5 {
+		t.Fatalf("ZIncrBy = %v, %v; want 5", s, err)
+	}
+	if s, _ := c.ZIncrBy(ctx, "board", "alice", 2.5); s != 7.5 {
+		t.Errorf("ZIncrBy = %v, want 7.5", s)
+	}
+}
+
+func TestZRangeByScore_Pagination(t *testing.T) {
+	c, _ := newTestCache(t)
+	seedLeaderboard(t, c)
+
+	tests := []struct {
+		name string
+		r    ScoreRange
+		want []string
+	}{
+		{"all", ScoreRange{Min: math.Inf(-1), Max: math.Inf(1)}, []string{"bob", "carol", "alice", "dave"}},
+		{"bounded", ScoreRange{Min: 20, Max: 30}, []string{"carol", "alice"}},
+		{"exclusive", ScoreRange{Min: 20, Max: 40, Exclusive: true}, []string{"alice"}},
+		{"page", ScoreRange{Min: math.Inf(-1), Max: math.Inf(1), Offset: 1, Count: 2}, []string{"carol", "alice"}},
+		{"offset only", ScoreRange{Min: math.Inf(-1), Max: math.Inf(1), Offset: 3}, []string{"dave"}},
+		{"reverse page", ScoreRange{Min: 10, Max: 40, Reverse: true, Count: 2}, []string{"dave", "alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ZRangeByScore(ctx, "board", tt.r)
+			if err != nil {
+				t.Fatalf("ZRangeByScore: %v", err)
+			}
+			if !slices.Equal(zsetMembers(got), tt.want) {
+				t.Errorf("got %v, want %v", zsetMembers(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestZRank_ZRevRank(t *testing.T) {
+	c, _ := newTestCache(t)
+	seedLeaderboard(t, c)
+
+	if r, err := c.ZRank(ctx, "board", "bob"); err != nil || r != 0 {
+		t.Errorf("ZRank = %d, %v; want 0", r, err)
+	}
+	if r, err := c.ZRevRank(ctx, "board", "bob"); err != nil || r != 3 {
+		t.Errorf("ZRevRank = %d, %v; want 3", r, err)
+	}
+	if _, err := c.ZRank(ctx, "board", "nobody"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestZRem(t *testing.T) {
+	c, mr := newTestCache(t)
+	seedLeaderboard(t, c)
+	if err := c.ZRem(ctx, "board", "alice", "bob", "carol", "dave"); err != nil {
+		t.Fatalf("ZRem: %v", err)
+	}
+	if mr.Exists("test:board") {
+		t.Error("expected empty sorted set to be removed")
+	}
+}
+
+func TestZTrimTop(t *testing.T) {
+	c, _ := newTestCache(t)
+	seedLeaderboard(t, c)
+	removed, err := c.ZTrimTop(ctx, "board", 2)
+	if err != nil || removed != 2 {
+		t.Fatalf("ZTrimTop = %d, %v; want 2", removed, err)
+	}
+	left, _ := c.ZRevRange(ctx, "board", 0, -1)
+	if want := []string{"dave", "alice"}; !slices.Equal(zsetMembers(left), want) {
+		t.Errorf("left = %v, want %v", zsetMembers(left), want)
+	}
+}
+
+func TestZTrimBefore(t *testing.T) {
+	c, _ := newTestCache(t)
+	now := time.Now()
+	err := c.ZAdd(ctx, "feed", []ZMember{
+		{Member: "old", Score: TimeScore(now.Add(-2 * time.Hour))},
+		{Member: "edge", Score: TimeScore(now.Add(-time.Hour))},
+		{Member: "new", Score: TimeScore(now)},
+	})
+	if err != nil {
+		t.Fatalf("ZAdd: %v", err)
+	}
+	removed, err := c.ZTrimBefore(ctx, "feed", now.Add(-time.Hour))
+	if err != nil || removed != 1 {
+		t.Fatalf("ZTrimBefore = %d, %v; want 1", removed, err)
+	}
+	left, _ := c.ZRange(ctx, "feed", 0, -1)
+	if want := []string{"edge", "new"}; !slices.Equal(zsetMembers(left), want) {
+		t.Errorf("left = %v, want %v", zsetMembers(left), want)
+	}
+}
@@ -18,6 +18,11 @@ type Config struct {
 	MaxPoolSize int           // max idle and open connections; default 2
 	MaxLifeTime time.Duration // max connection lifetime; default 5m
 	Verbose     bool          // false → silent; true → warn + slow query logging (≥200ms)
+
+	// Replicas lists read replicas as "host" or "host:port" (port defaults to Port).
+	// They share User, Password, Name, SSLMode and pool settings with the primary.
+	Replicas        []string
+	ReplicaCooldown time.Duration // how long a failing replica is ejected; default 30s
 }
 
 // ConfigFromEnv reads database configuration from environment variables.
@@ -29,7 +34,8 @@ type Config struct {
 // Required: {prefix}_HOST, {prefix}_USER, {prefix}_PASSWORD, {prefix}_NAME
 // Optional: {prefix}_PORT (5432), {prefix}_SSLMODE (require),
 //
-//	{prefix}_MAX_POOL_SIZE (2), {prefix}_MAX_LIFE_TIME (5m)
+//	{prefix}_MAX_POOL_SIZE (2), {prefix}_MAX_LIFE_TIME (5m),
+//	{prefix}_REPLICA_HOSTS (comma-separated), {prefix}_REPLICA_COOLDOWN (30s)
 func ConfigFromEnv(prefix string) (Config, error) {
 	p := prefix + "_"
 	cfg := Config{
@@ -41,6 +47,9 @@ func ConfigFromEnv(prefix string) (Config, error) {
 		SSLMode:     env.GetEnvWithDefault(p+"SSLMODE", "require"),
 		MaxPoolSize: env.GetEnvAsIntWithDefault(p+"MAX_POOL_SIZE", 2),
 		MaxLifeTime: env.GetEnvAsDurationWithDefault(p+"MAX_LIFE_TIME", 5*time.Minute),
+
+		Replicas:        env.GetEnvAsSliceWithDefault(p+"REPLICA_HOSTS", ",", nil),
+		ReplicaCooldown: env.GetEnvAsDurationWithDefault(p+"REPLICA_COOLDOWN", defaultReplicaCooldown),
 	}
 
 	var missing []string
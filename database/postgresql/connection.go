@@ -31,7 +31,14 @@ func New(cfg Config, log logger.Logger) (Database, error) {
 	if err != nil {
 		return nil, fmt.Errorf("postgresql: %w", err)
 	}
-	return &database{instance: gdb, logger: log}, nil
+	db := &database{instance: gdb, logger: log}
+	if len(cfg.Replicas) > 0 {
+		if db.replicas, err = openReplicas(cfg, log); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("postgresql: %w", err)
+		}
+	}
+	return db, nil
 }
 
 // RunMigrations applies all pending up migrations from migrationsPath.
@@ -69,19 +76,26 @@ func connect(cfg Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to open connection after %d attempts: %w", connectionRetries+1, err)
 	}
 
+	if err := setupPool(instance, cfg); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// setupPool applies cfg's pool limits to instance and enables OTel tracing.
+func setupPool(instance *gorm.DB, cfg Config) error {
 	sqlDB, err := instance.DB()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
+		return fmt.Errorf("failed to get sql.DB: %w", err)
 	}
 	sqlDB.SetMaxIdleConns(cfg.MaxPoolSize)
 	sqlDB.SetMaxOpenConns(cfg.MaxPoolSize)
 	sqlDB.SetConnMaxLifetime(cfg.MaxLifeTime)
 
 	if err := instance.Use(tracing.NewPlugin()); err != nil {
-		return nil, fmt.Errorf("failed to enable OTel tracing: %w", err)
+		return fmt.Errorf("failed to enable OTel tracing: %w", err)
 	}
-
-	return instance, nil
+	return nil
 }
 
 func buildGormLogger(verbose bool) gormLogger.Interface {
@@ -69,7 +69,7 @@ func (db *database) Find(ctx context.Context, model any, preloads []string, cond
 	if err := validate(ctx, model); err != nil {
 		return false, err
 	}
-	tx := db.instance.WithContext(ctx)
+	tx := db.reader(ctx).instance.WithContext(ctx)
 	for _, p := range preloads {
 		tx = tx.Preload(p)
 	}
@@ -87,21 +87,32 @@ func (db *database) Find(ctx context.Context, model any, preloads []string, cond
 
 // FindMany retrieves all records matching conditions into model (must be a pointer to a slice).
 // Returns found=false (no error) when the result set is empty.
+// With options.Keyset it returns one keyset page; use FindManyKeyset to get its cursors.
 func (db *database) FindMany(ctx context.Context, model any, options *QueryOptions, conditions any, args ...any) (bool, error) {
 	if err := validate(ctx, model); err != nil {
 		return false, err
 	}
-	tx := db.instance.WithContext(ctx)
+	if options != nil && options.Keyset != nil {
+		if err := validateSlice(model); err != nil {
+			return false, err
+		}
+		if _, err := db.reader(ctx).findKeyset(ctx, model, options, conditions, args); err != nil {
+			return false, err
+		}
+		return reflect.ValueOf(model).Elem().Len() > 0, nil
+	}
+	tx := db.reader(ctx).instance.WithContext(ctx)
 	if conditions != nil {
 		tx = tx.Where(conditions, args...)
 	}
 	if options != nil {
 		tx = applyQueryOptions(tx, options)
 	}
-	if err := tx.Find(model).Error; err != nil {
-		return false, handleDBError(ctx, db.logger, err, stepFindMany, msgFailedToFind)
+	result := tx.Find(model)
+	if result.Error != nil {
+		return false, handleDBError(ctx, db.logger, result.Error, stepFindMany, msgFailedToFind)
 	}
-	return tx.RowsAffected > 0, nil
+	return result.RowsAffected > 0, nil
 }
 
 // UpdateWhere updates fields on model's table for rows matching conditions.
@@ -148,7 +159,7 @@ func (db *database) Count(ctx context.Context, model any, options *QueryOptions,
 	if err := validate(ctx, model); err != nil {
 		return 0, err
 	}
-	tx := db.instance.WithContext(ctx).Model(model)
+	tx := db.reader(ctx).instance.WithContext(ctx).Model(model)
 	if options != nil {
 		for _, join := range options.Joins {
 			tx = tx.Joins(join)
@@ -168,6 +179,8 @@ func (db *database) Count(ctx context.Context, model any, options *QueryOptions,
 //
 // Pass model=nil for non-SELECT statements (INSERT, UPDATE, DELETE).
 // Pass a pointer to a slice for SELECT statements — results are scanned into it.
+// Statements run on the primary. With WithReplica(ctx), a SELECT without
+// FOR UPDATE/SHARE runs on a replica instead.
 func (db *database) Exec(ctx context.Context, model any, sql string, args ...any) (QueryResult, error) {
 	if ctx == nil {
 		return QueryResult{}, errors.New("context is required")
@@ -177,9 +190,12 @@ func (db *database) Exec(ctx context.Context, model any, sql string, args ...any
 	}
 
 	var tx *gorm.DB
-	if model == nil {
+	switch {
+	case model == nil:
 		tx = db.instance.WithContext(ctx).Exec(sql, args...)
-	} else {
+	case replicaRequested(ctx) && isReadOnlySelect(sql):
+		tx = db.reader(ctx).instance.WithContext(ctx).Raw(sql, args...).Scan(model)
+	default:
 		tx = db.instance.WithContext(ctx).Raw(sql, args...).Scan(model)
 	}
 	if tx.Error != nil {
@@ -203,14 +219,18 @@ func (db *database) WithTransaction(ctx context.Context, fn TransactionFunc) err
 	})
 }
 
-// Close releases the underlying connection pool.
+// Close releases the underlying connection pools.
 // Call once during graceful shutdown, after all in-flight requests have completed.
 func (db *database) Close() error {
 	sqlDB, err := db.instance.DB()
 	if err != nil {
 		return fmt.Errorf("postgresql: failed to get sql.DB for close: %w", err)
 	}
-	return sqlDB.Close()
+	err = sqlDB.Close()
+	if db.replicas != nil {
+		err = errors.Join(err, db.replicas.close())
+	}
+	return err
 }
 
 // --- internal helpers ---
@@ -258,18 +278,24 @@ func applyQueryOptions(tx *gorm.DB, o *QueryOptions) *gorm.DB {
 		tx = tx.Joins(j)
 	}
 	if o.Pagination != nil {
-		page, limit := o.Pagination.Page, o.Pagination.Limit
-		if page < 1 {
-			page = 1
-		}
-		if limit < 1 {
-			limit = 10
-		}
+		page, limit := pageBounds(o.Pagination)
 		tx = tx.Offset((page - 1) * limit).Limit(limit)
 	}
 	return tx
 }
 
+// pageBounds returns p's page and limit with defaults applied.
+func pageBounds(p *PaginationOptions) (page, limit int) {
+	page, limit = p.Page, p.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return page, limit
+}
+
 func handleDBError(ctx context.Context, log logger.Logger, err error, step, message string) error {
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil
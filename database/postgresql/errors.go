@@ -13,4 +13,11 @@ var (
 
 	// ErrInvalidReference is returned when a foreign key constraint fails.
 	ErrInvalidReference = errors.New("invalid reference")
+
+	// ErrNotFound is returned by Repository reads when no record matches.
+	ErrNotFound = errors.New("record not found")
+
+	// ErrInvalidCursor is returned when a keyset cursor is malformed or was issued
+	// for a different column list or sort direction.
+	ErrInvalidCursor = errors.New("invalid cursor")
 )
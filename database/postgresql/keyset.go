@@ -0,0 +1,253 @@
+package postgresql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const defaultKeysetLimit = 10
+
+// FindManyKeyset retrieves one page of records into model (a pointer to a slice)
+// using options.Keyset, and returns the cursors of the neighbouring pages.
+// Rows are returned in the Keyset column order in both directions.
+func (db *database) FindManyKeyset(ctx context.Context, model any, options *QueryOptions, conditions any, args ...any) (KeysetPage, error) {
+	if err := validate(ctx, model); err != nil {
+		return KeysetPage{}, err
+	}
+	if err := validateSlice(model); err != nil {
+		return KeysetPage{}, err
+	}
+	if options == nil || options.Keyset == nil {
+		return KeysetPage{}, errors.New("options.Keyset is required")
+	}
+	return db.reader(ctx).findKeyset(ctx, model, options, conditions, args)
+}
+
+func (db *database) findKeyset(ctx context.Context, model any, options *QueryOptions, conditions any, args []any) (KeysetPage, error) {
+	ks := options.Keyset
+	if err := validateKeyset(options); err != nil {
+		return KeysetPage{}, err
+	}
+	limit := ks.Limit
+	if limit < 1 {
+		limit = defaultKeysetLimit
+	}
+	backward := ks.Before != ""
+	token := ks.After
+	if backward {
+		token = ks.Before
+	}
+
+	tx := db.instance.WithContext(ctx)
+	if conditions != nil {
+		tx = tx.Where(conditions, args...)
+	}
+	tx = applyQueryOptions(tx, options)
+	if token != "" {
+		values, err := decodeCursor(token, ks.Columns)
+		if err != nil {
+			return KeysetPage{}, err
+		}
+		sql, vars := keysetCondition(ks.Columns, values, backward)
+		tx = tx.Where(sql, vars...)
+	}
+	tx = tx.Order(keysetOrder(ks.Columns, backward)).Limit(limit + 1)
+	if err := tx.Find(model).Error; err != nil {
+		return KeysetPage{}, handleDBError(ctx, db.logger, err, stepFindMany, msgFailedToFind)
+	}
+
+	rows := reflect.ValueOf(model).Elem()
+	page := KeysetPage{HasMore: rows.Len() > limit}
+	if page.HasMore {
+		rows.Set(rows.Slice(0, limit))
+	}
+	if backward {
+		reverse(rows)
+	}
+	if rows.Len() == 0 {
+		return page, nil
+	}
+
+	first, err := encodeCursor(ctx, tx, ks.Columns, rows.Index(0))
+	if err != nil {
+		return KeysetPage{}, err
+	}
+	last, err := encodeCursor(ctx, tx, ks.Columns, rows.Index(rows.Len()-1))
+	if err != nil {
+		return KeysetPage{}, err
+	}
+	// Coming from a cursor means there are rows on the side we came from.
+	switch {
+	case backward:
+		page.NextCursor = last
+		if page.HasMore {
+			page.PrevCursor = first
+		}
+	default:
+		if page.HasMore {
+			page.NextCursor = last
+		}
+		if token != "" {
+			page.PrevCursor = first
+		}
+	}
+	return page, nil
+}
+
+func validateKeyset(o *QueryOptions) error {
+	ks := o.Keyset
+	if len(ks.Columns) == 0 {
+		return errors.New("keyset requires at least one column")
+	}
+	for _, c := range ks.Columns {
+		if c.Name == "" {
+			return errors.New("keyset column name is required")
+		}
+	}
+	if ks.After != "" && ks.Before != "" {
+		return errors.New("keyset After and Before are mutually exclusive")
+	}
+	if o.Pagination != nil || o.OrderBy != "" {
+		return errors.New("keyset cannot be combined with Pagination or OrderBy")
+	}
+	return nil
+}
+
+// keysetCondition builds the WHERE clause selecting rows strictly after (or, when
+// backward, before) values in the column order:
+//
+//	(a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND id > ?)
+//
+// with the comparison flipped for descending columns.
+func keysetCondition(cols []KeysetColumn, values []any, backward bool) (string, []any) {
+	var (
+		terms []string
+		vars  []any
+	)
+	for i, c := range cols {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, cols[j].Name+" = ?")
+			vars = append(vars, values[j])
+		}
+		op := ">"
+		if c.Desc != backward {
+			op = "<"
+		}
+		parts = append(parts, c.Name+" "+op+" ?")
+		vars = append(vars, values[i])
+		terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+	}
+	return strings.Join(terms, " OR "), vars
+}
+
+// keysetOrder returns the ORDER BY for cols, reversed when paging backward.
+func keysetOrder(cols []KeysetColumn, backward bool) string {
+	parts := make([]string, len(cols))
+	for i, c := range cols {
+		dir := "ASC"
+		if c.Desc != backward {
+			dir = "DESC"
+		}
+		parts[i] = c.Name + " " + dir
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cursor is the JSON form of a keyset cursor. Sig ties it to the column list and
+// directions it was issued for.
+type cursor struct {
+	Sig    string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+// encodeCursor returns the opaque cursor of row: its Keyset column values and the
+// column signature as base64url-encoded JSON. Columns are matched to model fields
+// by column name, ignoring any table qualifier.
+func encodeCursor(ctx context.Context, tx *gorm.DB, cols []KeysetColumn, row reflect.Value) (string, error) {
+	schema := tx.Statement.Schema
+	if schema == nil {
+		return "", errors.New("keyset requires a GORM model")
+	}
+	row = reflect.Indirect(row)
+	values := make([]any, len(cols))
+	for i, c := range cols {
+		name := c.Name
+		if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+			name = name[dot+1:]
+		}
+		field := schema.LookUpField(strings.Trim(name, `"`))
+		if field == nil {
+			return "", fmt.Errorf("keyset column %q is not a field of %s", c.Name, schema.Name)
+		}
+		values[i], _ = field.ValueOf(ctx, row)
+	}
+	return marshalCursor(cols, values)
+}
+
+func marshalCursor(cols []KeysetColumn, values []any) (string, error) {
+	b, err := json.Marshal(cursor{Sig: keysetSignature(cols), Values: values})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// keysetSignature hashes the column names and directions, so a cursor is rejected
+// when the Keyset it is used with orders differently.
+func keysetSignature(cols []KeysetColumn) string {
+	h := sha256.New()
+	for _, c := range cols {
+		dir := " asc\n"
+		if c.Desc {
+			dir = " desc\n"
+		}
+		h.Write([]byte(c.Name + dir))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// decodeCursor returns the column values in token, which must have been issued for
+// cols. Integers are decoded as int64 so large ids keep their precision; timestamps
+// come back as RFC 3339 strings, which PostgreSQL casts to the column type.
+func decodeCursor(token string, cols []KeysetColumn) ([]any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber()
+	var c cursor
+	if err := dec.Decode(&c); err != nil || c.Sig != keysetSignature(cols) || len(c.Values) != len(cols) {
+		return nil, ErrInvalidCursor
+	}
+	values := c.Values
+	for i, v := range values {
+		num, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if iv, err := num.Int64(); err == nil {
+			values[i] = iv
+		} else if fv, err := num.Float64(); err == nil {
+			values[i] = fv
+		}
+	}
+	return values, nil
+}
+
+func reverse(rows reflect.Value) {
+	swap := reflect.Swapper(rows.Interface())
+	for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
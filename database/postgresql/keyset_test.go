@@ -0,0 +1,129 @@
+package postgresql
+
+import (
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type testEvent struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+func (testEvent) TableName() string { return "events" }
+
+var keysetCols = []KeysetColumn{{Name: "created_at", Desc: true}, {Name: "id", Desc: true}}
+
+func eventRows(events ...testEvent) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "created_at"})
+	for _, e := range events {
+		rows.AddRow(e.ID, e.CreatedAt)
+	}
+	return rows
+}
+
+func TestFindManyKeyset_Navigation(t *testing.T) {
+	db, mock := newMockDB(t)
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev := func(id int64) testEvent { return testEvent{ID: id, CreatedAt: t0.Add(time.Duration(id) * time.Minute)} }
+
+	// First page: newest first, one extra row fetched to detect HasMore.
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "events" WHERE kind = $1 ORDER BY created_at DESC, id DESC LIMIT $2`)).
+		WithArgs("click", 3).
+		WillReturnRows(eventRows(ev(9), ev(8), ev(7)))
+	var page1 []testEvent
+	p1, err := db.FindManyKeyset(ctx, &page1, &QueryOptions{Keyset: &KeysetOptions{Columns: keysetCols, Limit: 2}}, "kind = ?", "click")
+	if err != nil {
+		t.Fatalf("page 1: %v", err)
+	}
+	if len(page1) != 2 || page1[1].ID != 8 || !p1.HasMore || p1.NextCursor == "" || p1.PrevCursor != "" {
+		t.Fatalf("page 1 = %+v, %+v", page1, p1)
+	}
+
+	// Next page: rows after (created_at, id) of event 8 in descending order.
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "events" WHERE kind = $1 AND ((created_at < $2) OR (created_at = $3 AND id < $4)) ORDER BY created_at DESC, id DESC LIMIT $5`)).
+		WithArgs("click", ev(8).CreatedAt.Format(time.RFC3339Nano), ev(8).CreatedAt.Format(time.RFC3339Nano), int64(8), 3).
+		WillReturnRows(eventRows(ev(7)))
+	var page2 []testEvent
+	p2, err := db.FindManyKeyset(ctx, &page2, &QueryOptions{Keyset: &KeysetOptions{Columns: keysetCols, Limit: 2, After: p1.NextCursor}}, "kind = ?", "click")
+	if err != nil {
+		t.Fatalf("page 2: %v", err)
+	}
+	if len(page2) != 1 || p2.HasMore || p2.NextCursor != "" || p2.PrevCursor == "" {
+		t.Fatalf("page 2 = %+v, %+v", page2, p2)
+	}
+
+	// Back from page 2: reversed comparison and order, results flipped back.
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE kind = $1 AND ((created_at > $2) OR (created_at = $3 AND id > $4)) ORDER BY created_at ASC, id ASC LIMIT $5`)).
+		WithArgs("click", sqlmock.AnyArg(), sqlmock.AnyArg(), int64(7), 3).
+		WillReturnRows(eventRows(ev(8), ev(9)))
+	var back []testEvent
+	pb, err := db.FindManyKeyset(ctx, &back, &QueryOptions{Keyset: &KeysetOptions{Columns: keysetCols, Limit: 2, Before: p2.PrevCursor}}, "kind = ?", "click")
+	if err != nil {
+		t.Fatalf("previous page: %v", err)
+	}
+	if len(back) != 2 || back[0].ID != 9 || back[1].ID != 8 || pb.HasMore || pb.PrevCursor != "" || pb.NextCursor == "" {
+		t.Fatalf("previous page = %+v, %+v", back, pb)
+	}
+}
+
+func TestFindMany_Keyset(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "events" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(11).
+		WillReturnRows(eventRows(testEvent{ID: 1}))
+	var events []testEvent
+	found, err := db.FindMany(ctx, &events, &QueryOptions{Keyset: &KeysetOptions{Columns: []KeysetColumn{{Name: "id"}}}}, nil)
+	if err != nil || !found || len(events) != 1 {
+		t.Errorf("FindMany = %v, %+v, %v", found, events, err)
+	}
+}
+
+func TestFindManyKeyset_Validation(t *testing.T) {
+	db, _ := newMockDB(t)
+	var events []testEvent
+	cols := []KeysetColumn{{Name: "id"}}
+	tests := []struct {
+		name string
+		opts *QueryOptions
+	}{
+		{"no keyset", &QueryOptions{}},
+		{"no columns", &QueryOptions{Keyset: &KeysetOptions{}}},
+		{"after and before", &QueryOptions{Keyset: &KeysetOptions{Columns: cols, After: "a", Before: "b"}}},
+		{"with offset pagination", &QueryOptions{Keyset: &KeysetOptions{Columns: cols}, Pagination: &PaginationOptions{Page: 2}}},
+		{"with order by", &QueryOptions{Keyset: &KeysetOptions{Columns: cols}, OrderBy: "id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := db.FindManyKeyset(ctx, &events, tt.opts, nil); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	otherCols, _ := marshalCursor([]KeysetColumn{{Name: "id", Desc: true}}, []any{1})
+	wrongCount, _ := marshalCursor(cols, []any{1, 2})
+	for _, cursor := range []string{"not base64!", "W10", "WzEsMl0", otherCols, wrongCount} { // [], [1,2], other direction, two values
+		_, err := db.FindManyKeyset(ctx, &events, &QueryOptions{Keyset: &KeysetOptions{Columns: cols, After: cursor}}, nil)
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("cursor %q: expected ErrInvalidCursor, got %v", cursor, err)
+		}
+	}
+}
+
+func TestDecodeCursor_KeepsInt64Precision(t *testing.T) {
+	cols := []KeysetColumn{{Name: "id"}, {Name: "score"}, {Name: "name"}}
+	token := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"` + keysetSignature(cols) + `","v":[9007199254740993,1.5,"x"]}`))
+	values, err := decodeCursor(token, cols)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if values[0] != int64(9007199254740993) || values[1] != 1.5 || values[2] != "x" {
+		t.Errorf("values = %#v", values)
+	}
+}
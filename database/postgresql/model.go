@@ -8,11 +8,18 @@ import (
 )
 
 // Database is the interface for all database operations.
+//
+// With Config.Replicas, Find, FindMany, FindManyKeyset, FindManyPage and Count
+// read from a healthy replica, round-robin, unless ctx comes from WithPrimary.
+// Exec uses a replica only for a SELECT whose ctx comes from WithReplica. Writes
+// and everything inside WithTransaction use the primary.
 type Database interface {
 	Create(ctx context.Context, model any) (affectedRows int64, err error)
 	CreateMany(ctx context.Context, models any, batchSize int) (affectedRows int64, err error)
 	Find(ctx context.Context, model any, preloads []string, conditions any, args ...any) (found bool, err error)
 	FindMany(ctx context.Context, model any, options *QueryOptions, conditions any, args ...any) (found bool, err error)
+	FindManyKeyset(ctx context.Context, model any, options *QueryOptions, conditions any, args ...any) (page KeysetPage, err error)
+	FindManyPage(ctx context.Context, model any, options *QueryOptions, conditions any, args ...any) (page PageInfo, err error)
 	UpdateWhere(ctx context.Context, model any, updates any, conditions any, args ...any) (affectedRows int64, err error)
 	Delete(ctx context.Context, model any, conditions any, args ...any) (affectedRows int64, err error)
 	Count(ctx context.Context, model any, options *QueryOptions, conditions any, args ...any) (count int64, err error)
@@ -26,17 +33,62 @@ type Database interface {
 type database struct {
 	instance *gorm.DB
 	logger   logger.Logger
+	replicas *replicaSet // nil without replicas and inside transactions
 }
 
 // PaginationOptions defines 1-based page/limit pagination.
+// SkipTotal and WindowCount only affect FindManyPage and FindPage.
 type PaginationOptions struct {
-	Page  int // 1-based; defaults to 1
-	Limit int // records per page; defaults to 10
+	Page        int  // 1-based; defaults to 1
+	Limit       int  // records per page; defaults to 10
+	SkipTotal   bool // don't count; only report HasMore, from one extra row
+	WindowCount bool // count with COUNT(*) OVER() in the page query instead of a separate COUNT
+}
+
+// PageInfo describes a page returned by FindManyPage.
+type PageInfo struct {
+	Total      int64 // records matching the query; 0 with SkipTotal
+	Page       int   // 1-based page number
+	Limit      int   // records per page
+	TotalPages int   // 0 with SkipTotal
+	HasMore    bool  // later pages exist
+}
+
+// Page is one page of T records with its PageInfo, returned by FindPage.
+type Page[T any] struct {
+	Items []T
+	PageInfo
+}
+
+// KeysetColumn is one sort column of a keyset page.
+type KeysetColumn struct {
+	Name string // column as written in SQL, e.g. "created_at" or "orders.id"; must be NOT NULL
+	Desc bool
+}
+
+// KeysetOptions defines keyset (cursor) pagination: rows are ordered by Columns and
+// a page starts right after (or ends right before) the row a cursor points to, so
+// pages stay stable under concurrent inserts and deep pages cost the same as the first.
+// The last column must be unique (typically the primary key) to break ties.
+type KeysetOptions struct {
+	Columns []KeysetColumn
+	Limit   int    // records per page; defaults to 10
+	After   string // KeysetPage.NextCursor of the previous call; empty = first page
+	Before  string // KeysetPage.PrevCursor of the previous call; exclusive with After
+}
+
+// KeysetPage holds the cursors around a page returned by FindManyKeyset.
+type KeysetPage struct {
+	NextCursor string // pass as After for the following page; empty on the last page
+	PrevCursor string // pass as Before for the preceding page; empty on the first page
+	HasMore    bool   // more rows exist beyond this page in the requested direction
 }
 
 // QueryOptions controls ordering, preloading, joining, and pagination for FindMany and Count.
+// Keyset replaces Pagination and OrderBy; Count ignores it and FindManyPage rejects it.
 type QueryOptions struct {
 	Pagination *PaginationOptions
+	Keyset     *KeysetOptions
 	OrderBy    string
 	Preloads   []string
 	Joins      []string
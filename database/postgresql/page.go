@@ -0,0 +1,172 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pageTotalColumn carries COUNT(*) OVER() in WindowCount page queries.
+const pageTotalColumn = "__page_total"
+
+// FindPage retrieves one page of T records matching conditions with its PageInfo
+// (see Database.FindManyPage). An empty page has empty, non-nil Items.
+//
+//	page, err := postgresql.FindPage[Order](ctx, db, &postgresql.QueryOptions{
+//	    OrderBy:    "created_at DESC, id DESC",
+//	    Pagination: &postgresql.PaginationOptions{Page: req.Page, Limit: req.Limit},
+//	}, "customer_id = ?", customerID)
+func FindPage[T any](ctx context.Context, db Database, options *QueryOptions, conditions any, args ...any) (Page[T], error) {
+	items := []T{}
+	info, err := db.FindManyPage(ctx, &items, options, conditions, args...)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return Page[T]{Items: items, PageInfo: info}, nil
+}
+
+// FindManyPage retrieves one page of records into model (a pointer to a slice) using
+// options.Pagination, page 1 of 10 when nil, and returns its PageInfo. The count and
+// the page query are built from the same conditions and options.Joins, and run on
+// the same replica.
+//
+// By default a COUNT query runs first and the page query is skipped when the page
+// lies past the end. Pagination.WindowCount adds COUNT(*) OVER() to the page query
+// instead, saving a round trip; Pagination.SkipTotal doesn't count at all.
+func (db *database) FindManyPage(ctx context.Context, model any, options *QueryOptions, conditions any, args ...any) (PageInfo, error) {
+	if err := validate(ctx, model); err != nil {
+		return PageInfo{}, err
+	}
+	if err := validateSlice(model); err != nil {
+		return PageInfo{}, err
+	}
+	var opts QueryOptions
+	if options != nil {
+		opts = *options
+	}
+	if opts.Keyset != nil {
+		return PageInfo{}, errors.New("FindManyPage does not support Keyset; use FindManyKeyset")
+	}
+	var p PaginationOptions
+	if opts.Pagination != nil {
+		p = *opts.Pagination
+	}
+	if p.SkipTotal && p.WindowCount {
+		return PageInfo{}, errors.New("pagination SkipTotal and WindowCount are mutually exclusive")
+	}
+	opts.Pagination = nil
+
+	r := db.reader(ctx)
+	page, limit := pageBounds(&p)
+	offset := (page - 1) * limit
+	info := PageInfo{Page: page, Limit: limit}
+	rows := reflect.ValueOf(model).Elem()
+
+	switch {
+	case p.SkipTotal:
+		tx := r.pageQuery(ctx, &opts, conditions, args).Offset(offset).Limit(limit + 1)
+		if err := tx.Find(model).Error; err != nil {
+			return PageInfo{}, handleDBError(ctx, r.logger, err, stepFindMany, msgFailedToFind)
+		}
+		info.HasMore = rows.Len() > limit
+		if info.HasMore {
+			rows.Set(rows.Slice(0, limit))
+		}
+		return info, nil
+
+	case p.WindowCount:
+		total, err := r.findWithTotal(ctx, model, &opts, conditions, args, offset, limit)
+		if err != nil {
+			return PageInfo{}, err
+		}
+		// Past the end no row carries the window count.
+		if rows.Len() == 0 && offset > 0 {
+			if total, err = r.Count(ctx, model, &opts, conditions, args...); err != nil {
+				return PageInfo{}, err
+			}
+		}
+		info.setTotal(total)
+		return info, nil
+
+	default:
+		total, err := r.Count(ctx, model, &opts, conditions, args...)
+		if err != nil {
+			return PageInfo{}, err
+		}
+		if int64(offset) < total {
+			tx := r.pageQuery(ctx, &opts, conditions, args).Offset(offset).Limit(limit)
+			if err := tx.Find(model).Error; err != nil {
+				return PageInfo{}, handleDBError(ctx, r.logger, err, stepFindMany, msgFailedToFind)
+			}
+		} else {
+			rows.Set(reflect.MakeSlice(rows.Type(), 0, 0))
+		}
+		info.setTotal(total)
+		return info, nil
+	}
+}
+
+// pageQuery applies conditions and opts, which must have no Pagination.
+func (db *database) pageQuery(ctx context.Context, opts *QueryOptions, conditions any, args []any) *gorm.DB {
+	tx := db.instance.WithContext(ctx)
+	if conditions != nil {
+		tx = tx.Where(conditions, args...)
+	}
+	return applyQueryOptions(tx, opts)
+}
+
+// findWithTotal runs the page query with COUNT(*) OVER() and returns the count.
+// Rows are scanned into structs embedding the model's element type next to the
+// count column, then copied into model.
+func (db *database) findWithTotal(ctx context.Context, model any, opts *QueryOptions, conditions any, args []any, offset, limit int) (int64, error) {
+	rows := reflect.ValueOf(model).Elem()
+	elem := rows.Type().Elem()
+	record := elem
+	if record.Kind() == reflect.Ptr {
+		record = record.Elem()
+	}
+	if record.Kind() != reflect.Struct {
+		return 0, errors.New("WindowCount requires a slice of structs")
+	}
+	wrapper := reflect.StructOf([]reflect.StructField{
+		{Name: "Record_", Type: record, Tag: `gorm:"embedded"`},
+		{Name: "Total_", Type: reflect.TypeOf(int64(0)), Tag: `gorm:"column:` + pageTotalColumn + `"`},
+	})
+	dest := reflect.New(reflect.SliceOf(wrapper))
+
+	tx := db.pageQuery(ctx, opts, conditions, args).
+		Model(model).
+		Select("?.*, COUNT(*) OVER() AS "+pageTotalColumn, clause.Table{Name: clause.CurrentTable}).
+		Offset(offset).
+		Limit(limit)
+	if err := tx.Find(dest.Interface()).Error; err != nil {
+		return 0, handleDBError(ctx, db.logger, err, stepFindMany, msgFailedToFind)
+	}
+
+	scanned := dest.Elem()
+	out := reflect.MakeSlice(rows.Type(), scanned.Len(), scanned.Len())
+	var total int64
+	for i := range scanned.Len() {
+		r := scanned.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			v := reflect.New(record)
+			v.Elem().Set(r.Field(0))
+			out.Index(i).Set(v)
+		} else {
+			out.Index(i).Set(r.Field(0))
+		}
+		total = r.Field(1).Int()
+	}
+	rows.Set(out)
+	return total, nil
+}
+
+func (p *PageInfo) setTotal(total int64) {
+	limit := int64(p.Limit)
+	p.Total = total
+	p.TotalPages = int((total + limit - 1) / limit)
+	p.HasMore = int64(p.Page)*limit < total
+}
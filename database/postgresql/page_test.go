@@ -0,0 +1,144 @@
+package postgresql
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const pageJoin = "JOIN orders ON orders.user_id = users.id"
+
+func TestFindPage_CountThenQuery(t *testing.T) {
+	db, mock := newMockDB(t)
+	opts := &QueryOptions{
+		OrderBy:    "users.id",
+		Joins:      []string{pageJoin},
+		Pagination: &PaginationOptions{Page: 2, Limit: 2},
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" ` + pageJoin + ` WHERE orders.total > $1`)).
+		WithArgs(100).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "users" `+pageJoin+` WHERE orders.total > $1 ORDER BY users.id LIMIT $2 OFFSET $3`)).
+		WithArgs(100, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "carol").AddRow(4, "dave"))
+
+	page, err := FindPage[testUser](ctx, db, opts, "orders.total > ?", 100)
+	if err != nil {
+		t.Fatalf("FindPage: %v", err)
+	}
+	want := PageInfo{Total: 5, Page: 2, Limit: 2, TotalPages: 3, HasMore: true}
+	if page.PageInfo != want || len(page.Items) != 2 || page.Items[0].Name != "carol" {
+		t.Errorf("page = %+v", page)
+	}
+}
+
+func TestFindPage_PastTheEndSkipsQuery(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	page, err := NewRepository[testUser](db).FindPage(ctx, &QueryOptions{Pagination: &PaginationOptions{Page: 3, Limit: 2}}, nil)
+	if err != nil {
+		t.Fatalf("FindPage: %v", err)
+	}
+	want := PageInfo{Total: 3, Page: 3, Limit: 2, TotalPages: 2}
+	if page.PageInfo != want || page.Items == nil || len(page.Items) != 0 {
+		t.Errorf("page = %#v", page)
+	}
+}
+
+func TestFindPage_WindowCount(t *testing.T) {
+	db, mock := newMockDB(t)
+	opts := &QueryOptions{
+		OrderBy:    "users.id",
+		Joins:      []string{pageJoin},
+		Pagination: &PaginationOptions{Page: 1, Limit: 2, WindowCount: true},
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "users".*, COUNT(*) OVER() AS __page_total FROM "users" `+pageJoin+` WHERE orders.total > $1 ORDER BY users.id LIMIT $2`)).
+		WithArgs(100, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "__page_total"}).AddRow(1, "alice", 3).AddRow(2, "bob", 3))
+
+	var users []*testUser
+	info, err := db.FindManyPage(ctx, &users, opts, "orders.total > ?", 100)
+	if err != nil {
+		t.Fatalf("FindManyPage: %v", err)
+	}
+	want := PageInfo{Total: 3, Page: 1, Limit: 2, TotalPages: 2, HasMore: true}
+	if info != want || len(users) != 2 || users[1].Name != "bob" {
+		t.Errorf("FindManyPage = %+v, %+v", users, info)
+	}
+}
+
+func TestFindPage_WindowCountPastTheEnd(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(`COUNT\(\*\) OVER\(\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "__page_total"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+	page, err := FindPage[testUser](ctx, db, &QueryOptions{Pagination: &PaginationOptions{Page: 5, Limit: 2, WindowCount: true}}, nil)
+	if err != nil {
+		t.Fatalf("FindPage: %v", err)
+	}
+	if page.Total != 4 || page.TotalPages != 2 || page.HasMore || len(page.Items) != 0 {
+		t.Errorf("page = %+v", page)
+	}
+}
+
+func TestFindPage_SkipTotal(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id LIMIT $1 OFFSET $2`)).
+		WithArgs(3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(4).AddRow(5))
+
+	page, err := FindPage[testUser](ctx, db, &QueryOptions{OrderBy: "id", Pagination: &PaginationOptions{Page: 2, Limit: 2, SkipTotal: true}}, nil)
+	if err != nil {
+		t.Fatalf("FindPage: %v", err)
+	}
+	want := PageInfo{Page: 2, Limit: 2, HasMore: true}
+	if page.PageInfo != want || len(page.Items) != 2 || page.Items[1].ID != 4 {
+		t.Errorf("page = %+v", page)
+	}
+}
+
+func TestFindPage_Defaults(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" LIMIT $1`)).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	page, err := FindPage[testUser](ctx, db, nil, nil)
+	if err != nil {
+		t.Fatalf("FindPage: %v", err)
+	}
+	if page.Page != 1 || page.Limit != 10 || page.TotalPages != 1 || page.HasMore {
+		t.Errorf("page = %+v", page)
+	}
+}
+
+func TestFindManyPage_Validation(t *testing.T) {
+	db, _ := newMockDB(t)
+	var users []testUser
+	tests := []struct {
+		name  string
+		model any
+		opts  *QueryOptions
+	}{
+		{"not a slice", &testUser{}, nil},
+		{"keyset", &users, &QueryOptions{Keyset: &KeysetOptions{Columns: []KeysetColumn{{Name: "id"}}}}},
+		{"skip total and window count", &users, &QueryOptions{Pagination: &PaginationOptions{SkipTotal: true, WindowCount: true}}},
+		{"window count over non-structs", &[]int64{}, &QueryOptions{Pagination: &PaginationOptions{WindowCount: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := db.FindManyPage(ctx, tt.model, tt.opts, nil); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/juanMaAV92/go-utils/logger"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReplicaCooldown = 30 * time.Second
+	replicaPingTimeout     = 5 * time.Second
+	replicaCallback        = "postgresql:replica_health"
+
+	stepReplica = "db.replica"
+)
+
+type (
+	primaryKey struct{}
+	replicaKey struct{}
+)
+
+// lockingClause matches the row-locking clauses of a SELECT, which need the primary.
+var lockingClause = regexp.MustCompile(`(?i)\bFOR\s+(NO\s+KEY\s+UPDATE|KEY\s+SHARE|UPDATE|SHARE)\b`)
+
+// WithPrimary returns a context whose reads go to the primary instead of a replica,
+// e.g. to read a record right after writing it despite replication lag.
+//
+//	_, err := db.Create(ctx, &order)
+//	found, err := db.Find(postgresql.WithPrimary(ctx), &order, nil, "id = ?", order.ID)
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func primaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+// WithReplica returns a context that lets Exec run a read-only SELECT on a replica.
+// Raw SQL goes to the primary by default, since Exec cannot tell whether a
+// statement has side effects; SELECT ... FOR UPDATE/SHARE stays on the primary
+// even with WithReplica. WithPrimary takes precedence.
+//
+//	var totals []OrderTotal
+//	_, err := db.Exec(postgresql.WithReplica(ctx), &totals, "SELECT customer_id, sum(amount) FROM orders GROUP BY 1")
+func WithReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaKey{}, true)
+}
+
+func replicaRequested(ctx context.Context) bool {
+	requested, _ := ctx.Value(replicaKey{}).(bool)
+	return requested
+}
+
+// reader returns the database a read runs on: the next healthy replica, or db itself
+// when there are no replicas (as inside WithTransaction), when ctx forces the primary,
+// or when every replica is ejected.
+func (db *database) reader(ctx context.Context) *database {
+	if db.replicas == nil || primaryForced(ctx) {
+		return db
+	}
+	if r := db.replicas.pick(); r != nil {
+		return r.db
+	}
+	return db
+}
+
+// replica is one read replica. A connection error ejects it for the cooldown;
+// afterwards it is pinged and re-admitted once the ping succeeds.
+type replica struct {
+	host         string
+	db           *database    // view over the replica pool, without replicas of its own
+	ejectedUntil atomic.Int64 // unix nanoseconds; 0 while healthy
+	probing      atomic.Bool
+}
+
+type replicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+	cooldown time.Duration
+	logger   logger.Logger
+}
+
+// openReplicas opens a pool per cfg.Replicas entry with the primary's credentials.
+// An unreachable replica does not fail startup: it starts ejected.
+func openReplicas(cfg Config, log logger.Logger) (*replicaSet, error) {
+	s := &replicaSet{cooldown: cfg.ReplicaCooldown, logger: log}
+	if s.cooldown <= 0 {
+		s.cooldown = defaultReplicaCooldown
+	}
+	for _, addr := range cfg.Replicas {
+		rc := replicaConfig(cfg, addr)
+		instance, err := gorm.Open(postgres.Open(buildDSN(rc)), &gorm.Config{
+			Logger:               buildGormLogger(cfg.Verbose),
+			DisableAutomaticPing: true,
+		})
+		if err == nil {
+			err = setupPool(instance, rc)
+		}
+		if err != nil {
+			_ = s.close()
+			return nil, fmt.Errorf("failed to open replica %s: %w", rc.Host, err)
+		}
+		r, err := s.add(rc.Host, instance)
+		if err != nil {
+			_ = s.close()
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+		if err := r.ping(ctx); err != nil {
+			s.eject(ctx, r, err)
+		}
+		cancel()
+	}
+	return s, nil
+}
+
+// add registers instance as a replica and hooks its queries for health tracking.
+func (s *replicaSet) add(host string, instance *gorm.DB) (*replica, error) {
+	r := &replica{host: host, db: &database{instance: instance, logger: s.logger}}
+	observe := func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if isConnError(tx.Error) && ctx.Err() == nil {
+			s.eject(ctx, r, tx.Error)
+		}
+	}
+	cb := instance.Callback()
+	for _, err := range []error{
+		cb.Query().After("*").Register(replicaCallback, observe),
+		cb.Row().After("*").Register(replicaCallback, observe),
+		cb.Raw().After("*").Register(replicaCallback, observe),
+	} {
+		if err != nil {
+			return nil, fmt.Errorf("failed to register replica health callback: %w", err)
+		}
+	}
+	s.replicas = append(s.replicas, r)
+	return r, nil
+}
+
+// pick returns the next healthy replica round-robin, or nil when all are ejected.
+// Ejected replicas whose cooldown has elapsed are probed in the background.
+func (s *replicaSet) pick() *replica {
+	n := uint64(len(s.replicas))
+	start := s.next.Add(1)
+	now := time.Now().UnixNano()
+	for i := range n {
+		r := s.replicas[(start+i)%n]
+		until := r.ejectedUntil.Load()
+		if until == 0 {
+			return r
+		}
+		if now >= until {
+			s.probe(r)
+		}
+	}
+	return nil
+}
+
+func (s *replicaSet) eject(ctx context.Context, r *replica, err error) {
+	until := time.Now().Add(s.cooldown).UnixNano()
+	if r.ejectedUntil.Swap(until) == 0 && s.logger != nil {
+		s.logger.Warning(ctx, stepReplica, "replica ejected", "host", r.host, "error", err.Error())
+	}
+}
+
+// probe pings r once, unless a probe is already running, and re-admits it on success.
+func (s *replicaSet) probe(r *replica) {
+	if !r.probing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer r.probing.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+		defer cancel()
+		if err := r.ping(ctx); err != nil {
+			r.ejectedUntil.Store(time.Now().Add(s.cooldown).UnixNano())
+			return
+		}
+		r.ejectedUntil.Store(0)
+		if s.logger != nil {
+			s.logger.Info(ctx, stepReplica, "replica re-admitted", "host", r.host)
+		}
+	}()
+}
+
+func (r *replica) ping(ctx context.Context) error {
+	sqlDB, err := r.db.instance.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (s *replicaSet) close() error {
+	var errs []error
+	for _, r := range s.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// replicaConfig returns cfg pointed at addr, "host" or "host:port"; the port
+// defaults to the primary's.
+func replicaConfig(cfg Config, addr string) Config {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, cfg.Port
+	}
+	cfg.Host, cfg.Port, cfg.Replicas = host, port, nil
+	return cfg
+}
+
+// isConnError reports whether err means the server or the connection to it failed,
+// rather than the query: network errors, broken connections, connection_exception
+// (08) and operator_intervention (57P: shutdown, starting up, recovery) codes.
+func isConnError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P")
+	}
+	var (
+		connErr *pgconn.ConnectError
+		netErr  net.Error
+	)
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &connErr) || errors.As(err, &netErr)
+}
+
+// isReadOnlySelect reports whether sql is a plain SELECT without a row-locking
+// clause, which Exec may send to a replica under WithReplica.
+func isReadOnlySelect(sql string) bool {
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+	if len(sql) < 6 || !strings.EqualFold(sql[:6], "select") {
+		return false
+	}
+	if len(sql) > 6 && !strings.ContainsRune(" \t\r\n*(", rune(sql[6])) {
+		return false
+	}
+	return !lockingClause.MatchString(sql)
+}
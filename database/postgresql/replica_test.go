@@ -0,0 +1,271 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// newReplicatedMockDB returns a database with a sqlmock primary and n sqlmock
+// replicas. Replica pings are monitored so tests can script health probes.
+func newReplicatedMockDB(t *testing.T, n int, cooldown time.Duration) (*database, sqlmock.Sqlmock, []sqlmock.Sqlmock) {
+	t.Helper()
+	primary, primaryMock := newMockDB(t)
+	db := primary.(*database)
+	db.replicas = &replicaSet{cooldown: cooldown}
+
+	mocks := make([]sqlmock.Sqlmock, n)
+	for i := range n {
+		sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		if err != nil {
+			t.Fatalf("sqlmock: %v", err)
+		}
+		gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger:               gormLogger.Default.LogMode(gormLogger.Silent),
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			t.Fatalf("gorm: %v", err)
+		}
+		if _, err := db.replicas.add("replica", gdb); err != nil {
+			t.Fatalf("add replica: %v", err)
+		}
+		t.Cleanup(func() {
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("replica %d: %v", i, err)
+			}
+		})
+		mocks[i] = mock
+	}
+	return db, primaryMock, mocks
+}
+
+func userRows(ids ...int64) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, id := range ids {
+		rows.AddRow(id)
+	}
+	return rows
+}
+
+func TestReplicas_ReadsRoundRobin(t *testing.T) {
+	db, _, replicas := newReplicatedMockDB(t, 2, time.Minute)
+	for _, r := range replicas {
+		r.ExpectQuery(`SELECT \* FROM "users" WHERE id = \$1`).WillReturnRows(userRows(1))
+		r.ExpectQuery(`SELECT count\(\*\) FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		r.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM users`)).WillReturnRows(userRows(1))
+	}
+
+	for range 2 {
+		var u testUser
+		if found, err := db.Find(ctx, &u, nil, "id = ?", 1); err != nil || !found {
+			t.Errorf("Find = %v, %v", found, err)
+		}
+	}
+	for range 2 {
+		if n, err := db.Count(ctx, &testUser{}, nil, nil); err != nil || n != 3 {
+			t.Errorf("Count = %d, %v", n, err)
+		}
+	}
+	for range 2 {
+		var users []testUser
+		if _, err := db.Exec(WithReplica(ctx), &users, "SELECT id FROM users"); err != nil {
+			t.Errorf("Exec SELECT: %v", err)
+		}
+	}
+}
+
+func TestReplicas_WritesAndTransactionsUsePrimary(t *testing.T) {
+	db, primary, _ := newReplicatedMockDB(t, 1, time.Minute)
+
+	primary.ExpectBegin()
+	primary.ExpectQuery(`INSERT INTO "users"`).WillReturnRows(userRows(9))
+	primary.ExpectCommit()
+	if _, err := db.Create(ctx, &testUser{Name: "alice"}); err != nil {
+		t.Errorf("Create: %v", err)
+	}
+
+	primary.ExpectExec(`UPDATE users SET name = \$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := db.Exec(ctx, nil, "UPDATE users SET name = ?", "bob"); err != nil {
+		t.Errorf("Exec UPDATE: %v", err)
+	}
+
+	primary.ExpectQuery(`WITH gone AS`).WillReturnRows(userRows(9))
+	var deleted []testUser
+	if _, err := db.Exec(ctx, &deleted, "WITH gone AS (DELETE FROM users RETURNING id) SELECT id FROM gone"); err != nil {
+		t.Errorf("Exec WITH: %v", err)
+	}
+
+	primary.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM users`)).WillReturnRows(userRows(9))
+	var users []testUser
+	if _, err := db.Exec(ctx, &users, "SELECT id FROM users"); err != nil {
+		t.Errorf("Exec SELECT without WithReplica: %v", err)
+	}
+
+	primary.ExpectQuery(`FOR UPDATE`).WillReturnRows(userRows(9))
+	if _, err := db.Exec(WithReplica(ctx), &users, "SELECT id FROM users WHERE id = ? FOR UPDATE", 9); err != nil {
+		t.Errorf("Exec SELECT FOR UPDATE: %v", err)
+	}
+
+	primary.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM users`)).WillReturnRows(userRows(9))
+	if _, err := db.Exec(WithPrimary(WithReplica(ctx)), &users, "SELECT id FROM users"); err != nil {
+		t.Errorf("Exec with WithPrimary and WithReplica: %v", err)
+	}
+
+	primary.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows(9))
+	if found, err := db.Find(WithPrimary(ctx), &testUser{}, nil, "id = ?", 9); err != nil || !found {
+		t.Errorf("Find with WithPrimary = %v, %v", found, err)
+	}
+
+	primary.ExpectBegin()
+	primary.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows(9))
+	primary.ExpectCommit()
+	err := db.WithTransaction(ctx, func(tx Database) error {
+		_, err := tx.Find(ctx, &testUser{}, nil, "id = ?", 9)
+		return err
+	})
+	if err != nil {
+		t.Errorf("WithTransaction: %v", err)
+	}
+}
+
+func TestReplicas_EjectOnConnectionError(t *testing.T) {
+	db, _, replicas := newReplicatedMockDB(t, 2, time.Hour)
+
+	// A query error leaves the replica in rotation; a connection error ejects it.
+	replicas[1].ExpectQuery(`SELECT`).WillReturnError(&pgconn.PgError{Code: "42P01"})
+	replicas[0].ExpectQuery(`SELECT`).WillReturnRows(userRows(1))
+	replicas[1].ExpectQuery(`SELECT`).WillReturnError(io.ErrUnexpectedEOF)
+	replicas[0].ExpectQuery(`SELECT`).WillReturnRows(userRows(1))
+
+	var users []testUser
+	if _, err := db.FindMany(ctx, &users, nil, nil); err == nil {
+		t.Error("expected query error")
+	}
+	for range 2 {
+		_, _ = db.FindMany(ctx, &users, nil, nil)
+	}
+	if db.replicas.replicas[1].ejectedUntil.Load() == 0 {
+		t.Fatal("replica 1 not ejected")
+	}
+	if found, err := db.FindMany(ctx, &users, nil, nil); err != nil || !found {
+		t.Errorf("FindMany on remaining replica = %v, %v", found, err)
+	}
+	if db.replicas.replicas[0].ejectedUntil.Load() != 0 {
+		t.Error("healthy replica ejected")
+	}
+}
+
+func TestReplicas_AllEjectedFallBackToPrimaryThenReadmit(t *testing.T) {
+	db, primary, replicas := newReplicatedMockDB(t, 1, time.Millisecond)
+	r := db.replicas.replicas[0]
+
+	replicas[0].ExpectQuery(`SELECT`).WillReturnError(&net.OpError{Op: "read", Err: errors.New("connection reset")})
+	var users []testUser
+	_, _ = db.FindMany(ctx, &users, nil, nil)
+	if r.ejectedUntil.Load() == 0 {
+		t.Fatal("replica not ejected")
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The cooldown elapsed: this read goes to the primary while the replica is probed.
+	replicas[0].ExpectPing()
+	primary.ExpectQuery(`SELECT`).WillReturnRows(userRows(1))
+	if found, err := db.FindMany(ctx, &users, nil, nil); err != nil || !found {
+		t.Fatalf("FindMany on primary = %v, %v", found, err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for r.ejectedUntil.Load() != 0 || r.probing.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("replica not re-admitted after a successful ping")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	replicas[0].ExpectQuery(`SELECT`).WillReturnRows(userRows(1))
+	if found, err := db.FindMany(ctx, &users, nil, nil); err != nil || !found {
+		t.Errorf("FindMany on re-admitted replica = %v, %v", found, err)
+	}
+}
+
+func TestIsReadOnlySelect(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT id FROM users":        true,
+		"  select * from users":       true,
+		"(SELECT 1) UNION (SELECT 2)": true,
+		"SELECT":                      true,
+		"SELECT id FROM jobs WHERE format = 'for update'": false,
+		"SELECT id FROM jobs FOR UPDATE SKIP LOCKED":      false,
+		"select id from jobs for share":                   false,
+		"SELECT id FROM jobs FOR NO KEY UPDATE":           false,
+		"SELECT id FROM jobs\nFOR\tKEY SHARE":             false,
+		"UPDATE users SET name = 'x'":                     false,
+		"WITH x AS (SELECT 1) SELECT *":                   false,
+		"selected":                                        false,
+		"":                                                false,
+	}
+	for sql, want := range tests {
+		if got := isReadOnlySelect(sql); got != want {
+			t.Errorf("isReadOnlySelect(%q) = %v, want %v", sql, got, want)
+		}
+	}
+}
+
+func TestIsConnError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{gorm.ErrRecordNotFound, false},
+		{&pgconn.PgError{Code: pgUniqueViolation}, false},
+		{&pgconn.PgError{Code: "57014"}, false}, // query_canceled
+		{&pgconn.PgError{Code: "57P01"}, true},  // admin_shutdown
+		{&pgconn.PgError{Code: "08006"}, true},  // connection_failure
+		{io.ErrUnexpectedEOF, true},
+		{&net.OpError{Op: "dial", Err: errors.New("refused")}, true},
+		{fmt.Errorf("read: %w", context.DeadlineExceeded), false},
+	}
+	for _, tt := range tests {
+		if got := isConnError(tt.err); got != tt.want {
+			t.Errorf("isConnError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestReplicaConfig(t *testing.T) {
+	cfg := Config{Host: "primary", Port: "5432", User: "app", Replicas: []string{"r1", "r2:6432"}}
+	if rc := replicaConfig(cfg, "r1"); rc.Host != "r1" || rc.Port != "5432" || rc.User != "app" || rc.Replicas != nil {
+		t.Errorf("replicaConfig(r1) = %+v", rc)
+	}
+	if rc := replicaConfig(cfg, "r2:6432"); rc.Host != "r2" || rc.Port != "6432" {
+		t.Errorf("replicaConfig(r2:6432) = %+v", rc)
+	}
+}
+
+func TestConfigFromEnv_Replicas(t *testing.T) {
+	t.Setenv("RDB_HOST", "primary")
+	t.Setenv("RDB_USER", "app")
+	t.Setenv("RDB_PASSWORD", "secret")
+	t.Setenv("RDB_NAME", "orders")
+	t.Setenv("RDB_REPLICA_HOSTS", "replica-1, replica-2:6432,")
+	t.Setenv("RDB_REPLICA_COOLDOWN", "10s")
+	cfg, err := ConfigFromEnv("RDB")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if len(cfg.Replicas) != 2 || cfg.Replicas[1] != "replica-2:6432" || cfg.ReplicaCooldown != 10*time.Second {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
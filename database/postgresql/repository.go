@@ -0,0 +1,99 @@
+package postgresql
+
+import (
+	"context"
+
+	"gorm.io/gorm/clause"
+)
+
+// Repository is a type-safe view of a Database for the GORM model T (a struct,
+// not a pointer). Reads return T values and ErrNotFound instead of found flags;
+// errors are mapped exactly as by the Database methods it calls.
+//
+//	users := postgresql.NewRepository[User](db)
+//	u, err := users.GetByID(ctx, 42, "Profile")
+//	if errors.Is(err, postgresql.ErrNotFound) {
+//	    return errors.ErrNotFound("user not found")
+//	}
+type Repository[T any] struct {
+	db Database
+}
+
+// NewRepository returns a Repository for T backed by db.
+func NewRepository[T any](db Database) *Repository[T] {
+	return &Repository[T]{db: db}
+}
+
+// WithTx returns a Repository bound to tx, the Database passed to a WithTransaction
+// callback, so several repositories can share one transaction.
+func (r *Repository[T]) WithTx(tx Database) *Repository[T] {
+	return &Repository[T]{db: tx}
+}
+
+// WithTransaction runs fn with a Repository scoped to a new transaction, like
+// Database.WithTransaction. Return nil to commit, return an error to rollback.
+func (r *Repository[T]) WithTransaction(ctx context.Context, fn func(tx *Repository[T]) error) error {
+	return r.db.WithTransaction(ctx, func(tx Database) error {
+		return fn(r.WithTx(tx))
+	})
+}
+
+// GetByID returns the record whose primary key is id, or ErrNotFound.
+// preloads lists relations to eager-load.
+func (r *Repository[T]) GetByID(ctx context.Context, id any, preloads ...string) (T, error) {
+	return r.FindOne(ctx, preloads, clause.Eq{Column: clause.PrimaryColumn, Value: id})
+}
+
+// FindOne returns the first record matching conditions (see Database.Find), or ErrNotFound.
+func (r *Repository[T]) FindOne(ctx context.Context, preloads []string, conditions any, args ...any) (T, error) {
+	var v T
+	found, err := r.db.Find(ctx, &v, preloads, conditions, args...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if !found {
+		var zero T
+		return zero, ErrNotFound
+	}
+	return v, nil
+}
+
+// List returns all records matching conditions (see Database.FindMany).
+// An empty result is an empty slice, not an error.
+func (r *Repository[T]) List(ctx context.Context, options *QueryOptions, conditions any, args ...any) ([]T, error) {
+	items := []T{}
+	if _, err := r.db.FindMany(ctx, &items, options, conditions, args...); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FindPage returns one page of records matching conditions with its total (see FindPage).
+func (r *Repository[T]) FindPage(ctx context.Context, options *QueryOptions, conditions any, args ...any) (Page[T], error) {
+	return FindPage[T](ctx, r.db, options, conditions, args...)
+}
+
+// Create inserts entity; generated fields such as the primary key are set on it.
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	_, err := r.db.Create(ctx, entity)
+	return err
+}
+
+// Update applies updates to the records matching conditions (see Database.UpdateWhere)
+// and returns the number of rows updated. Pass a map to write zero values.
+func (r *Repository[T]) Update(ctx context.Context, updates any, conditions any, args ...any) (int64, error) {
+	return r.db.UpdateWhere(ctx, new(T), updates, conditions, args...)
+}
+
+// Delete removes the records matching conditions (soft delete when T has gorm.DeletedAt)
+// and returns the number of rows deleted. nil conditions are rejected rather than
+// deleting every row.
+func (r *Repository[T]) Delete(ctx context.Context, conditions any, args ...any) (int64, error) {
+	return r.db.Delete(ctx, new(T), conditions, args...)
+}
+
+// Exists reports whether any record matches conditions.
+func (r *Repository[T]) Exists(ctx context.Context, conditions any, args ...any) (bool, error) {
+	return r.db.Find(ctx, new(T), nil, conditions, args...)
+}
@@ -0,0 +1,163 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+type testUser struct {
+	ID    int64
+	Name  string
+	Email string
+}
+
+func (testUser) TableName() string { return "users" }
+
+var ctx = context.Background()
+
+// newMockDB returns a Database whose queries go to a sqlmock connection.
+func newMockDB(t *testing.T) (Database, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	return &database{instance: gdb}, mock
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	db, mock := newMockDB(t)
+	users := NewRepository[testUser](db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1 ORDER BY "users"."id" LIMIT $2`)).
+		WithArgs(42, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(42, "alice", "alice@example.com"))
+	u, err := users.GetByID(ctx, 42)
+	if err != nil || u.ID != 42 || u.Name != "alice" {
+		t.Errorf("GetByID = %+v, %v", u, err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err := users.GetByID(ctx, 7); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing row: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRepository_FindOneAndExists(t *testing.T) {
+	db, mock := newMockDB(t)
+	users := NewRepository[testUser](db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE email = $1`)).
+		WithArgs("alice@example.com", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(1, "alice@example.com"))
+	if u, err := users.FindOne(ctx, nil, "email = ?", "alice@example.com"); err != nil || u.ID != 1 {
+		t.Errorf("FindOne = %+v, %v", u, err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE email = \$1`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if ok, err := users.Exists(ctx, "email = ?", "bob@example.com"); err != nil || ok {
+		t.Errorf("Exists = %v, %v", ok, err)
+	}
+}
+
+func TestRepository_List(t *testing.T) {
+	db, mock := newMockDB(t)
+	users := NewRepository[testUser](db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE name LIKE $1 ORDER BY id`)).
+		WithArgs("a%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "alice").AddRow(2, "anna"))
+	list, err := users.List(ctx, &QueryOptions{OrderBy: "id"}, "name LIKE ?", "a%")
+	if err != nil || len(list) != 2 || list[1].Name != "anna" {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	list, err = users.List(ctx, nil, nil)
+	if err != nil || list == nil || len(list) != 0 {
+		t.Errorf("empty List = %#v, %v", list, err)
+	}
+}
+
+func TestRepository_CreateMapsErrors(t *testing.T) {
+	db, mock := newMockDB(t)
+	users := NewRepository[testUser](db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "users"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectCommit()
+	u := testUser{Name: "alice"}
+	if err := users.Create(ctx, &u); err != nil || u.ID != 9 {
+		t.Errorf("Create = %+v, %v", u, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "users"`).WillReturnError(&pgconn.PgError{Code: pgUniqueViolation, Detail: "email taken"})
+	mock.ExpectRollback()
+	if err := users.Create(ctx, &testUser{Name: "alice"}); !errors.Is(err, ErrDuplicateRecord) {
+		t.Errorf("expected ErrDuplicateRecord, got %v", err)
+	}
+}
+
+func TestRepository_UpdateAndDelete(t *testing.T) {
+	db, mock := newMockDB(t)
+	users := NewRepository[testUser](db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "name"=$1 WHERE id = $2`)).
+		WithArgs("bob", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if n, err := users.Update(ctx, map[string]any{"name": "bob"}, "id = ?", 1); err != nil || n != 1 {
+		t.Errorf("Update = %d, %v", n, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "users" WHERE id = $1`)).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if n, err := users.Delete(ctx, "id = ?", 1); err != nil || n != 1 {
+		t.Errorf("Delete = %d, %v", n, err)
+	}
+
+	if _, err := users.Delete(ctx, nil); err == nil {
+		t.Error("Delete without conditions must fail")
+	}
+}
+
+func TestRepository_WithTransaction(t *testing.T) {
+	db, mock := newMockDB(t)
+	users := NewRepository[testUser](db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "users"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectRollback()
+	rollback := errors.New("rollback")
+	err := users.WithTransaction(ctx, func(tx *Repository[testUser]) error {
+		if err := tx.Create(ctx, &testUser{Name: "alice"}); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Errorf("WithTransaction = %v", err)
+	}
+}
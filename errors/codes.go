@@ -7,8 +7,10 @@ const (
 	StatusNotFoundCode              = "NOT_FOUND"
 	StatusMethodNotAllowedCode      = "METHOD_NOT_ALLOWED"
 	StatusRequestTimeoutCode        = "REQUEST_TIMEOUT"
+	StatusConflictCode              = "CONFLICT"
 	StatusRequestEntityTooLargeCode = "REQUEST_ENTITY_TOO_LARGE"
 	StatusUnsupportedMediaTypeCode  = "UNSUPPORTED_MEDIA_TYPE"
+	StatusUnprocessableEntityCode   = "UNPROCESSABLE_ENTITY"
 	StatusTooManyRequestsCode       = "TOO_MANY_REQUESTS"
 	StatusInternalServerErrorCode   = "INTERNAL_ERROR"
 	StatusBadGatewayCode            = "BAD_GATEWAY"
@@ -48,12 +48,16 @@ func echoErrToResponse(e *echo.HTTPError) *errors.ErrorResponse {
 		return errors.ErrMethodNotAllowed()
 	case http.StatusRequestTimeout:
 		return errors.ErrRequestTimeout()
+	case http.StatusConflict:
+		return errors.ErrConflict()
 	case http.StatusTooManyRequests:
 		return errors.ErrTooManyRequests()
 	case http.StatusRequestEntityTooLarge:
 		return errors.ErrRequestEntityTooLarge()
 	case http.StatusUnsupportedMediaType:
 		return errors.ErrUnsupportedMediaType()
+	case http.StatusUnprocessableEntity:
+		return errors.ErrUnprocessableEntity()
 	case http.StatusInternalServerError:
 		return errors.ErrInternalServer()
 	case http.StatusBadGateway:
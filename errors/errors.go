@@ -74,6 +74,10 @@ func ErrRequestTimeout(messages ...string) *ErrorResponse {
 	return &ErrorResponse{HttpCode: http.StatusRequestTimeout, Code: StatusRequestTimeoutCode, Messages: defaultMessages(messages, "request timeout")}
 }
 
+func ErrConflict(messages ...string) *ErrorResponse {
+	return &ErrorResponse{HttpCode: http.StatusConflict, Code: StatusConflictCode, Messages: defaultMessages(messages, "conflict")}
+}
+
 func ErrTooManyRequests(messages ...string) *ErrorResponse {
 	return &ErrorResponse{HttpCode: http.StatusTooManyRequests, Code: StatusTooManyRequestsCode, Messages: defaultMessages(messages, "too many requests")}
 }
@@ -86,6 +90,10 @@ func ErrUnsupportedMediaType(messages ...string) *ErrorResponse {
 	return &ErrorResponse{HttpCode: http.StatusUnsupportedMediaType, Code: StatusUnsupportedMediaTypeCode, Messages: defaultMessages(messages, "unsupported media type")}
 }
 
+func ErrUnprocessableEntity(messages ...string) *ErrorResponse {
+	return &ErrorResponse{HttpCode: http.StatusUnprocessableEntity, Code: StatusUnprocessableEntityCode, Messages: defaultMessages(messages, "unprocessable entity")}
+}
+
 func ErrInternalServer(messages ...string) *ErrorResponse {
 	return &ErrorResponse{HttpCode: http.StatusInternalServerError, Code: StatusInternalServerErrorCode, Messages: defaultMessages(messages, "internal server error")}
 }
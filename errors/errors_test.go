@@ -81,9 +81,11 @@ func TestAllPredefinedHaveCorrectStatusCodes(t *testing.T) {
 		{"NotFound", ErrNotFound(), http.StatusNotFound},
 		{"MethodNotAllowed", ErrMethodNotAllowed(), http.StatusMethodNotAllowed},
 		{"RequestTimeout", ErrRequestTimeout(), http.StatusRequestTimeout},
+		{"Conflict", ErrConflict(), http.StatusConflict},
 		{"TooManyRequests", ErrTooManyRequests(), http.StatusTooManyRequests},
 		{"RequestEntityTooLarge", ErrRequestEntityTooLarge(), http.StatusRequestEntityTooLarge},
 		{"UnsupportedMediaType", ErrUnsupportedMediaType(), http.StatusUnsupportedMediaType},
+		{"UnprocessableEntity", ErrUnprocessableEntity(), http.StatusUnprocessableEntity},
 		{"InternalServer", ErrInternalServer(), http.StatusInternalServerError},
 		{"BadGateway", ErrBadGateway(), http.StatusBadGateway},
 		{"ServiceUnavailable", ErrServiceUnavailable(), http.StatusServiceUnavailable},
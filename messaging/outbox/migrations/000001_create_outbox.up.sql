@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id               BIGSERIAL   PRIMARY KEY,
+    destination      TEXT        NOT NULL,
+    body             TEXT        NOT NULL,
+    attributes       JSONB       NOT NULL DEFAULT '{}',
+    trace_context    JSONB       NOT NULL DEFAULT '{}',
+    subject          TEXT        NOT NULL DEFAULT '',
+    message_group_id TEXT        NOT NULL DEFAULT '',
+    deduplication_id TEXT        NOT NULL DEFAULT '',
+    attempts         INTEGER     NOT NULL DEFAULT 0,
+    last_error       TEXT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    available_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at          TIMESTAMPTZ,
+    failed_at        TIMESTAMPTZ -- set after MaxAttempts failed publishes; the relay skips the row
+);
+
+-- Due rows for the relay's claim query; sent and failed rows drop out of the index.
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (available_at, id) WHERE sent_at IS NULL AND failed_at IS NULL;
+
+-- Unsent rows per FIFO group, for the claim query's check that no earlier row
+-- of the group is waiting for a retry.
+CREATE INDEX IF NOT EXISTS outbox_group_idx ON outbox (destination, message_group_id, id)
+    WHERE sent_at IS NULL AND failed_at IS NULL AND message_group_id <> '';
+
+-- Sent rows for retention cleanup.
+CREATE INDEX IF NOT EXISTS outbox_sent_at_idx ON outbox (sent_at) WHERE sent_at IS NOT NULL;
@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/database/postgresql"
+	"github.com/juanMaAV92/go-utils/env"
+)
+
+// tableName matches a plain or schema-qualified SQL identifier; the table name
+// is written into the SQL as is.
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Config holds the outbox table and relay settings.
+type Config struct {
+	Table        string        // default "outbox"; may be schema-qualified
+	BatchSize    int           // rows claimed per relay transaction, default 100
+	PollInterval time.Duration // relay wait when no rows are due, default 1s
+	MinBackoff   time.Duration // retry delay after the first failed publish, default 1s; doubles per attempt
+	MaxBackoff   time.Duration // retry delay cap, default 5m
+	MaxAttempts  int           // failed publishes before a row is marked failed and no longer retried, default 20; negative retries forever
+	Retention    time.Duration // sent rows older than this are deleted, default 24h; negative keeps them
+}
+
+// ConfigFromEnv reads outbox configuration from environment variables.
+//
+//	ConfigFromEnv("OUTBOX")       → OUTBOX_TABLE, OUTBOX_BATCH_SIZE, …
+//	ConfigFromEnv("ORDER_OUTBOX") → ORDER_OUTBOX_TABLE, …
+//
+// All variables are optional.
+func ConfigFromEnv(prefix string) (Config, error) {
+	p := prefix + "_"
+	cfg := Config{
+		Table:        env.GetEnvWithDefault(p+"TABLE", "outbox"),
+		BatchSize:    env.GetEnvAsIntWithDefault(p+"BATCH_SIZE", 100),
+		PollInterval: env.GetEnvAsDurationWithDefault(p+"POLL_INTERVAL", time.Second),
+		MinBackoff:   env.GetEnvAsDurationWithDefault(p+"MIN_BACKOFF", time.Second),
+		MaxBackoff:   env.GetEnvAsDurationWithDefault(p+"MAX_BACKOFF", 5*time.Minute),
+		MaxAttempts:  env.GetEnvAsIntWithDefault(p+"MAX_ATTEMPTS", 20),
+		Retention:    env.GetEnvAsDurationWithDefault(p+"RETENTION", 24*time.Hour),
+	}
+	if !tableName.MatchString(cfg.Table) {
+		return Config{}, fmt.Errorf("outbox: invalid table name in %s: %q", p+"TABLE", cfg.Table)
+	}
+	return cfg, nil
+}
+
+func (c Config) withDefaults() Config {
+	if c.Table == "" {
+		c.Table = "outbox"
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 5 * time.Minute
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = c.MinBackoff
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 20
+	}
+	if c.Retention == 0 {
+		c.Retention = 24 * time.Hour
+	}
+	return c
+}
+
+// Event is a message published by the relay once the transaction that added it commits.
+type Event struct {
+	Destination string            // required; selects the relay's Publisher
+	Body        string            // required
+	Attributes  map[string]string // optional; max 6, so SNS/SQS can add the trace context within their limit of 10
+	Subject     string            // SNS only; used by some subscription protocols (e.g. email)
+
+	// FIFO topics and queues only — ignored otherwise.
+	MessageGroupId         string
+	MessageDeduplicationId string
+}
+
+// Outbox records events in the same transaction as the rows they describe.
+type Outbox interface {
+	// Add inserts event into the outbox table through tx, the Database passed to a
+	// WithTransaction callback, so the event is committed or rolled back together
+	// with the business rows. The trace context of ctx is stored with it.
+	Add(ctx context.Context, tx postgresql.Database, event Event) error
+}
+
+// Publisher publishes relayed events to one destination.
+// SNSPublisher and SQSPublisher adapt the messaging producers.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// PublisherFunc adapts a function to Publisher.
+type PublisherFunc func(ctx context.Context, event Event) error
+
+// Publish calls f(ctx, event).
+func (f PublisherFunc) Publish(ctx context.Context, event Event) error { return f(ctx, event) }
+
+// Relay publishes committed outbox events.
+type Relay interface {
+	// Start claims due events, publishes them and marks them sent until ctx is
+	// cancelled. Blocks; the batch in flight is finished before it returns.
+	Start(ctx context.Context) error
+}
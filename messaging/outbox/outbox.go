@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/juanMaAV92/go-utils/database/postgresql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// maxAttributes leaves room for the trace context headers within the 10 message
+// attributes SNS and SQS accept.
+const maxAttributes = 6
+
+type outbox struct {
+	insertSQL string
+}
+
+// New creates an Outbox writing to cfg.Table, created by the migration in migrations/.
+func New(cfg Config) (Outbox, error) {
+	cfg = cfg.withDefaults()
+	if !tableName.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("outbox: invalid table name %q", cfg.Table)
+	}
+	return &outbox{
+		insertSQL: "INSERT INTO " + cfg.Table +
+			" (destination, body, attributes, trace_context, subject, message_group_id, deduplication_id)" +
+			" VALUES (?, ?, CAST(? AS jsonb), CAST(? AS jsonb), ?, ?, ?)",
+	}, nil
+}
+
+// Add inserts event through tx together with the W3C trace context of ctx.
+//
+//	err := db.WithTransaction(ctx, func(tx postgresql.Database) error {
+//	    if _, err := tx.Create(ctx, &order); err != nil {
+//	        return err
+//	    }
+//	    return ob.Add(ctx, tx, outbox.Event{Destination: "orders", Body: string(payload)})
+//	})
+func (o *outbox) Add(ctx context.Context, tx postgresql.Database, event Event) error {
+	if tx == nil {
+		return errors.New("outbox: transaction is required")
+	}
+	if event.Destination == "" {
+		return errors.New("outbox: event destination is required")
+	}
+	if event.Body == "" {
+		return errors.New("outbox: event body cannot be empty")
+	}
+	if len(event.Attributes) > maxAttributes {
+		return fmt.Errorf("outbox: at most %d attributes are allowed, got %d", maxAttributes, len(event.Attributes))
+	}
+
+	attrs := event.Attributes
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	attrsJSON, err := json.Marshal(attrs)
+	if err != nil {
+		return fmt.Errorf("outbox: encode attributes: %w", err)
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	traceJSON, err := json.Marshal(carrier)
+	if err != nil {
+		return fmt.Errorf("outbox: encode trace context: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, nil, o.insertSQL,
+		event.Destination, event.Body, string(attrsJSON), string(traceJSON),
+		event.Subject, event.MessageGroupId, event.MessageDeduplicationId,
+	); err != nil {
+		return fmt.Errorf("outbox: add: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/juanMaAV92/go-utils/database/postgresql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// mockLogger satisfies logger.Logger without importing the package.
+type mockLogger struct{}
+
+func (mockLogger) Info(_ context.Context, _, _ string, _ ...any)    {}
+func (mockLogger) Error(_ context.Context, _, _ string, _ ...any)   {}
+func (mockLogger) Warning(_ context.Context, _, _ string, _ ...any) {}
+func (mockLogger) Debug(_ context.Context, _, _ string, _ ...any)   {}
+func (mockLogger) Fatal(_ context.Context, _, _ string, _ ...any)   {}
+
+var ctx = context.Background()
+
+type execCall struct {
+	sql  string
+	args []any
+}
+
+// fakeDB records Exec calls and serves claim queries from pending.
+// Methods the outbox does not use panic through the nil embedded interface.
+type fakeDB struct {
+	postgresql.Database
+
+	mu           sync.Mutex
+	execs        []execCall
+	pending      []record
+	transactions int
+	failExec     string // Exec returns an error for statements containing it
+}
+
+func (f *fakeDB) Exec(_ context.Context, model any, sql string, args ...any) (postgresql.QueryResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, execCall{sql: sql, args: args})
+	if f.failExec != "" && strings.Contains(sql, f.failExec) {
+		return postgresql.QueryResult{}, errors.New("failed to execute query")
+	}
+	if model != nil {
+		rows := f.pending
+		f.pending = nil
+		*model.(*[]record) = rows
+		return postgresql.QueryResult{RowsAffected: int64(len(rows)), Found: len(rows) > 0}, nil
+	}
+	return postgresql.QueryResult{RowsAffected: 1, Found: true}, nil
+}
+
+func (f *fakeDB) WithTransaction(_ context.Context, fn postgresql.TransactionFunc) error {
+	f.mu.Lock()
+	f.transactions++
+	f.mu.Unlock()
+	return fn(f)
+}
+
+func (f *fakeDB) calls(contains string) []execCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []execCall
+	for _, c := range f.execs {
+		if strings.Contains(c.sql, contains) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func tracedContext() (context.Context, trace.SpanContext) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(ctx, sc), sc
+}
+
+// ---- New / ConfigFromEnv ----
+
+func TestNew_Validation(t *testing.T) {
+	for _, table := range []string{"outbox; DROP TABLE users", "1outbox", "a.b.c", `"outbox"`} {
+		if _, err := New(Config{Table: table}); err == nil {
+			t.Errorf("table %q: expected error", table)
+		}
+	}
+	for _, table := range []string{"", "outbox", "events.order_outbox"} {
+		if _, err := New(Config{Table: table}); err != nil {
+			t.Errorf("table %q: %v", table, err)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("ORDER_OUTBOX_TABLE", "orders.outbox")
+	t.Setenv("ORDER_OUTBOX_BATCH_SIZE", "25")
+	t.Setenv("ORDER_OUTBOX_RETENTION", "-1s")
+	cfg, err := ConfigFromEnv("ORDER_OUTBOX")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if cfg.Table != "orders.outbox" || cfg.BatchSize != 25 || cfg.PollInterval.Seconds() != 1 || cfg.MaxAttempts != 20 || cfg.Retention >= 0 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	t.Setenv("ORDER_OUTBOX_TABLE", "outbox--")
+	if _, err := ConfigFromEnv("ORDER_OUTBOX"); err == nil {
+		t.Error("expected error for invalid table name")
+	}
+}
+
+// ---- Add ----
+
+func TestAdd_InsertsEventWithTraceContext(t *testing.T) {
+	traced, sc := tracedContext()
+	db := &fakeDB{}
+	ob, _ := New(Config{})
+
+	err := ob.Add(traced, db, Event{
+		Destination:    "orders",
+		Body:           `{"id":1}`,
+		Attributes:     map[string]string{"type": "order.created"},
+		MessageGroupId: "customer-7",
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	inserts := db.calls("INSERT INTO outbox ")
+	if len(inserts) != 1 {
+		t.Fatalf("inserts = %+v", db.execs)
+	}
+	args := inserts[0].args
+	if args[0] != "orders" || args[1] != `{"id":1}` || args[2] != `{"type":"order.created"}` || args[5] != "customer-7" {
+		t.Errorf("args = %v", args)
+	}
+	var stored map[string]string
+	if err := json.Unmarshal([]byte(args[3].(string)), &stored); err != nil {
+		t.Fatalf("trace context %q: %v", args[3], err)
+	}
+	if !strings.Contains(stored["traceparent"], sc.TraceID().String()) {
+		t.Errorf("traceparent = %q, want trace %s", stored["traceparent"], sc.TraceID())
+	}
+}
+
+func TestAdd_Validation(t *testing.T) {
+	ob, _ := New(Config{})
+	db := &fakeDB{}
+	tests := []struct {
+		name string
+		tx   postgresql.Database
+		e    Event
+	}{
+		{"nil tx", nil, Event{Destination: "orders", Body: "x"}},
+		{"no destination", db, Event{Body: "x"}},
+		{"empty body", db, Event{Destination: "orders"}},
+		{"too many attributes", db, Event{Destination: "orders", Body: "x", Attributes: map[string]string{
+			"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6", "g": "7",
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ob.Add(ctx, tt.tx, tt.e); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+	if len(db.execs) != 0 {
+		t.Errorf("invalid events must not be inserted: %+v", db.execs)
+	}
+}
+
+func TestAdd_PropagatesExecError(t *testing.T) {
+	ob, _ := New(Config{})
+	db := &fakeDB{failExec: "INSERT"}
+	if err := ob.Add(ctx, db, Event{Destination: "orders", Body: "x"}); err == nil || !strings.HasPrefix(err.Error(), "outbox: add:") {
+		t.Errorf("Add = %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package outbox
+
+import (
+	"context"
+
+	snsproducer "github.com/juanMaAV92/go-utils/messaging/sns/producer"
+	sqsproducer "github.com/juanMaAV92/go-utils/messaging/sqs/producer"
+)
+
+// SNSPublisher publishes events to the topic of p. The producer injects the trace
+// context restored by the relay into the message attributes.
+func SNSPublisher(p snsproducer.Producer) Publisher {
+	return PublisherFunc(func(ctx context.Context, e Event) error {
+		return p.Publish(ctx, &snsproducer.Message{
+			Body:                   e.Body,
+			Attributes:             e.Attributes,
+			Subject:                e.Subject,
+			MessageGroupId:         e.MessageGroupId,
+			MessageDeduplicationId: e.MessageDeduplicationId,
+		})
+	})
+}
+
+// SQSPublisher sends events to the queue of p. The producer injects the trace
+// context restored by the relay into the message attributes.
+func SQSPublisher(p sqsproducer.Producer) Publisher {
+	return PublisherFunc(func(ctx context.Context, e Event) error {
+		return p.SendMessage(ctx, &sqsproducer.Message{
+			Body:                   e.Body,
+			Attributes:             e.Attributes,
+			MessageGroupId:         e.MessageGroupId,
+			MessageDeduplicationId: e.MessageDeduplicationId,
+		})
+	})
+}
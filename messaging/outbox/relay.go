@@ -0,0 +1,267 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/database/postgresql"
+	"github.com/juanMaAV92/go-utils/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const cleanupInterval = 10 * time.Minute
+
+// record is one outbox row as claimed by the relay.
+type record struct {
+	ID              int64
+	Destination     string
+	Body            string
+	Attributes      string
+	TraceContext    string
+	Subject         string
+	MessageGroupId  string
+	DeduplicationId string
+	Attempts        int
+}
+
+// groupKey identifies a FIFO message group; group ids are scoped to a destination.
+type groupKey struct {
+	destination string
+	group       string
+}
+
+type relay struct {
+	db         postgresql.Database
+	publishers map[string]Publisher
+	logger     logger.Logger
+	cfg        Config
+	name       string
+	tracer     trace.Tracer
+
+	claimSQL   string
+	sentSQL    string
+	retrySQL   string
+	holdSQL    string
+	cleanupSQL string
+}
+
+// NewRelay creates a Relay for the outbox table in cfg. publishers maps each
+// Event.Destination to the Publisher that sends it; events for a destination
+// without one are retried like failed publishes.
+// name identifies this relay in logs and traces.
+//
+// Several replicas may run a relay on the same table: FOR UPDATE SKIP LOCKED
+// hands each row to one of them. Delivery is at least once.
+func NewRelay(db postgresql.Database, publishers map[string]Publisher, log logger.Logger, cfg Config, name string) (Relay, error) {
+	if db == nil {
+		return nil, errors.New("outbox: database is required")
+	}
+	if len(publishers) == 0 {
+		return nil, errors.New("outbox: at least one publisher is required")
+	}
+	if log == nil {
+		return nil, errors.New("outbox: logger is required")
+	}
+	if name == "" {
+		return nil, errors.New("outbox: name is required")
+	}
+	cfg = cfg.withDefaults()
+	if !tableName.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("outbox: invalid table name %q", cfg.Table)
+	}
+	t := cfg.Table
+	return &relay{
+		db:         db,
+		publishers: publishers,
+		logger:     log,
+		cfg:        cfg,
+		name:       name,
+		tracer:     otel.Tracer("github.com/juanMaAV92/go-utils/messaging/outbox"),
+		// A row whose group has an earlier unsent row waiting for a retry is not
+		// claimed, so a failed publish holds back the rest of its group.
+		claimSQL: "SELECT id, destination, body, attributes, trace_context, subject, message_group_id, deduplication_id, attempts" +
+			" FROM " + t + " o WHERE o.sent_at IS NULL AND o.failed_at IS NULL AND o.available_at <= now()" +
+			" AND (o.message_group_id = '' OR NOT EXISTS (SELECT 1 FROM " + t + " p" +
+			" WHERE p.destination = o.destination AND p.message_group_id = o.message_group_id" +
+			" AND p.sent_at IS NULL AND p.failed_at IS NULL AND p.available_at > now() AND p.id < o.id))" +
+			" ORDER BY o.id LIMIT ? FOR UPDATE SKIP LOCKED",
+		sentSQL: "UPDATE " + t + " SET sent_at = now(), attempts = attempts + 1, last_error = NULL WHERE id IN ?",
+		retrySQL: "UPDATE " + t + " SET attempts = attempts + 1, last_error = ?, available_at = now() + CAST(? AS bigint) * interval '1 millisecond'," +
+			" failed_at = CASE WHEN CAST(? AS boolean) THEN now() END WHERE id = ?",
+		holdSQL:    "UPDATE " + t + " SET available_at = now() + CAST(? AS bigint) * interval '1 millisecond' WHERE id = ?",
+		cleanupSQL: "DELETE FROM " + t + " WHERE sent_at < now() - CAST(? AS bigint) * interval '1 millisecond'",
+	}, nil
+}
+
+// Start relays due events until ctx is cancelled. A full batch is followed by the
+// next one at once; otherwise the relay waits PollInterval.
+func (r *relay) Start(ctx context.Context) error {
+	r.logger.Info(ctx, "outbox.relay.start", "starting relay",
+		"relay", r.name, "table", r.cfg.Table, "batch_size", r.cfg.BatchSize)
+
+	var lastCleanup time.Time
+	for {
+		if r.cfg.Retention > 0 && time.Since(lastCleanup) >= cleanupInterval {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		// Finish the batch even on shutdown: abandoning it would roll back the
+		// sent marks of events already published, and publish them again.
+		n, err := r.relayBatch(context.WithoutCancel(ctx))
+		if err != nil {
+			r.logger.Error(ctx, "outbox.relay.batch", "relay batch failed", "relay", r.name, "error", err.Error())
+		}
+		if err == nil && n == r.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info(ctx, "outbox.relay.stop", "relay stopped", "relay", r.name)
+			return ctx.Err()
+		case <-time.After(r.cfg.PollInterval):
+		}
+	}
+}
+
+// relayBatch claims up to BatchSize due rows in one transaction, publishes them and
+// records the outcome. It returns the number of rows claimed.
+func (r *relay) relayBatch(ctx context.Context) (int, error) {
+	var claimed int
+	err := r.db.WithTransaction(ctx, func(tx postgresql.Database) error {
+		var rows []record
+		if _, err := tx.Exec(ctx, &rows, r.claimSQL, r.cfg.BatchSize); err != nil {
+			return fmt.Errorf("outbox: claim: %w", err)
+		}
+		claimed = len(rows)
+
+		sent := make([]int64, 0, len(rows))
+		held := map[groupKey]time.Duration{} // groups with a failed publish → its retry delay
+		for _, row := range rows {
+			group := groupKey{row.Destination, row.MessageGroupId}
+			if delay, ok := held[group]; ok {
+				// Keep FIFO order: later rows of the group wait for the failed one.
+				if _, err := tx.Exec(ctx, nil, r.holdSQL, delay.Milliseconds(), row.ID); err != nil {
+					return fmt.Errorf("outbox: hold group: %w", err)
+				}
+				continue
+			}
+			if err := r.publish(ctx, row); err != nil {
+				delay := backoff(row.Attempts+1, r.cfg.MinBackoff, r.cfg.MaxBackoff)
+				failed := r.cfg.MaxAttempts > 0 && row.Attempts+1 >= r.cfg.MaxAttempts
+				if failed {
+					r.logger.Error(ctx, "outbox.relay.publish", "publish failed, giving up",
+						"relay", r.name, "outbox_id", row.ID, "destination", row.Destination,
+						"attempts", row.Attempts+1, "error", err.Error())
+				} else {
+					r.logger.Warning(ctx, "outbox.relay.publish", "publish failed, will retry",
+						"relay", r.name, "outbox_id", row.ID, "destination", row.Destination,
+						"attempts", row.Attempts+1, "retry_in", delay.String(), "error", err.Error())
+				}
+				if _, err := tx.Exec(ctx, nil, r.retrySQL, err.Error(), delay.Milliseconds(), failed, row.ID); err != nil {
+					return fmt.Errorf("outbox: schedule retry: %w", err)
+				}
+				if row.MessageGroupId != "" && !failed {
+					held[group] = delay
+				}
+				continue
+			}
+			sent = append(sent, row.ID)
+		}
+		if len(sent) > 0 {
+			if _, err := tx.Exec(ctx, nil, r.sentSQL, sent); err != nil {
+				return fmt.Errorf("outbox: mark sent: %w", err)
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// publish sends row under the trace context stored by Add, so the message carries
+// the trace of the request that created it.
+func (r *relay) publish(ctx context.Context, row record) error {
+	carrier := propagation.MapCarrier{}
+	if row.TraceContext != "" {
+		_ = json.Unmarshal([]byte(row.TraceContext), &carrier)
+	}
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+
+	ctx, span := r.tracer.Start(ctx,
+		fmt.Sprintf("outbox relay %s", r.name),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "outbox"),
+			attribute.String("messaging.operation", "publish"),
+			attribute.String("messaging.destination.name", row.Destination),
+			attribute.String("messaging.producer.name", r.name),
+			attribute.Int64("messaging.outbox.id", row.ID),
+			attribute.Int("messaging.outbox.attempts", row.Attempts+1),
+		),
+	)
+	defer span.End()
+
+	err := r.send(ctx, row)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+func (r *relay) send(ctx context.Context, row record) error {
+	pub, ok := r.publishers[row.Destination]
+	if !ok {
+		return fmt.Errorf("outbox: no publisher for destination %q", row.Destination)
+	}
+	var attrs map[string]string
+	if row.Attributes != "" {
+		if err := json.Unmarshal([]byte(row.Attributes), &attrs); err != nil {
+			return fmt.Errorf("outbox: decode attributes: %w", err)
+		}
+	}
+	if len(attrs) == 0 {
+		attrs = nil
+	}
+	return pub.Publish(ctx, Event{
+		Destination:            row.Destination,
+		Body:                   row.Body,
+		Attributes:             attrs,
+		Subject:                row.Subject,
+		MessageGroupId:         row.MessageGroupId,
+		MessageDeduplicationId: row.DeduplicationId,
+	})
+}
+
+// cleanup deletes rows sent more than Retention ago.
+func (r *relay) cleanup(ctx context.Context) {
+	res, err := r.db.Exec(ctx, nil, r.cleanupSQL, r.cfg.Retention.Milliseconds())
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error(ctx, "outbox.relay.cleanup", "failed to delete sent events", "relay", r.name, "error", err.Error())
+		}
+		return
+	}
+	if res.RowsAffected > 0 {
+		r.logger.Info(ctx, "outbox.relay.cleanup", "deleted sent events", "relay", r.name, "count", res.RowsAffected)
+	}
+}
+
+// backoff returns the retry delay after attempts failed publishes: minDelay doubled
+// per attempt, capped at maxDelay.
+func backoff(attempts int, minDelay, maxDelay time.Duration) time.Duration {
+	d := minDelay
+	for i := 1; i < attempts && d < maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxDelay)
+}
@@ -0,0 +1,300 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/database/postgresql"
+	snsproducer "github.com/juanMaAV92/go-utils/messaging/sns/producer"
+	sqsproducer "github.com/juanMaAV92/go-utils/messaging/sqs/producer"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// capturePublisher records published events and the trace each was published under.
+type capturePublisher struct {
+	mu     sync.Mutex
+	events []Event
+	traces []trace.TraceID
+	err    error
+}
+
+func (p *capturePublisher) Publish(ctx context.Context, e Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, e)
+	p.traces = append(p.traces, trace.SpanContextFromContext(ctx).TraceID())
+	return nil
+}
+
+func newTestRelay(t *testing.T, db postgresql.Database, pubs map[string]Publisher, cfg Config) *relay {
+	t.Helper()
+	r, err := NewRelay(db, pubs, mockLogger{}, cfg, "test-relay")
+	if err != nil {
+		t.Fatalf("NewRelay: %v", err)
+	}
+	return r.(*relay)
+}
+
+func TestNewRelay_Validation(t *testing.T) {
+	pubs := map[string]Publisher{"orders": &capturePublisher{}}
+	tests := []struct {
+		name string
+		fn   func() (Relay, error)
+	}{
+		{"nil db", func() (Relay, error) { return NewRelay(nil, pubs, mockLogger{}, Config{}, "r") }},
+		{"no publishers", func() (Relay, error) { return NewRelay(&fakeDB{}, nil, mockLogger{}, Config{}, "r") }},
+		{"nil logger", func() (Relay, error) { return NewRelay(&fakeDB{}, pubs, nil, Config{}, "r") }},
+		{"empty name", func() (Relay, error) { return NewRelay(&fakeDB{}, pubs, mockLogger{}, Config{}, "") }},
+		{"bad table", func() (Relay, error) { return NewRelay(&fakeDB{}, pubs, mockLogger{}, Config{Table: "x y"}, "r") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.fn(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestRelayBatch_PublishesAndMarksSent(t *testing.T) {
+	traced, sc := tracedContext()
+	db := &fakeDB{}
+	ob, _ := New(Config{})
+	_ = ob.Add(traced, db, Event{Destination: "orders", Body: "x"})
+	traceJSON := db.calls("INSERT")[0].args[3].(string)
+
+	db.pending = []record{
+		{ID: 1, Destination: "orders", Body: `{"id":1}`, Attributes: `{"type":"order.created"}`, TraceContext: traceJSON, MessageGroupId: "g1"},
+		{ID: 2, Destination: "orders", Body: `{"id":2}`, Attributes: `{}`, TraceContext: `{}`},
+	}
+	pub := &capturePublisher{}
+	r := newTestRelay(t, db, map[string]Publisher{"orders": pub}, Config{BatchSize: 10})
+
+	n, err := r.relayBatch(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("relayBatch = %d, %v", n, err)
+	}
+	if db.transactions != 1 {
+		t.Errorf("transactions = %d, want 1", db.transactions)
+	}
+	claim := db.calls("FOR UPDATE SKIP LOCKED")
+	if len(claim) != 1 || claim[0].args[0] != 10 || !strings.Contains(claim[0].sql, "FROM outbox o WHERE o.sent_at IS NULL") {
+		t.Errorf("claim = %+v", claim)
+	}
+
+	if len(pub.events) != 2 || pub.events[0].Attributes["type"] != "order.created" || pub.events[0].MessageGroupId != "g1" || pub.events[1].Attributes != nil {
+		t.Errorf("published = %+v", pub.events)
+	}
+	if pub.traces[0] != sc.TraceID() {
+		t.Errorf("event 1 published under trace %s, want %s", pub.traces[0], sc.TraceID())
+	}
+
+	sent := db.calls("SET sent_at = now()")
+	if len(sent) != 1 {
+		t.Fatalf("mark sent = %+v", sent)
+	}
+	if ids := sent[0].args[0].([]int64); len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("sent ids = %v", ids)
+	}
+}
+
+func TestRelayBatch_FailedPublishIsRetriedWithBackoff(t *testing.T) {
+	db := &fakeDB{pending: []record{
+		{ID: 1, Destination: "orders", Body: "a", Attempts: 2},
+		{ID: 2, Destination: "billing", Body: "b"}, // no publisher configured
+		{ID: 3, Destination: "audit", Body: "c"},
+	}}
+	pubs := map[string]Publisher{
+		"orders": &capturePublisher{err: errors.New("throttled")},
+		"audit":  &capturePublisher{},
+	}
+	r := newTestRelay(t, db, pubs, Config{MinBackoff: time.Second, MaxBackoff: time.Minute})
+
+	if _, err := r.relayBatch(ctx); err != nil {
+		t.Fatalf("relayBatch: %v", err)
+	}
+	retries := db.calls("SET attempts = attempts + 1, last_error")
+	if len(retries) != 2 {
+		t.Fatalf("retries = %+v", retries)
+	}
+	// Third failure of event 1: 1s doubled twice.
+	if retries[0].args[0] != "throttled" || retries[0].args[1] != int64(4000) || retries[0].args[2] != false || retries[0].args[3] != int64(1) {
+		t.Errorf("retry 1 args = %v", retries[0].args)
+	}
+	if !strings.Contains(retries[1].args[0].(string), `no publisher for destination "billing"`) || retries[1].args[3] != int64(2) {
+		t.Errorf("retry 2 args = %v", retries[1].args)
+	}
+	if sent := db.calls("SET sent_at"); len(sent) != 1 || len(sent[0].args[0].([]int64)) != 1 {
+		t.Errorf("only event 3 should be marked sent: %+v", sent)
+	}
+}
+
+func TestRelayBatch_FailedPublishHoldsItsGroup(t *testing.T) {
+	db := &fakeDB{pending: []record{
+		{ID: 1, Destination: "orders", Body: "a", MessageGroupId: "g1"},
+		{ID: 2, Destination: "orders", Body: "b", MessageGroupId: "g2"},
+		{ID: 3, Destination: "orders", Body: "c", MessageGroupId: "g1"},
+		{ID: 4, Destination: "billing", Body: "d", MessageGroupId: "g1"}, // same id, other destination
+		{ID: 5, Destination: "orders", Body: "e", MessageGroupId: "g1"},
+	}}
+	failing := PublisherFunc(func(_ context.Context, e Event) error {
+		if e.Body == "a" {
+			return errors.New("throttled")
+		}
+		return nil
+	})
+	r := newTestRelay(t, db, map[string]Publisher{"orders": failing, "billing": failing}, Config{MinBackoff: time.Second, MaxBackoff: time.Minute})
+
+	if _, err := r.relayBatch(ctx); err != nil {
+		t.Fatalf("relayBatch: %v", err)
+	}
+	if retries := db.calls("last_error = ?"); len(retries) != 1 || retries[0].args[3] != int64(1) {
+		t.Errorf("retries = %+v", retries)
+	}
+	held := db.calls("SET available_at")
+	if len(held) != 2 || held[0].args[1] != int64(3) || held[1].args[1] != int64(5) || held[0].args[0] != int64(1000) {
+		t.Errorf("held = %+v", held)
+	}
+	if sent := db.calls("SET sent_at"); len(sent) != 1 || len(sent[0].args[0].([]int64)) != 2 {
+		t.Errorf("events 2 and 4 should be marked sent: %+v", sent)
+	}
+	if claim := db.calls("FOR UPDATE")[0].sql; !strings.Contains(claim, "NOT EXISTS") || !strings.Contains(claim, "p.available_at > now() AND p.id < o.id") {
+		t.Errorf("claim must skip groups waiting for a retry: %s", claim)
+	}
+}
+
+func TestRelayBatch_GivesUpAfterMaxAttempts(t *testing.T) {
+	db := &fakeDB{pending: []record{
+		{ID: 1, Destination: "orders", Body: "a", MessageGroupId: "g1", Attempts: 2},
+		{ID: 2, Destination: "orders", Body: "b", MessageGroupId: "g1"},
+	}}
+	pub := &capturePublisher{err: errors.New("message too large")}
+	r := newTestRelay(t, db, map[string]Publisher{"orders": pub}, Config{MaxAttempts: 3})
+
+	if _, err := r.relayBatch(ctx); err != nil {
+		t.Fatalf("relayBatch: %v", err)
+	}
+	retries := db.calls("last_error = ?")
+	if len(retries) != 2 || retries[0].args[2] != true || retries[1].args[2] != false {
+		t.Fatalf("event 1 should be marked failed, event 2 retried: %+v", retries)
+	}
+	if !strings.Contains(retries[0].sql, "failed_at = CASE WHEN") {
+		t.Errorf("retry sql = %s", retries[0].sql)
+	}
+	if held := db.calls("SET available_at"); len(held) != 0 {
+		t.Errorf("a failed row must not hold its group: %+v", held)
+	}
+	if claim := db.calls("FOR UPDATE")[0].sql; !strings.Contains(claim, "o.failed_at IS NULL") {
+		t.Errorf("claim must skip failed rows: %s", claim)
+	}
+}
+
+func TestRelayBatch_ClaimErrorRollsBack(t *testing.T) {
+	db := &fakeDB{failExec: "FOR UPDATE"}
+	r := newTestRelay(t, db, map[string]Publisher{"orders": &capturePublisher{}}, Config{})
+	if _, err := r.relayBatch(ctx); err == nil || !strings.Contains(err.Error(), "claim") {
+		t.Errorf("relayBatch = %v", err)
+	}
+}
+
+func TestRelay_StartDrainsAndStops(t *testing.T) {
+	db := &fakeDB{pending: []record{{ID: 1, Destination: "orders", Body: "a"}}}
+	pub := &capturePublisher{}
+	r := newTestRelay(t, db, map[string]Publisher{"orders": pub}, Config{PollInterval: 5 * time.Millisecond, Retention: time.Hour})
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- r.Start(runCtx) }()
+
+	deadline := time.Now().Add(time.Second)
+	for len(db.calls("SET sent_at")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("event not relayed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Start = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after cancel")
+	}
+	if cleanup := db.calls("DELETE FROM outbox WHERE sent_at <"); len(cleanup) != 1 || cleanup[0].args[0] != int64(time.Hour/time.Millisecond) {
+		t.Errorf("cleanup = %+v", cleanup)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{10, time.Minute},
+		{1000, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts, time.Second, time.Minute); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// ---- publishers ----
+
+type fakeSNS struct{ msg *snsproducer.Message }
+
+func (f *fakeSNS) Publish(_ context.Context, msg *snsproducer.Message) error {
+	f.msg = msg
+	return nil
+}
+
+type fakeSQS struct{ msg *sqsproducer.Message }
+
+func (f *fakeSQS) SendMessage(_ context.Context, msg *sqsproducer.Message) error {
+	f.msg = msg
+	return nil
+}
+
+func (f *fakeSQS) SendBatch(context.Context, []*sqsproducer.Message) (*sqsproducer.BatchResult, error) {
+	return nil, errors.New("not used")
+}
+
+func TestPublishers(t *testing.T) {
+	e := Event{
+		Destination:            "orders",
+		Body:                   "b",
+		Attributes:             map[string]string{"k": "v"},
+		Subject:                "s",
+		MessageGroupId:         "g",
+		MessageDeduplicationId: "d",
+	}
+
+	sns := &fakeSNS{}
+	if err := SNSPublisher(sns).Publish(ctx, e); err != nil {
+		t.Fatalf("SNSPublisher: %v", err)
+	}
+	if m := sns.msg; m.Body != "b" || m.Attributes["k"] != "v" || m.Subject != "s" || m.MessageGroupId != "g" || m.MessageDeduplicationId != "d" {
+		t.Errorf("SNS message = %+v", m)
+	}
+
+	sqs := &fakeSQS{}
+	if err := SQSPublisher(sqs).Publish(ctx, e); err != nil {
+		t.Fatalf("SQSPublisher: %v", err)
+	}
+	if m := sqs.msg; m.Body != "b" || m.Attributes["k"] != "v" || m.MessageGroupId != "g" || m.MessageDeduplicationId != "d" {
+		t.Errorf("SQS message = %+v", m)
+	}
+}
@@ -0,0 +1,383 @@
+package consumer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/logger"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	messageChanBuffer = 100
+	pollErrorBackoff  = time.Second
+
+	// bodyField is the stream field holding the message body; the producer writes the same field.
+	bodyField = "body"
+
+	// Fields added to dead-lettered entries alongside the original fields.
+	deadLetterSourceField     = "dead_letter_source"
+	deadLetterIDField         = "dead_letter_id"
+	deadLetterDeliveriesField = "dead_letter_deliveries"
+)
+
+type consumer struct {
+	client      goredis.UniversalClient
+	processor   MessageProcessor
+	logger      logger.Logger
+	cfg         ConsumerConfig
+	name        string
+	messageChan chan goredis.XMessage
+	wg          sync.WaitGroup
+	tracer      trace.Tracer
+}
+
+// New creates a new Redis Streams consumer-group consumer.
+// name identifies this consumer in logs and traces.
+func New(client goredis.UniversalClient, processor MessageProcessor, log logger.Logger, cfg ConsumerConfig, name string) (Consumer, error) {
+	if client == nil {
+		return nil, errors.New("redisstream/consumer: client is required")
+	}
+	if processor == nil {
+		return nil, errors.New("redisstream/consumer: processor is required")
+	}
+	if log == nil {
+		return nil, errors.New("redisstream/consumer: logger is required")
+	}
+	if cfg.Stream == "" {
+		return nil, errors.New("redisstream/consumer: stream is required")
+	}
+	if cfg.Group == "" {
+		return nil, errors.New("redisstream/consumer: group is required")
+	}
+	if name == "" {
+		return nil, errors.New("redisstream/consumer: name is required")
+	}
+	cfg = cfg.withDefaults()
+	if cfg.ConsumerName == "" {
+		consumerName, err := defaultConsumerName()
+		if err != nil {
+			return nil, err
+		}
+		cfg.ConsumerName = consumerName
+	}
+	return &consumer{
+		client:      client,
+		processor:   processor,
+		logger:      log,
+		cfg:         cfg,
+		name:        name,
+		messageChan: make(chan goredis.XMessage, messageChanBuffer),
+		tracer:      otel.Tracer("github.com/juanMaAV92/go-utils/messaging/redisstream"),
+	}, nil
+}
+
+// Start creates the consumer group if needed, then reads new entries and periodically
+// reclaims stale pending entries, dispatching both to the worker pool.
+// Blocks until ctx is cancelled.
+func (c *consumer) Start(ctx context.Context) error {
+	if err := c.ensureGroup(ctx); err != nil {
+		return err
+	}
+	c.logger.Info(ctx, "redisstream.consumer.start", "starting consumer",
+		"consumer", c.name, "stream", c.cfg.Stream, "group", c.cfg.Group,
+		"consumer_name", c.cfg.ConsumerName, "workers", c.cfg.WorkerPoolSize)
+
+	for i := 0; i < c.cfg.WorkerPoolSize; i++ {
+		c.wg.Add(1)
+		go c.worker(ctx, i)
+	}
+
+	claimTicker := time.NewTicker(c.cfg.ClaimInterval)
+	defer claimTicker.Stop()
+
+	// Pick up entries left pending by a crashed replica without waiting a full interval.
+	c.reclaimAndLog(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.logger.Info(ctx, "redisstream.consumer.stop", "context cancelled, stopping", "consumer", c.name)
+			close(c.messageChan)
+			c.wg.Wait()
+			c.logger.Info(ctx, "redisstream.consumer.stop", "consumer stopped", "consumer", c.name)
+			return ctx.Err()
+		case <-claimTicker.C:
+			c.reclaimAndLog(ctx)
+		default:
+			if err := c.poll(ctx); err != nil && ctx.Err() == nil {
+				c.logger.Error(ctx, "redisstream.consumer.poll", "poll error", "consumer", c.name, "error", err.Error())
+				select {
+				case <-ctx.Done():
+				case <-time.After(pollErrorBackoff):
+				}
+			}
+		}
+	}
+}
+
+func (c *consumer) worker(ctx context.Context, workerID int) {
+	defer c.wg.Done()
+	for msg := range c.messageChan {
+		c.process(ctx, msg, workerID)
+	}
+}
+
+// ensureGroup creates the consumer group (and the stream) unless it already exists.
+func (c *consumer) ensureGroup(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(ctx, c.cfg.Stream, c.cfg.Group, c.cfg.StartID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("redisstream: create group: %w", err)
+	}
+	return nil
+}
+
+func (c *consumer) poll(ctx context.Context) error {
+	streams, err := c.client.XReadGroup(ctx, &goredis.XReadGroupArgs{
+		Group:    c.cfg.Group,
+		Consumer: c.cfg.ConsumerName,
+		Streams:  []string{c.cfg.Stream, ">"},
+		Count:    c.cfg.BatchSize,
+		Block:    c.cfg.Block,
+	}).Result()
+	if err == goredis.Nil {
+		return nil // block timed out with no new entries
+	}
+	if err != nil {
+		return fmt.Errorf("redisstream: read group: %w", err)
+	}
+	for _, s := range streams {
+		for _, msg := range s.Messages {
+			if err := c.dispatch(ctx, msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *consumer) dispatch(ctx context.Context, msg goredis.XMessage) error {
+	select {
+	case c.messageChan <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *consumer) reclaimAndLog(ctx context.Context) {
+	if err := c.reclaim(ctx); err != nil && ctx.Err() == nil {
+		c.logger.Error(ctx, "redisstream.consumer.reclaim", "reclaim error", "consumer", c.name, "error", err.Error())
+	}
+}
+
+// reclaim takes over entries pending longer than MinIdle (XAUTOCLAIM), including
+// those of crashed replicas. Entries delivered more than MaxDeliveries times are
+// moved to the dead-letter stream; the rest are dispatched again.
+func (c *consumer) reclaim(ctx context.Context) error {
+	start := "0-0"
+	for {
+		msgs, next, err := c.client.XAutoClaim(ctx, &goredis.XAutoClaimArgs{
+			Stream:   c.cfg.Stream,
+			Group:    c.cfg.Group,
+			Consumer: c.cfg.ConsumerName,
+			MinIdle:  c.cfg.MinIdle,
+			Start:    start,
+			Count:    c.cfg.BatchSize,
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("redisstream: autoclaim: %w", err)
+		}
+
+		if len(msgs) > 0 {
+			counts, err := c.deliveryCounts(ctx, msgs)
+			if err != nil {
+				return err
+			}
+			for _, msg := range msgs {
+				if msg.Values == nil {
+					// Entry was trimmed from the stream while pending — nothing left to process.
+					if err := c.ack(ctx, msg.ID); err != nil {
+						return err
+					}
+					continue
+				}
+				if c.cfg.MaxDeliveries > 0 && counts[msg.ID] > c.cfg.MaxDeliveries {
+					c.deadLetter(ctx, msg, counts[msg.ID])
+					continue
+				}
+				if err := c.dispatch(ctx, msg); err != nil {
+					return err
+				}
+			}
+		}
+
+		if next == "" || next == "0-0" {
+			return nil
+		}
+		start = next
+	}
+}
+
+// deliveryCounts returns the delivery count of each claimed entry (XPENDING, pipelined).
+func (c *consumer) deliveryCounts(ctx context.Context, msgs []goredis.XMessage) (map[string]int64, error) {
+	pipe := c.client.Pipeline()
+	cmds := make([]*goredis.XPendingExtCmd, len(msgs))
+	for i, msg := range msgs {
+		cmds[i] = pipe.XPendingExt(ctx, &goredis.XPendingExtArgs{
+			Stream:   c.cfg.Stream,
+			Group:    c.cfg.Group,
+			Start:    msg.ID,
+			End:      msg.ID,
+			Count:    1,
+			Consumer: c.cfg.ConsumerName,
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("redisstream: pending: %w", err)
+	}
+
+	counts := make(map[string]int64, len(msgs))
+	for _, cmd := range cmds {
+		for _, p := range cmd.Val() {
+			counts[p.ID] = p.RetryCount
+		}
+	}
+	return counts, nil
+}
+
+// deadLetter copies msg to the dead-letter stream and acknowledges the original.
+// If the ack fails the entry stays pending and may be dead-lettered again.
+func (c *consumer) deadLetter(ctx context.Context, msg goredis.XMessage, deliveries int64) {
+	values := make(map[string]any, len(msg.Values)+3)
+	for k, v := range msg.Values {
+		values[k] = v
+	}
+	values[deadLetterSourceField] = c.cfg.Stream
+	values[deadLetterIDField] = msg.ID
+	values[deadLetterDeliveriesField] = strconv.FormatInt(deliveries, 10)
+
+	if err := c.client.XAdd(ctx, &goredis.XAddArgs{Stream: c.cfg.DeadLetterStream, Values: values}).Err(); err != nil {
+		c.logger.Error(ctx, "redisstream.consumer.dead_letter", "failed to dead-letter message",
+			"consumer", c.name, "message_id", msg.ID, "error", err.Error())
+		return
+	}
+	if err := c.ack(ctx, msg.ID); err != nil {
+		c.logger.Error(ctx, "redisstream.consumer.dead_letter", "failed to ack dead-lettered message",
+			"consumer", c.name, "message_id", msg.ID, "error", err.Error())
+		return
+	}
+	c.logger.Warning(ctx, "redisstream.consumer.dead_letter", "message moved to dead-letter stream",
+		"consumer", c.name, "message_id", msg.ID, "deliveries", deliveries, "dead_letter_stream", c.cfg.DeadLetterStream)
+}
+
+func (c *consumer) process(ctx context.Context, msg goredis.XMessage, workerID int) {
+	body, fields := splitFields(msg.Values)
+
+	// Restore distributed trace from stream fields.
+	ctx = extractTrace(ctx, fields)
+
+	ctx, span := c.tracer.Start(ctx,
+		fmt.Sprintf("Redis stream process %s", c.name),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "redis"),
+			attribute.String("messaging.operation", "process"),
+			attribute.String("messaging.destination.name", c.cfg.Stream),
+			attribute.String("messaging.destination.kind", "stream"),
+			attribute.String("messaging.consumer.group.name", c.cfg.Group),
+			attribute.String("messaging.consumer.name", c.name),
+			attribute.String("messaging.message.id", msg.ID),
+			attribute.Int("messaging.worker.id", workerID),
+			attribute.Int("messaging.message.body.size", len(body)),
+		),
+	)
+	defer span.End()
+
+	if err := c.processor.ProcessMessage(ctx, []byte(body)); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.logger.Error(ctx, "redisstream.consumer.process", "processing failed, message will be reclaimed",
+			"consumer", c.name, "worker", workerID, "message_id", msg.ID, "error", err.Error())
+		return // leave pending — reclaimed after MinIdle
+	}
+
+	if err := c.ack(ctx, msg.ID); err != nil {
+		span.RecordError(err)
+		c.logger.Error(ctx, "redisstream.consumer.ack", "failed to ack message",
+			"consumer", c.name, "worker", workerID, "message_id", msg.ID, "error", err.Error())
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.logger.Info(ctx, "redisstream.consumer.process", "message processed",
+		"consumer", c.name, "worker", workerID, "message_id", msg.ID)
+}
+
+func (c *consumer) ack(ctx context.Context, id string) error {
+	if err := c.client.XAck(ctx, c.cfg.Stream, c.cfg.Group, id).Err(); err != nil {
+		return fmt.Errorf("redisstream: ack: %w", err)
+	}
+	return nil
+}
+
+// splitFields separates the body from the remaining string fields (attributes and trace context).
+func splitFields(values map[string]any) (string, map[string]string) {
+	fields := make(map[string]string, len(values))
+	for k, v := range values {
+		if s, ok := v.(string); ok {
+			fields[k] = s
+		}
+	}
+	body := fields[bodyField]
+	delete(fields, bodyField)
+	return body, fields
+}
+
+// extractTrace restores W3C Trace Context from stream fields into ctx.
+func extractTrace(ctx context.Context, fields map[string]string) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, &fieldCarrier{fields: fields})
+}
+
+// defaultConsumerName returns "{hostname}-{random}" so replicas never share a pending list.
+func defaultConsumerName() (string, error) {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "consumer"
+	}
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("redisstream/consumer: failed to generate consumer name: %w", err)
+	}
+	return host + "-" + hex.EncodeToString(b), nil
+}
+
+// fieldCarrier implements propagation.TextMapCarrier for stream entry fields.
+type fieldCarrier struct {
+	fields map[string]string
+}
+
+func (c *fieldCarrier) Get(key string) string { return c.fields[key] }
+func (c *fieldCarrier) Set(key, value string) { c.fields[key] = value }
+func (c *fieldCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.fields))
+	for k := range c.fields {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,285 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// --- mocks ---
+
+type mockProcessor struct {
+	fn func(ctx context.Context, body []byte) error
+}
+
+func (m *mockProcessor) ProcessMessage(ctx context.Context, body []byte) error {
+	return m.fn(ctx, body)
+}
+
+// mockLogger satisfies logger.Logger without importing the package.
+type mockLogger struct{}
+
+func (mockLogger) Info(_ context.Context, _, _ string, _ ...any)    {}
+func (mockLogger) Error(_ context.Context, _, _ string, _ ...any)   {}
+func (mockLogger) Warning(_ context.Context, _, _ string, _ ...any) {}
+func (mockLogger) Debug(_ context.Context, _, _ string, _ ...any)   {}
+func (mockLogger) Fatal(_ context.Context, _, _ string, _ ...any)   {}
+
+func cfg() ConsumerConfig {
+	return ConsumerConfig{
+		Stream:         "orders",
+		Group:          "billing",
+		ConsumerName:   "test-1",
+		StartID:        "0",
+		Block:          20 * time.Millisecond,
+		WorkerPoolSize: 2,
+		MinIdle:        10 * time.Millisecond,
+		ClaimInterval:  20 * time.Millisecond,
+		MaxDeliveries:  2,
+	}
+}
+
+var ctx = context.Background()
+
+func newTestClient(t *testing.T) *goredis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// run starts c in the background and returns a func that stops it and waits for Start to return.
+func run(t *testing.T, c Consumer) func() {
+	t.Helper()
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- c.Start(runCtx) }()
+	return func() {
+		cancel()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Start returned %v, want context.Canceled", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("Start did not return after cancel")
+		}
+	}
+}
+
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func pendingCount(client *goredis.Client) int64 {
+	p, err := client.XPending(ctx, "orders", "billing").Result()
+	if err != nil {
+		return -1
+	}
+	return p.Count
+}
+
+// ---- New ----
+
+func TestNew_Validation(t *testing.T) {
+	client := newTestClient(t)
+	proc := &mockProcessor{fn: func(context.Context, []byte) error { return nil }}
+
+	if _, err := New(nil, proc, mockLogger{}, cfg(), "c"); err == nil {
+		t.Error("expected error for nil client")
+	}
+	if _, err := New(client, nil, mockLogger{}, cfg(), "c"); err == nil {
+		t.Error("expected error for nil processor")
+	}
+	if _, err := New(client, proc, nil, cfg(), "c"); err == nil {
+		t.Error("expected error for nil logger")
+	}
+	if _, err := New(client, proc, mockLogger{}, ConsumerConfig{Group: "g"}, "c"); err == nil {
+		t.Error("expected error for empty stream")
+	}
+	if _, err := New(client, proc, mockLogger{}, ConsumerConfig{Stream: "s"}, "c"); err == nil {
+		t.Error("expected error for empty group")
+	}
+	if _, err := New(client, proc, mockLogger{}, cfg(), ""); err == nil {
+		t.Error("expected error for empty name")
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	client := newTestClient(t)
+	proc := &mockProcessor{fn: func(context.Context, []byte) error { return nil }}
+	c, err := New(client, proc, mockLogger{}, ConsumerConfig{Stream: "orders", Group: "billing"}, "c")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	got := c.(*consumer).cfg
+	if got.ConsumerName == "" || got.DeadLetterStream != "orders:dlq" || got.StartID != "$" ||
+		got.WorkerPoolSize != 10 || got.MaxDeliveries != 5 {
+		t.Errorf("defaults not applied: %+v", got)
+	}
+}
+
+// ---- Start ----
+
+func TestStart_ProcessesAndAcks(t *testing.T) {
+	client := newTestClient(t)
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	proc := &mockProcessor{fn: func(_ context.Context, body []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+		return nil
+	}}
+	client.XAdd(ctx, &goredis.XAddArgs{Stream: "orders", Values: map[string]any{"body": "one"}})
+	client.XAdd(ctx, &goredis.XAddArgs{Stream: "orders", Values: map[string]any{"body": "two"}})
+
+	c, err := New(client, proc, mockLogger{}, cfg(), "test-consumer")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	stop := run(t, c)
+	defer stop()
+
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(bodies) == 2
+	}, "expected both messages to be processed")
+	eventually(t, func() bool { return pendingCount(client) == 0 }, "expected messages to be acked")
+}
+
+func TestStart_ExistingGroup(t *testing.T) {
+	client := newTestClient(t)
+	client.XGroupCreateMkStream(ctx, "orders", "billing", "0")
+	proc := &mockProcessor{fn: func(context.Context, []byte) error { return nil }}
+	c, _ := New(client, proc, mockLogger{}, cfg(), "test-consumer")
+
+	// BUSYGROUP must not prevent the consumer from starting.
+	stop := run(t, c)
+	time.Sleep(30 * time.Millisecond)
+	stop()
+}
+
+func TestStart_RestoresTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	client := newTestClient(t)
+
+	traceID := trace.TraceID{9, 8, 7}
+	got := make(chan trace.TraceID, 1)
+	proc := &mockProcessor{fn: func(ctx context.Context, _ []byte) error {
+		got <- trace.SpanContextFromContext(ctx).TraceID()
+		return nil
+	}}
+	client.XAdd(ctx, &goredis.XAddArgs{Stream: "orders", Values: map[string]any{
+		"body":        "x",
+		"traceparent": "00-" + traceID.String() + "-0102030405060708-01",
+	}})
+
+	c, _ := New(client, proc, mockLogger{}, cfg(), "test-consumer")
+	stop := run(t, c)
+	defer stop()
+
+	select {
+	case id := <-got:
+		if id != traceID {
+			t.Errorf("trace id = %s, want %s", id, traceID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not processed")
+	}
+}
+
+// ---- reclaim / dead-letter ----
+
+func TestReclaim_FailingMessageIsDeadLettered(t *testing.T) {
+	client := newTestClient(t)
+	var calls atomic.Int32
+	proc := &mockProcessor{fn: func(context.Context, []byte) error {
+		calls.Add(1)
+		return errors.New("boom")
+	}}
+	client.XAdd(ctx, &goredis.XAddArgs{Stream: "orders", Values: map[string]any{"body": "poison", "source": "test"}})
+
+	c, _ := New(client, proc, mockLogger{}, cfg(), "test-consumer")
+	stop := run(t, c)
+	defer stop()
+
+	eventually(t, func() bool {
+		n, _ := client.XLen(ctx, "orders:dlq").Result()
+		return n == 1
+	}, "expected message in dead-letter stream")
+	eventually(t, func() bool { return pendingCount(client) == 0 }, "expected dead-lettered message to be acked")
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("processor calls = %d, want MaxDeliveries (2)", n)
+	}
+	entries, _ := client.XRange(ctx, "orders:dlq", "-", "+").Result()
+	v := entries[0].Values
+	if v["body"] != "poison" || v["source"] != "test" || v[deadLetterSourceField] != "orders" ||
+		v[deadLetterDeliveriesField] != "3" || !strings.Contains(v[deadLetterIDField].(string), "-") {
+		t.Errorf("dead-letter fields = %v", v)
+	}
+}
+
+func TestReclaim_TakesOverCrashedConsumer(t *testing.T) {
+	client := newTestClient(t)
+	client.XGroupCreateMkStream(ctx, "orders", "billing", "0")
+	client.XAdd(ctx, &goredis.XAddArgs{Stream: "orders", Values: map[string]any{"body": "orphan"}})
+	// A replica reads the entry and dies before acking it.
+	client.XReadGroup(ctx, &goredis.XReadGroupArgs{Group: "billing", Consumer: "crashed", Streams: []string{"orders", ">"}})
+
+	got := make(chan string, 1)
+	proc := &mockProcessor{fn: func(_ context.Context, body []byte) error {
+		got <- string(body)
+		return nil
+	}}
+	c, _ := New(client, proc, mockLogger{}, cfg(), "test-consumer")
+	stop := run(t, c)
+	defer stop()
+
+	select {
+	case body := <-got:
+		if body != "orphan" {
+			t.Errorf("body = %q, want orphan", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("orphaned message not reclaimed")
+	}
+	eventually(t, func() bool { return pendingCount(client) == 0 }, "expected reclaimed message to be acked")
+}
+
+// ---- splitFields ----
+
+func TestSplitFields(t *testing.T) {
+	body, fields := splitFields(map[string]any{"body": "hello", "traceparent": "tp", "n": 1})
+	if body != "hello" {
+		t.Errorf("body = %q, want hello", body)
+	}
+	if _, ok := fields["body"]; ok {
+		t.Error("body should not be in fields")
+	}
+	if fields["traceparent"] != "tp" || len(fields) != 1 {
+		t.Errorf("fields = %v", fields)
+	}
+}
@@ -0,0 +1,97 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/env"
+	sqsconsumer "github.com/juanMaAV92/go-utils/messaging/sqs/consumer"
+)
+
+// ConsumerConfig holds consumer-specific configuration.
+type ConsumerConfig struct {
+	Stream           string
+	Group            string
+	ConsumerName     string        // unique per replica; default "{hostname}-{random}"
+	StartID          string        // where a new group starts reading: "$" (new entries, default) or "0" (whole stream)
+	BatchSize        int64         // XREADGROUP / XAUTOCLAIM COUNT, default 10
+	Block            time.Duration // XREADGROUP BLOCK, default 5s — also bounds shutdown latency
+	WorkerPoolSize   int           // concurrent workers, default 10
+	MinIdle          time.Duration // pending entries idle this long are reclaimed, default 1m
+	ClaimInterval    time.Duration // how often pending entries are reclaimed, default 30s
+	MaxDeliveries    int64         // deliveries before dead-lettering, default 5; negative disables
+	DeadLetterStream string        // default "{Stream}:dlq"
+}
+
+// ConfigFromEnv reads consumer configuration from environment variables.
+//
+//	ConfigFromEnv("STREAM")       → STREAM_NAME, STREAM_GROUP, STREAM_WORKER_POOL_SIZE, …
+//	ConfigFromEnv("ORDER_STREAM") → ORDER_STREAM_NAME, …
+//
+// Required: {prefix}_NAME, {prefix}_GROUP
+func ConfigFromEnv(prefix string) (ConsumerConfig, error) {
+	p := prefix + "_"
+	cfg := ConsumerConfig{
+		Stream:           env.GetEnv(p + "NAME"),
+		Group:            env.GetEnv(p + "GROUP"),
+		ConsumerName:     env.GetEnvWithDefault(p+"CONSUMER_NAME", ""),
+		StartID:          env.GetEnvWithDefault(p+"START_ID", "$"),
+		BatchSize:        int64(env.GetEnvAsIntWithDefault(p+"BATCH_SIZE", 10)),
+		Block:            env.GetEnvAsDurationWithDefault(p+"BLOCK", 5*time.Second),
+		WorkerPoolSize:   env.GetEnvAsIntWithDefault(p+"WORKER_POOL_SIZE", 10),
+		MinIdle:          env.GetEnvAsDurationWithDefault(p+"MIN_IDLE", time.Minute),
+		ClaimInterval:    env.GetEnvAsDurationWithDefault(p+"CLAIM_INTERVAL", 30*time.Second),
+		MaxDeliveries:    int64(env.GetEnvAsIntWithDefault(p+"MAX_DELIVERIES", 5)),
+		DeadLetterStream: env.GetEnvWithDefault(p+"DEAD_LETTER_STREAM", ""),
+	}
+	if cfg.Stream == "" {
+		return ConsumerConfig{}, fmt.Errorf("redisstream/consumer: missing required env var: %s", p+"NAME")
+	}
+	if cfg.Group == "" {
+		return ConsumerConfig{}, fmt.Errorf("redisstream/consumer: missing required env var: %s", p+"GROUP")
+	}
+	return cfg, nil
+}
+
+func (c ConsumerConfig) withDefaults() ConsumerConfig {
+	if c.StartID == "" {
+		c.StartID = "$"
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 10
+	}
+	if c.Block <= 0 {
+		c.Block = 5 * time.Second
+	}
+	if c.WorkerPoolSize <= 0 {
+		c.WorkerPoolSize = 10
+	}
+	if c.MinIdle <= 0 {
+		c.MinIdle = time.Minute
+	}
+	if c.ClaimInterval <= 0 {
+		c.ClaimInterval = 30 * time.Second
+	}
+	if c.MaxDeliveries == 0 {
+		c.MaxDeliveries = 5
+	}
+	if c.DeadLetterStream == "" {
+		c.DeadLetterStream = c.Stream + ":dlq"
+	}
+	return c
+}
+
+// MessageProcessor is implemented by services to handle individual messages; it is
+// the SQS consumer's interface, so one processor serves both transports.
+// Return nil to acknowledge (XACK) the message.
+// Return an error to leave it pending; it is reclaimed after MinIdle and
+// dead-lettered once it has been delivered more than MaxDeliveries times.
+type MessageProcessor = sqsconsumer.MessageProcessor
+
+// Consumer is the interface for consuming messages from a Redis stream.
+type Consumer interface {
+	// Start reads the stream as part of the consumer group and dispatches
+	// messages to the worker pool. Blocks until ctx is cancelled.
+	Start(ctx context.Context) error
+}
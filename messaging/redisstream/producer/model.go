@@ -0,0 +1,55 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/juanMaAV92/go-utils/env"
+)
+
+// ProducerConfig holds producer-specific configuration.
+type ProducerConfig struct {
+	Stream string
+	MaxLen int64 // approximate stream cap (XADD MAXLEN ~); 0 = unbounded
+}
+
+// ConfigFromEnv reads producer configuration from environment variables.
+//
+//	ConfigFromEnv("STREAM")       → STREAM_NAME, STREAM_MAX_LEN
+//	ConfigFromEnv("ORDER_STREAM") → ORDER_STREAM_NAME, ORDER_STREAM_MAX_LEN
+//
+// Required: {prefix}_NAME
+func ConfigFromEnv(prefix string) (ProducerConfig, error) {
+	p := prefix + "_"
+	cfg := ProducerConfig{
+		Stream: env.GetEnv(p + "NAME"),
+		MaxLen: int64(env.GetEnvAsIntWithDefault(p+"MAX_LEN", 0)),
+	}
+	if cfg.Stream == "" {
+		return ProducerConfig{}, fmt.Errorf("redisstream/producer: missing required env var: %s", p+"NAME")
+	}
+	return cfg, nil
+}
+
+// Message is a message to be appended to the stream.
+type Message struct {
+	Body       string            // required; stored in the "body" field
+	Attributes map[string]string // optional; each becomes a stream field ("body" and trace keys are reserved)
+}
+
+// BatchResult contains the outcome of a SendBatch call.
+type BatchResult struct {
+	SuccessCount  int
+	FailedCount   int
+	FailedIndexes []int // positions in the input slice that were not appended
+}
+
+// Producer is the interface for appending messages to a Redis stream.
+type Producer interface {
+	// SendMessage appends a single message (XADD).
+	SendMessage(ctx context.Context, msg *Message) error
+
+	// SendBatch appends messages in one pipelined round trip.
+	// Returns BatchResult even on partial failure.
+	SendBatch(ctx context.Context, msgs []*Message) (*BatchResult, error)
+}
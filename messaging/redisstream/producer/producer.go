@@ -0,0 +1,225 @@
+package producer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/juanMaAV92/go-utils/logger"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// bodyField is the stream field holding Message.Body; the consumer reads the same field.
+const bodyField = "body"
+
+type producer struct {
+	client goredis.UniversalClient
+	logger logger.Logger
+	cfg    ProducerConfig
+	name   string
+	tracer trace.Tracer
+}
+
+// New creates a new Redis Streams producer.
+// name identifies this producer in logs and traces (useful when writing to multiple streams).
+func New(client goredis.UniversalClient, log logger.Logger, cfg ProducerConfig, name string) (Producer, error) {
+	if client == nil {
+		return nil, errors.New("redisstream/producer: client is required")
+	}
+	if log == nil {
+		return nil, errors.New("redisstream/producer: logger is required")
+	}
+	if cfg.Stream == "" {
+		return nil, errors.New("redisstream/producer: stream is required")
+	}
+	if name == "" {
+		return nil, errors.New("redisstream/producer: name is required")
+	}
+	return &producer{
+		client: client,
+		logger: log,
+		cfg:    cfg,
+		name:   name,
+		tracer: otel.Tracer("github.com/juanMaAV92/go-utils/messaging/redisstream"),
+	}, nil
+}
+
+// SendMessage appends a single message to the stream.
+func (p *producer) SendMessage(ctx context.Context, msg *Message) error {
+	if msg == nil {
+		return errors.New("redisstream/producer: message is required")
+	}
+	if msg.Body == "" {
+		return errors.New("redisstream/producer: message body cannot be empty")
+	}
+
+	ctx, span := p.tracer.Start(ctx,
+		fmt.Sprintf("Redis stream send %s", p.name),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttrs(p.cfg.Stream, p.name)...),
+	)
+	defer span.End()
+	span.SetAttributes(attribute.Int("messaging.message.body.size", len(msg.Body)))
+
+	fields, err := buildFieldsWithTrace(ctx, msg)
+	if err != nil {
+		return p.fail(span, err)
+	}
+
+	msgID, err := p.client.XAdd(ctx, p.xaddArgs(fields)).Result()
+	if err != nil {
+		p.logError(ctx, "redisstream.send_message", "failed to send message", "producer", p.name, "error", err.Error())
+		return p.fail(span, fmt.Errorf("redisstream: send message: %w", err))
+	}
+
+	span.SetAttributes(attribute.String("messaging.message.id", msgID))
+	p.logInfo(ctx, "redisstream.send_message", "message sent", "producer", p.name, "message_id", msgID)
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// SendBatch appends messages in a single pipelined round trip.
+// Each XADD succeeds or fails on its own; the pipeline is not a transaction.
+func (p *producer) SendBatch(ctx context.Context, msgs []*Message) (*BatchResult, error) {
+	if len(msgs) == 0 {
+		return nil, errors.New("redisstream/producer: at least one message is required")
+	}
+
+	ctx, span := p.tracer.Start(ctx,
+		fmt.Sprintf("Redis stream send_batch %s", p.name),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttrs(p.cfg.Stream, p.name)...),
+	)
+	defer span.End()
+
+	pipe := p.client.Pipeline()
+	cmds := make([]*goredis.StringCmd, len(msgs))
+	for i, msg := range msgs {
+		if msg == nil || msg.Body == "" {
+			return nil, p.fail(span, fmt.Errorf("redisstream/producer: message at index %d has empty body", i))
+		}
+		fields, err := buildFieldsWithTrace(ctx, msg)
+		if err != nil {
+			return nil, p.fail(span, fmt.Errorf("message at index %d: %w", i, err))
+		}
+		cmds[i] = pipe.XAdd(ctx, p.xaddArgs(fields))
+	}
+	// Per-command errors are inspected below; Exec only reports the first one.
+	_, _ = pipe.Exec(ctx)
+
+	result := &BatchResult{FailedIndexes: make([]int, 0)}
+	var firstErr error
+	for i, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			result.FailedCount++
+			result.FailedIndexes = append(result.FailedIndexes, i)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		result.SuccessCount++
+	}
+
+	span.SetAttributes(
+		attribute.Int("messaging.batch.message_count", len(msgs)),
+		attribute.Int("messaging.batch.success_count", result.SuccessCount),
+		attribute.Int("messaging.batch.failed_count", result.FailedCount),
+	)
+
+	if result.FailedCount > 0 {
+		p.logWarning(ctx, "redisstream.send_batch", "partial batch failure",
+			"producer", p.name, "success", result.SuccessCount, "failed", result.FailedCount, "error", firstErr.Error())
+		return result, p.fail(span, fmt.Errorf("redisstream: %d of %d messages failed: %w", result.FailedCount, len(msgs), firstErr))
+	}
+
+	p.logInfo(ctx, "redisstream.send_batch", "batch sent", "producer", p.name, "count", result.SuccessCount)
+	span.SetStatus(codes.Ok, "")
+	return result, nil
+}
+
+// --- helpers ---
+
+func (p *producer) xaddArgs(fields map[string]any) *goredis.XAddArgs {
+	args := &goredis.XAddArgs{Stream: p.cfg.Stream, Values: fields}
+	if p.cfg.MaxLen > 0 {
+		args.MaxLen = p.cfg.MaxLen
+		args.Approx = true
+	}
+	return args
+}
+
+func (p *producer) logInfo(ctx context.Context, step, msg string, kv ...any) {
+	if p.logger != nil {
+		p.logger.Info(ctx, step, msg, kv...)
+	}
+}
+
+func (p *producer) logWarning(ctx context.Context, step, msg string, kv ...any) {
+	if p.logger != nil {
+		p.logger.Warning(ctx, step, msg, kv...)
+	}
+}
+
+func (p *producer) logError(ctx context.Context, step, msg string, kv ...any) {
+	if p.logger != nil {
+		p.logger.Error(ctx, step, msg, kv...)
+	}
+}
+
+func (p *producer) fail(span trace.Span, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return err
+}
+
+// buildFieldsWithTrace flattens body and attributes into stream fields and injects W3C trace context.
+func buildFieldsWithTrace(ctx context.Context, msg *Message) (map[string]any, error) {
+	carrier := &fieldCarrier{fields: make(map[string]string)}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	fields := make(map[string]any, len(msg.Attributes)+len(carrier.fields)+1)
+	for k, v := range msg.Attributes {
+		if k == bodyField {
+			return nil, fmt.Errorf("redisstream/producer: attribute %q is reserved", k)
+		}
+		if _, ok := carrier.fields[k]; ok {
+			return nil, fmt.Errorf("redisstream/producer: attribute %q is reserved for trace context", k)
+		}
+		fields[k] = v
+	}
+	for k, v := range carrier.fields {
+		fields[k] = v
+	}
+	fields[bodyField] = msg.Body
+	return fields, nil
+}
+
+func messagingAttrs(stream, name string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "redis"),
+		attribute.String("messaging.operation", "publish"),
+		attribute.String("messaging.destination.name", stream),
+		attribute.String("messaging.destination.kind", "stream"),
+		attribute.String("messaging.producer.name", name),
+	}
+}
+
+// fieldCarrier implements propagation.TextMapCarrier for stream entry fields.
+type fieldCarrier struct {
+	fields map[string]string
+}
+
+func (c *fieldCarrier) Get(key string) string { return c.fields[key] }
+func (c *fieldCarrier) Set(key, value string) { c.fields[key] = value }
+func (c *fieldCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.fields))
+	for k := range c.fields {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,152 @@
+package producer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// mockLogger satisfies logger.Logger without importing the package.
+type mockLogger struct{}
+
+func (mockLogger) Info(_ context.Context, _, _ string, _ ...any)    {}
+func (mockLogger) Error(_ context.Context, _, _ string, _ ...any)   {}
+func (mockLogger) Warning(_ context.Context, _, _ string, _ ...any) {}
+func (mockLogger) Debug(_ context.Context, _, _ string, _ ...any)   {}
+func (mockLogger) Fatal(_ context.Context, _, _ string, _ ...any)   {}
+
+var ctx = context.Background()
+
+func newTestProducer(t *testing.T, cfg ProducerConfig) (Producer, *goredis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	p, err := New(client, mockLogger{}, cfg, "test-producer")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p, client
+}
+
+// ---- New ----
+
+func TestNew_Validation(t *testing.T) {
+	client := goredis.NewClient(&goredis.Options{Addr: "localhost:0"})
+	defer client.Close()
+
+	if _, err := New(nil, mockLogger{}, ProducerConfig{Stream: "s"}, "p"); err == nil {
+		t.Error("expected error for nil client")
+	}
+	if _, err := New(client, nil, ProducerConfig{Stream: "s"}, "p"); err == nil {
+		t.Error("expected error for nil logger")
+	}
+	if _, err := New(client, mockLogger{}, ProducerConfig{}, "p"); err == nil {
+		t.Error("expected error for empty stream")
+	}
+	if _, err := New(client, mockLogger{}, ProducerConfig{Stream: "s"}, ""); err == nil {
+		t.Error("expected error for empty name")
+	}
+}
+
+// ---- SendMessage ----
+
+func TestSendMessage_WritesBodyAttributesAndTrace(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	p, client := newTestProducer(t, ProducerConfig{Stream: "orders"})
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	traced := trace.ContextWithSpanContext(ctx, sc)
+
+	err := p.SendMessage(traced, &Message{Body: `{"id":"1"}`, Attributes: map[string]string{"source": "test"}})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	entries, _ := client.XRange(ctx, "orders", "-", "+").Result()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	v := entries[0].Values
+	if v["body"] != `{"id":"1"}` || v["source"] != "test" {
+		t.Errorf("fields = %v", v)
+	}
+	if tp, _ := v["traceparent"].(string); !strings.Contains(tp, sc.TraceID().String()) {
+		t.Errorf("traceparent = %q, want trace id %s", tp, sc.TraceID())
+	}
+}
+
+func TestSendMessage_Validation(t *testing.T) {
+	p, _ := newTestProducer(t, ProducerConfig{Stream: "orders"})
+	if err := p.SendMessage(ctx, nil); err == nil {
+		t.Error("expected error for nil message")
+	}
+	if err := p.SendMessage(ctx, &Message{}); err == nil {
+		t.Error("expected error for empty body")
+	}
+	if err := p.SendMessage(ctx, &Message{Body: "x", Attributes: map[string]string{"body": "y"}}); err == nil {
+		t.Error("expected error for reserved attribute")
+	}
+}
+
+func TestSendMessage_MaxLen(t *testing.T) {
+	p, client := newTestProducer(t, ProducerConfig{Stream: "orders", MaxLen: 2})
+	for i := 0; i < 5; i++ {
+		if err := p.SendMessage(ctx, &Message{Body: "x"}); err != nil {
+			t.Fatalf("SendMessage: %v", err)
+		}
+	}
+	// MAXLEN ~ trims lazily on real Redis; miniredis trims exactly.
+	if n, _ := client.XLen(ctx, "orders").Result(); n != 2 {
+		t.Errorf("XLEN = %d, want 2", n)
+	}
+}
+
+// ---- SendBatch ----
+
+func TestSendBatch(t *testing.T) {
+	p, client := newTestProducer(t, ProducerConfig{Stream: "orders"})
+	result, err := p.SendBatch(ctx, []*Message{{Body: "1"}, {Body: "2"}, {Body: "3"}})
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+	if result.SuccessCount != 3 || result.FailedCount != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if n, _ := client.XLen(ctx, "orders").Result(); n != 3 {
+		t.Errorf("XLEN = %d, want 3", n)
+	}
+}
+
+func TestSendBatch_Validation(t *testing.T) {
+	p, _ := newTestProducer(t, ProducerConfig{Stream: "orders"})
+	if _, err := p.SendBatch(ctx, nil); err == nil {
+		t.Error("expected error for empty batch")
+	}
+	if _, err := p.SendBatch(ctx, []*Message{{Body: "1"}, {}}); err == nil {
+		t.Error("expected error for empty body in batch")
+	}
+}
+
+func TestSendBatch_PartialFailure(t *testing.T) {
+	p, client := newTestProducer(t, ProducerConfig{Stream: "orders"})
+	// A non-stream value at the key makes every XADD fail with WRONGTYPE.
+	client.Set(ctx, "orders", "not-a-stream", 0)
+	result, err := p.SendBatch(ctx, []*Message{{Body: "1"}, {Body: "2"}})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if result == nil || result.FailedCount != 2 || len(result.FailedIndexes) != 2 {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -0,0 +1,262 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/juanMaAV92/go-utils/cache/redis"
+	apperrors "github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/middleware/identity"
+	"github.com/labstack/echo/v4"
+)
+
+// --- helpers ---
+
+func newTestCache(t *testing.T) (redis.Cache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	c, err := redis.New(context.Background(), redis.Config{Host: mr.Host(), Port: mr.Port()}, nil)
+	if err != nil {
+		t.Fatalf("redis.New: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, mr
+}
+
+func serve(mw echo.MiddlewareFunc, h echo.HandlerFunc, req *http.Request) (*httptest.ResponseRecorder, error) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	return rec, mw(h)(c)
+}
+
+func newRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	return req
+}
+
+// countingHandler creates a payment and counts how often it ran.
+func countingHandler(calls *atomic.Int32) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		n := calls.Add(1)
+		c.Response().Header().Set("Location", "/payments/"+strconv.Itoa(int(n)))
+		return c.JSON(http.StatusCreated, map[string]int32{"id": n})
+	}
+}
+
+func assertAppError(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*apperrors.ErrorResponse)
+	if !ok || appErr.HttpCode != code {
+		t.Fatalf("expected %d ErrorResponse, got %v", code, err)
+	}
+}
+
+// --- Middleware ---
+
+func TestMiddleware_ReplaysFirstResponse(t *testing.T) {
+	c, _ := newTestCache(t)
+	mw := Middleware(c, Config{})
+	var calls atomic.Int32
+
+	first, err := serve(mw, countingHandler(&calls), newRequest("k-1", `{"amount":10}`))
+	if err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	second, err := serve(mw, countingHandler(&calls), newRequest("k-1", `{"amount":10}`))
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Location") != "/payments/1" || second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replay headers = %v", second.Header())
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("first response must not be marked as replayed")
+	}
+}
+
+func TestMiddleware_RejectsKeyReuseForDifferentRequest(t *testing.T) {
+	c, _ := newTestCache(t)
+	mw := Middleware(c, Config{})
+	var calls atomic.Int32
+
+	_, _ = serve(mw, countingHandler(&calls), newRequest("k-1", `{"amount":10}`))
+	_, err := serve(mw, countingHandler(&calls), newRequest("k-1", `{"amount":99}`))
+	assertAppError(t, err, http.StatusUnprocessableEntity)
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestMiddleware_RejectsKeyReuseForDifferentQuery(t *testing.T) {
+	c, _ := newTestCache(t)
+	mw := Middleware(c, Config{})
+	var calls atomic.Int32
+	withQuery := func(query string) *http.Request {
+		req := newRequest("k-1", `{"amount":10}`)
+		req.URL.RawQuery = query
+		return req
+	}
+
+	_, _ = serve(mw, countingHandler(&calls), withQuery("currency=EUR&dry_run=false"))
+	if _, err := serve(mw, countingHandler(&calls), withQuery("dry_run=false&currency=EUR")); err != nil {
+		t.Fatalf("reordered query must replay: %v", err)
+	}
+	_, err := serve(mw, countingHandler(&calls), withQuery("currency=USD&dry_run=false"))
+	assertAppError(t, err, http.StatusUnprocessableEntity)
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestMiddleware_ConflictWhileInFlight(t *testing.T) {
+	c, _ := newTestCache(t)
+	mw := Middleware(c, Config{})
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := serve(mw, slow, newRequest("k-1", "{}"))
+		done <- err
+	}()
+	<-started
+
+	rec, err := serve(mw, slow, newRequest("k-1", "{}"))
+	assertAppError(t, err, http.StatusConflict)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+}
+
+func TestMiddleware_DoesNotStoreFailures(t *testing.T) {
+	c, _ := newTestCache(t)
+	mw := Middleware(c, Config{})
+	var calls atomic.Int32
+	failing := func(c echo.Context) error {
+		calls.Add(1)
+		return apperrors.ErrBadGateway()
+	}
+
+	_, _ = serve(mw, failing, newRequest("k-1", "{}"))
+	_, err := serve(mw, failing, newRequest("k-1", "{}"))
+	assertAppError(t, err, http.StatusBadGateway)
+	if calls.Load() != 2 {
+		t.Errorf("handler ran %d times, want 2 — failures must be retryable", calls.Load())
+	}
+}
+
+func TestMiddleware_ScopesKeysPerUser(t *testing.T) {
+	c, mr := newTestCache(t)
+	mw := Middleware(c, Config{})
+	var calls atomic.Int32
+
+	for _, user := range []string{"u-1", "u-2"} {
+		req := newRequest("k-1", "{}")
+		req = req.WithContext(identity.WithIdentity(req.Context(), &identity.Identity{UserCode: user}))
+		_, _ = serve(mw, countingHandler(&calls), req)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler ran %d times, want once per user", calls.Load())
+	}
+	if !mr.Exists("idempotency:u-1:k-1") {
+		t.Errorf("expected key idempotency:u-1:k-1, have %v", mr.Keys())
+	}
+}
+
+func TestMiddleware_MissingKey(t *testing.T) {
+	c, mr := newTestCache(t)
+	var calls atomic.Int32
+
+	if _, err := serve(Middleware(c, Config{}), countingHandler(&calls), newRequest("", "{}")); err != nil {
+		t.Errorf("optional key: unexpected error %v", err)
+	}
+	_, err := serve(Middleware(c, Config{Required: true}), countingHandler(&calls), newRequest("", "{}"))
+	assertAppError(t, err, http.StatusBadRequest)
+	if len(mr.Keys()) != 0 {
+		t.Errorf("expected no keys, have %v", mr.Keys())
+	}
+}
+
+func TestMiddleware_SkipsSafeMethods(t *testing.T) {
+	c, mr := newTestCache(t)
+	var calls atomic.Int32
+	req := httptest.NewRequest(http.MethodGet, "/payments", nil)
+	req.Header.Set("Idempotency-Key", "k-1")
+
+	_, _ = serve(Middleware(c, Config{}), countingHandler(&calls), req)
+	if len(mr.Keys()) != 0 {
+		t.Errorf("expected no keys, have %v", mr.Keys())
+	}
+}
+
+func TestMiddleware_RedisDown(t *testing.T) {
+	c, mr := newTestCache(t)
+	mr.Close()
+	var calls atomic.Int32
+
+	if _, err := serve(Middleware(c, Config{}), countingHandler(&calls), newRequest("k-1", "{}")); err != nil {
+		t.Errorf("fail-open should process the request, got %v", err)
+	}
+	_, err := serve(Middleware(c, Config{FailClosed: true}), countingHandler(&calls), newRequest("k-1", "{}"))
+	assertAppError(t, err, http.StatusServiceUnavailable)
+}
+
+func TestMiddleware_UnreadableStoredResponse(t *testing.T) {
+	c, mr := newTestCache(t)
+	var calls atomic.Int32
+	_ = mr.Set("idempotency:k-1", "not a record")
+
+	_, err := serve(Middleware(c, Config{FailClosed: true}), countingHandler(&calls), newRequest("k-1", "{}"))
+	assertAppError(t, err, http.StatusServiceUnavailable)
+	if calls.Load() != 0 {
+		t.Errorf("fail-closed ran the handler %d times", calls.Load())
+	}
+
+	if _, err := serve(Middleware(c, Config{}), countingHandler(&calls), newRequest("k-1", "{}")); err != nil {
+		t.Errorf("fail-open should process the request, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestMiddleware_RejectsOversizedBody(t *testing.T) {
+	c, mr := newTestCache(t)
+	mw := Middleware(c, Config{MaxBodyBytes: 16})
+	var calls atomic.Int32
+
+	_, err := serve(mw, countingHandler(&calls), newRequest("k-1", `{"amount":1000000000}`))
+	assertAppError(t, err, http.StatusRequestEntityTooLarge)
+	if calls.Load() != 0 || len(mr.Keys()) != 0 {
+		t.Errorf("oversized body ran the handler %d times, keys %v", calls.Load(), mr.Keys())
+	}
+	if _, err := serve(mw, countingHandler(&calls), newRequest("k-2", `{"amount":1}`)); err != nil {
+		t.Errorf("body within the limit: %v", err)
+	}
+}
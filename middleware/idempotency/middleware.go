@@ -0,0 +1,186 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/juanMaAV92/go-utils/cache/redis"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerReplayed   = "Idempotent-Replayed"
+	headerRetryAfter = "Retry-After"
+
+	// maxKeyLength bounds client keys; UUIDs and ULIDs fit comfortably.
+	maxKeyLength = 255
+)
+
+// Middleware returns an Echo middleware that makes unsafe requests safe to retry.
+// The first request with a given Idempotency-Key runs the handler under a Redis lock
+// and stores its status, headers and body; later requests with the same key and the
+// same method, path and body get that response replayed with Idempotent-Replayed: true.
+//
+// Errors use the errors package: 409 while the first request is still in flight,
+// 422 when the key is reused for a different request, 400 for a missing (when
+// Required) or oversized key, 413 for a body over MaxBodyBytes. Handler errors and 5xx responses are not stored,
+// so the client can retry them with the same key.
+//
+//	api.POST("/payments", createPayment, idempotency.Middleware(cache, idempotency.Config{
+//	    Required: true,
+//	}))
+func Middleware(cache redis.Cache, cfg Config) echo.MiddlewareFunc {
+	if cache == nil {
+		panic("idempotency: cache is required")
+	}
+	cfg = cfg.withDefaults()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !slices.Contains(cfg.Methods, req.Method) {
+				return next(c)
+			}
+			idemKey := req.Header.Get(cfg.Header)
+			if idemKey == "" {
+				if cfg.Required {
+					return errors.ErrBadRequest("missing " + cfg.Header + " header")
+				}
+				return next(c)
+			}
+			if len(idemKey) > maxKeyLength {
+				return errors.ErrBadRequest(cfg.Header + " must be at most " + strconv.Itoa(maxKeyLength) + " characters")
+			}
+
+			fingerprint, err := fingerprintRequest(c.Response(), req, cfg.MaxBodyBytes)
+			var tooLarge *http.MaxBytesError
+			if stderrors.As(err, &tooLarge) {
+				return errors.ErrRequestEntityTooLarge("request body must be at most " + strconv.FormatInt(tooLarge.Limit, 10) + " bytes")
+			}
+			if err != nil {
+				return errors.ErrBadRequest("failed to read request body")
+			}
+			key := cfg.Prefix + ":" + idemKey
+			if scope := cfg.Scope(c); scope != "" {
+				key = cfg.Prefix + ":" + scope + ":" + idemKey
+			}
+			ctx := req.Context()
+
+			if done, err := replayStored(c, cache, cfg, key, fingerprint); done || err != nil {
+				return err
+			}
+
+			lock, err := cache.Lock(ctx, key+":lock", cfg.LockTTL, redis.WithLockAutoRefresh())
+			if stderrors.Is(err, redis.ErrLockNotAcquired) {
+				c.Response().Header().Set(headerRetryAfter, "1")
+				return errors.ErrConflict("a request with this " + cfg.Header + " is still being processed")
+			}
+			if err != nil {
+				if cfg.FailClosed {
+					return errors.ErrServiceUnavailable()
+				}
+				return next(c)
+			}
+			defer func() { _ = lock.Unlock(context.WithoutCancel(ctx)) }()
+
+			// The first request may have completed between the lookup and the lock.
+			if done, err := replayStored(c, cache, cfg, key, fingerprint); done || err != nil {
+				return err
+			}
+
+			res := c.Response()
+			preset := make(map[string]bool, len(res.Header()))
+			for name := range res.Header() {
+				preset[name] = true
+			}
+			rec := &recorder{ResponseWriter: res.Writer}
+			res.Writer = rec
+			err = next(c)
+			res.Writer = rec.ResponseWriter
+			if err != nil || !res.Committed || res.Status >= http.StatusInternalServerError {
+				return err
+			}
+
+			stored := record{Fingerprint: fingerprint, Status: res.Status, Body: rec.body.Bytes()}
+			for name, values := range res.Header() {
+				if !preset[name] {
+					if stored.Header == nil {
+						stored.Header = make(http.Header)
+					}
+					stored.Header[name] = values
+				}
+			}
+			// The response is already sent; a failed store only costs replay protection.
+			_ = cache.Set(context.WithoutCancel(ctx), key, stored, redis.WithTTL(cfg.TTL))
+			return nil
+		}
+	}
+}
+
+// replayStored writes the response stored under key, if any. done reports whether
+// the request was answered (replayed or rejected) without running the handler.
+// A lookup failure other than a miss is treated like Redis being unavailable.
+func replayStored(c echo.Context, cache redis.Cache, cfg Config, key, fingerprint string) (done bool, err error) {
+	var stored record
+	if err := cache.Get(c.Request().Context(), key, &stored); err != nil {
+		if !stderrors.Is(err, redis.ErrKeyNotFound) && cfg.FailClosed {
+			return true, errors.ErrServiceUnavailable()
+		}
+		return false, nil
+	}
+	if stored.Fingerprint != fingerprint {
+		return true, errors.ErrUnprocessableEntity("idempotency key was already used for a different request")
+	}
+	h := c.Response().Header()
+	for name, values := range stored.Header {
+		h[name] = values
+	}
+	h.Set(headerReplayed, "true")
+	c.Response().WriteHeader(stored.Status)
+	_, err = c.Response().Write(stored.Body)
+	return true, err
+}
+
+// fingerprintRequest hashes method, path, query and body, and restores the body
+// for the handler. The query is canonicalised so parameter order does not matter.
+// Bodies over maxBody bytes fail with *http.MaxBytesError.
+func fingerprintRequest(w http.ResponseWriter, req *http.Request, maxBody int64) (string, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBody))
+		if err != nil {
+			return "", err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		body = b
+	}
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "?" + req.URL.Query().Encode() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recorder tees the response body into a buffer while writing it to the client.
+type recorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
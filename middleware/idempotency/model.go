@@ -0,0 +1,95 @@
+package idempotency
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/middleware/identity"
+	"github.com/labstack/echo/v4"
+)
+
+// ScopeFunc returns the namespace an Idempotency-Key is unique within (e.g. a user code),
+// so two clients sending the same key never share a stored response.
+type ScopeFunc func(c echo.Context) string
+
+// Config controls the idempotency middleware.
+//
+//	idempotency.Config{
+//	    Required: true,
+//	    TTL:      48 * time.Hour,
+//	}
+type Config struct {
+	// Header carries the client-generated key.
+	// Default: "Idempotency-Key"
+	Header string
+
+	// Methods lists the HTTP methods the middleware applies to; others pass through.
+	// Default: POST, PATCH
+	Methods []string
+
+	// Required rejects requests without the header with 400.
+	// Default: false — requests without a key are processed normally.
+	Required bool
+
+	// Scope namespaces keys per client.
+	// Default: the identity user code (empty for anonymous requests)
+	Scope ScopeFunc
+
+	// Prefix namespaces the Redis keys.
+	// Default: "idempotency"
+	Prefix string
+
+	// TTL is how long a completed response is kept for replay.
+	// Default: 24h
+	TTL time.Duration
+
+	// LockTTL bounds how long a crashed request keeps its key locked. The lock is
+	// renewed while the handler runs, so it does not limit handler duration.
+	// Default: 30s
+	LockTTL time.Duration
+
+	// MaxBodyBytes bounds the request body read to fingerprint the request;
+	// larger bodies are rejected with 413.
+	// Default: 1 MiB
+	MaxBodyBytes int64
+
+	// FailClosed rejects requests with 503 when Redis is unavailable or a stored
+	// response cannot be read.
+	// Default: false — requests are processed without idempotency protection.
+	FailClosed bool
+}
+
+// record is a completed response stored for replay.
+type record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Header == "" {
+		cfg.Header = "Idempotency-Key"
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if cfg.Scope == nil {
+		cfg.Scope = func(c echo.Context) string {
+			return identity.GetUserCode(c.Request().Context())
+		}
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "idempotency"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = 30 * time.Second
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 1 << 20
+	}
+	return cfg
+}
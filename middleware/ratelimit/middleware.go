@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/juanMaAV92/go-utils/cache/redis"
+	"github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/middleware/identity"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerLimit      = "RateLimit-Limit"
+	headerRemaining  = "RateLimit-Remaining"
+	headerReset      = "RateLimit-Reset"
+	headerRetryAfter = "Retry-After"
+)
+
+// Middleware returns an Echo middleware that limits requests per key using cache.Allow.
+// Every response carries RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers.
+// Rejected requests get Retry-After and the standard errors.ErrTooManyRequests body (429).
+// Panics when cache is nil or cfg.Limit has no positive Limit and Period.
+//
+//	e.Use(ratelimit.Middleware(cache, ratelimit.Config{Limit: redis.PerSecond(20)}))
+//
+//	api.POST("/orders", createOrder, ratelimit.Middleware(cache, ratelimit.Config{
+//	    Limit:   redis.PerMinute(10),
+//	    KeyFunc: ratelimit.ByUserCode(),
+//	    Prefix:  "ratelimit:create_order",
+//	}))
+func Middleware(cache redis.Cache, cfg Config) echo.MiddlewareFunc {
+	if cache == nil {
+		panic("ratelimit: cache is required")
+	}
+	if cfg.Limit.Limit <= 0 || cfg.Limit.Period < time.Millisecond {
+		panic("ratelimit: Limit requires Limit > 0 and Period >= 1ms")
+	}
+	cfg = cfg.withDefaults()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := cfg.KeyFunc(c)
+			if key == "" {
+				return next(c)
+			}
+
+			res, err := cache.Allow(c.Request().Context(), cfg.Prefix+":"+key, cfg.Limit)
+			if err != nil {
+				if cfg.FailClosed {
+					return errors.ErrServiceUnavailable()
+				}
+				if cfg.Logger != nil {
+					cfg.Logger.Warning(c.Request().Context(), "ratelimit.allow", "rate limit check failed, letting request through",
+						"key", key, "error", err.Error())
+				}
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set(headerLimit, strconv.Itoa(res.Limit))
+			h.Set(headerRemaining, strconv.Itoa(res.Remaining))
+			h.Set(headerReset, strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+			if !res.Allowed {
+				h.Set(headerRetryAfter, strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+				return errors.ErrTooManyRequests()
+			}
+			return next(c)
+		}
+	}
+}
+
+// ByIP keys requests by client IP (echo.Context.RealIP).
+func ByIP() KeyFunc {
+	return func(c echo.Context) string {
+		return "ip:" + c.RealIP()
+	}
+}
+
+// ByUserCode keys requests by identity.GetUserCode, falling back to the client IP
+// for anonymous requests. Register after identity.Middleware.
+func ByUserCode() KeyFunc {
+	return func(c echo.Context) string {
+		if code := identity.GetUserCode(c.Request().Context()); code != "" {
+			return "user:" + code
+		}
+		return "ip:" + c.RealIP()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
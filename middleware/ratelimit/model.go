@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"github.com/juanMaAV92/go-utils/cache/redis"
+	"github.com/juanMaAV92/go-utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// KeyFunc returns the identity a request is counted against (e.g. "ip:10.0.0.1").
+// Return an empty string to skip rate limiting for the request.
+type KeyFunc func(c echo.Context) string
+
+// Config controls the rate-limit middleware.
+//
+//	ratelimit.Config{
+//	    Limit:   redis.PerMinute(100),
+//	    KeyFunc: ratelimit.ByUserCode(),
+//	}
+type Config struct {
+	// Limit is the rate applied to each key. Required: Limit > 0 and Period >= 1ms.
+	Limit redis.RateLimit
+
+	// KeyFunc extracts the key for a request.
+	// Default: ByIP()
+	KeyFunc KeyFunc
+
+	// Prefix namespaces the Redis keys, so different routes can have independent limits.
+	// Default: "ratelimit"
+	Prefix string
+
+	// FailClosed rejects requests with 503 when Redis is unavailable.
+	// Default: false — requests are let through so a Redis outage does not take the API down.
+	FailClosed bool
+
+	// Logger records the Redis error when a request is let through (FailClosed false).
+	// Optional.
+	Logger logger.Logger
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = ByIP()
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "ratelimit"
+	}
+	return cfg
+}
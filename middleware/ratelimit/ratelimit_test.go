@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/juanMaAV92/go-utils/cache/redis"
+	apperrors "github.com/juanMaAV92/go-utils/errors"
+	"github.com/juanMaAV92/go-utils/middleware/identity"
+	"github.com/labstack/echo/v4"
+)
+
+// --- helpers ---
+
+func newTestCache(t *testing.T) (redis.Cache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	c, err := redis.New(context.Background(), redis.Config{Host: mr.Host(), Port: mr.Port()}, nil)
+	if err != nil {
+		t.Fatalf("redis.New: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, mr
+}
+
+func serve(mw echo.MiddlewareFunc, req *http.Request) (*httptest.ResponseRecorder, error) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	err := mw(func(c echo.Context) error { return c.NoContent(http.StatusOK) })(c)
+	return rec, err
+}
+
+func newRequest(ip string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = ip + ":1234"
+	return req
+}
+
+// captureLogger records the steps of warnings.
+type captureLogger struct{ warnings []string }
+
+func (l *captureLogger) Info(context.Context, string, string, ...any)  {}
+func (l *captureLogger) Error(context.Context, string, string, ...any) {}
+func (l *captureLogger) Debug(context.Context, string, string, ...any) {}
+func (l *captureLogger) Fatal(context.Context, string, string, ...any) {}
+func (l *captureLogger) Warning(_ context.Context, step, _ string, _ ...any) {
+	l.warnings = append(l.warnings, step)
+}
+
+// --- Middleware ---
+
+func TestMiddleware_AllowsWithinLimitAndSetsHeaders(t *testing.T) {
+	c, _ := newTestCache(t)
+	mw := Middleware(c, Config{Limit: redis.PerMinute(2)})
+
+	rec, err := serve(mw, newRequest("10.0.0.1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rec.Header().Get("RateLimit-Limit"); got != "2" {
+		t.Errorf("RateLimit-Limit = %q, want 2", got)
+	}
+	if got := rec.Header().Get("RateLimit-Remaining"); got != "1" {
+		t.Errorf("RateLimit-Remaining = %q, want 1", got)
+	}
+	if got := rec.Header().Get("RateLimit-Reset"); got != "60" {
+		t.Errorf("RateLimit-Reset = %q, want 60", got)
+	}
+}
+
+func TestMiddleware_RejectsOverLimit(t *testing.T) {
+	c, _ := newTestCache(t)
+	mw := Middleware(c, Config{Limit: redis.PerMinute(1)})
+
+	_, _ = serve(mw, newRequest("10.0.0.1"))
+	rec, err := serve(mw, newRequest("10.0.0.1"))
+
+	appErr, ok := err.(*apperrors.ErrorResponse)
+	if !ok || appErr.HttpCode != http.StatusTooManyRequests {
+		t.Fatalf("expected ErrTooManyRequests, got %v", err)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+
+	// A different IP has its own budget.
+	if _, err := serve(mw, newRequest("10.0.0.2")); err != nil {
+		t.Errorf("other IP should be allowed, got %v", err)
+	}
+}
+
+func TestMiddleware_ByUserCode(t *testing.T) {
+	c, mr := newTestCache(t)
+	mw := Middleware(c, Config{Limit: redis.PerMinute(5), KeyFunc: ByUserCode()})
+
+	req := newRequest("10.0.0.1")
+	req = req.WithContext(identity.WithIdentity(req.Context(), &identity.Identity{UserCode: "u-1"}))
+	_, _ = serve(mw, req)
+
+	if !mr.Exists("ratelimit:user:u-1") {
+		t.Errorf("expected key ratelimit:user:u-1, have %v", mr.Keys())
+	}
+}
+
+func TestMiddleware_EmptyKeySkips(t *testing.T) {
+	c, mr := newTestCache(t)
+	mw := Middleware(c, Config{
+		Limit:   redis.PerMinute(1),
+		KeyFunc: func(echo.Context) string { return "" },
+	})
+	_, _ = serve(mw, newRequest("10.0.0.1"))
+	if len(mr.Keys()) != 0 {
+		t.Errorf("expected no keys, have %v", mr.Keys())
+	}
+}
+
+func TestMiddleware_RedisDown(t *testing.T) {
+	c, mr := newTestCache(t)
+	mr.Close()
+
+	log := &captureLogger{}
+	open := Middleware(c, Config{Limit: redis.PerSecond(1), Logger: log})
+	if _, err := serve(open, newRequest("10.0.0.1")); err != nil {
+		t.Errorf("fail-open should let the request through, got %v", err)
+	}
+	if len(log.warnings) != 1 || log.warnings[0] != "ratelimit.allow" {
+		t.Errorf("fail-open should log the Redis error, got %v", log.warnings)
+	}
+
+	closed := Middleware(c, Config{Limit: redis.PerSecond(1), FailClosed: true})
+	_, err := serve(closed, newRequest("10.0.0.1"))
+	appErr, ok := err.(*apperrors.ErrorResponse)
+	if !ok || appErr.HttpCode != http.StatusServiceUnavailable {
+		t.Errorf("fail-closed should return 503, got %v", err)
+	}
+}
+
+func TestMiddleware_PanicsOnInvalidLimit(t *testing.T) {
+	c, _ := newTestCache(t)
+	for name, limit := range map[string]redis.RateLimit{
+		"zero limit":  {Limit: 0, Period: time.Second},
+		"zero period": {Limit: 10},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			Middleware(c, Config{Limit: limit})
+		})
+	}
+}
+
+func TestCeilSeconds(t *testing.T) {
+	if got := ceilSeconds(1500 * time.Millisecond); got != 2 {
+		t.Errorf("ceilSeconds(1.5s) = %d, want 2", got)
+	}
+}